@@ -0,0 +1,9 @@
+data "netbox_prefix" "servers" {
+  cidr = "10.0.10.0/24"
+}
+
+// The next 5 free addresses of the prefix
+data "netbox_available_ips" "free" {
+  prefix_id = data.netbox_prefix.servers.id
+  limit     = 5
+}
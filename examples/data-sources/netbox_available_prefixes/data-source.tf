@@ -0,0 +1,9 @@
+data "netbox_prefix" "container" {
+  cidr = "10.0.0.0/16"
+}
+
+// The free blocks of the container that fit a /24
+data "netbox_available_prefixes" "free" {
+  parent_prefix_id = data.netbox_prefix.container.id
+  prefix_length    = 24
+}
@@ -0,0 +1,20 @@
+data "netbox_vrf" "prod" {
+  name = "prod"
+}
+
+data "netbox_prefixes" "prod_containers" {
+  include_utilization = true
+
+  filter {
+    name  = "vrf_id"
+    value = data.netbox_vrf.prod.id
+  }
+  filter {
+    name  = "status"
+    value = "container"
+  }
+  filter {
+    name  = "within"
+    value = "10.0.0.0/8"
+  }
+}
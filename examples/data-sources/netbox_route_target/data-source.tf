@@ -0,0 +1,3 @@
+data "netbox_route_target" "cust_a" {
+  name = "65000:100"
+}
@@ -0,0 +1,11 @@
+provider "netbox" {
+  server_url        = "https://netbox.example.com/"
+  run_id            = "ci-job-1234"
+  changelog_comment = "Applied by the network pipeline"
+}
+
+data "netbox_run" "current" {}
+
+output "netbox_run_id" {
+  value = data.netbox_run.current.run_id
+}
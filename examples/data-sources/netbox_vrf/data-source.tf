@@ -1,3 +1,7 @@
 data "netbox_vrf" "cust_a_prod" {
   name = "cust-a-prod"
 }
+
+data "netbox_vrf" "cust_a_rd" {
+  rd = "65000:100"
+}
@@ -0,0 +1,11 @@
+resource "netbox_vlan_group" "test" {
+  name    = "my-vlan-group"
+  min_vid = 100
+  max_vid = 199
+}
+
+resource "netbox_available_vlan" "test" {
+  group_id = netbox_vlan_group.test.id
+  name     = "my-vlan"
+  status   = "active"
+}
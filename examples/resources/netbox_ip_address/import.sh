@@ -0,0 +1,5 @@
+# IP addresses can be imported by ID
+terraform import netbox_ip_address.myvm_ip 1
+
+# or by address, optionally followed by @ and the name of the VRF
+terraform import netbox_ip_address.myvm_ip 10.0.0.1/24@customer-a
@@ -0,0 +1,4 @@
+resource "netbox_route_target" "cust_a" {
+  name        = "65000:100"
+  description = "Customer A"
+}
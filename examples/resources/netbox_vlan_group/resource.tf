@@ -0,0 +1,11 @@
+data "netbox_site" "test" {
+  name = "my-site"
+}
+
+resource "netbox_vlan_group" "test" {
+  name       = "my-vlan-group"
+  scope_type = "dcim.site"
+  scope_id   = data.netbox_site.test.id
+  min_vid    = 100
+  max_vid    = 199
+}
@@ -1,4 +1,11 @@
+resource "netbox_route_target" "cust_a" {
+  name = "65000:100"
+}
+
 resource "netbox_vrf" "cust_a_prod" {
-  name = "cust-a-prod"
-  tags = ["customer-a", "prod"]
+  name           = "cust-a-prod"
+  rd             = "65000:100"
+  import_targets = [netbox_route_target.cust_a.id]
+  export_targets = [netbox_route_target.cust_a.id]
+  tags           = ["customer-a", "prod"]
 }
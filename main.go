@@ -18,4 +18,7 @@ func main() {
 			return netbox.Provider()
 		},
 	})
+
+	// Delete tokens the provider provisioned for itself
+	netbox.Shutdown()
 }
@@ -1,21 +1,59 @@
 package netbox
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/go-openapi/runtime"
 	httptransport "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
 	"github.com/goware/urlx"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	netboxclient "github.com/netbox-community/go-netbox/netbox/client"
-	log "github.com/sirupsen/logrus"
+	"github.com/netbox-community/go-netbox/netbox/client/status"
 )
 
 // Config struct for the netbox provider
 type Config struct {
-	APIToken           string
-	ServerURL          string
-	AllowInsecureHttps bool
-	Headers            map[string]interface{}
+	APIToken            string
+	APITokenFile        string
+	APITokenCommand     string
+	Username            string
+	Password            string
+	ServerURL           string
+	AllowInsecureHttps  bool
+	Headers             map[string]interface{}
+	MaxRetries          int
+	RetryWaitMin        time.Duration
+	RetryWaitMax        time.Duration
+	CACertFile          string
+	CACertPEM           string
+	ClientCert          string
+	ClientKey           string
+	ProxyURL            string
+	RequestTimeout      time.Duration
+	MaxIdleConnsPerHost int
+	KeepAlive           time.Duration
+	ReadOnly            bool
+	RunID               string
+	MaxRequestsPerSec   int
+	MaxConcurrent       int
+	WaitForReady        bool
+	StartupTimeout      time.Duration
 }
 
 // customHeaderTransport is a transport that adds the specified headers on
@@ -25,15 +63,63 @@ type customHeaderTransport struct {
 	headers  map[string]interface{}
 }
 
+// requestIDTransport is a transport that sets the X-Request-ID header of
+// every request to the ID of the Terraform run, so that requests of a run can
+// be correlated in the logs of Netbox and of proxies in front of it.
+type requestIDTransport struct {
+	original http.RoundTripper
+	runID    string
+}
+
+// timeoutTransport is a transport that limits the time a single request may
+// take. The limit applies on top of any deadline of the request context.
+type timeoutTransport struct {
+	original http.RoundTripper
+	timeout  time.Duration
+}
+
+// timeoutBody cancels the context of a request with a timeout once its
+// response body has been closed.
+type timeoutBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// contextTransport is a client transport that submits operations without a
+// context with a context that is never canceled. This disables the fixed
+// timeout the generated client applies to such operations in favor of the
+// request timeout of the provider.
+type contextTransport struct {
+	original runtime.ClientTransport
+	ctx      context.Context
+}
+
+// retryTransport is a transport that retries requests failing with a
+// connection error, a 429 or a 5xx status with exponential backoff.
+type retryTransport struct {
+	original   http.RoundTripper
+	maxRetries int
+	waitMin    time.Duration
+	waitMax    time.Duration
+}
+
 // Client does the heavy lifting of establishing a base Open API client to Netbox.
-func (cfg *Config) Client() (interface{}, error) {
+// Requests of operations without a context of their own are logged with the
+// loggers of ctx. The API token is taken from the configured source; tokens
+// provisioned with a username and password are deleted by Shutdown.
+func (cfg *Config) Client(ctx context.Context) (interface{}, error) {
 
-	log.WithFields(log.Fields{
+	tflog.Debug(ctx, "Initializing Netbox client", map[string]interface{}{
 		"server_url": cfg.ServerURL,
-	}).Debug("Initializing Netbox client")
+		"username":   cfg.Username,
+	})
 
-	if cfg.APIToken == "" {
-		return nil, fmt.Errorf("Missing netbox API key")
+	apiToken, err := cfg.staticAPIToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if apiToken == "" && cfg.ReadOnly {
+		return nil, fmt.Errorf("username and password cannot be used with read_only, because provisioning a token writes to Netbox: use api_token, api_token_file or api_token_command instead")
 	}
 
 	// parse serverUrl
@@ -43,25 +129,61 @@ func (cfg *Config) Client() (interface{}, error) {
 	}
 
 	desiredRuntimeClientSchemes := []string{parsedURL.Scheme}
-	log.WithFields(log.Fields{
+	tflog.Debug(ctx, "Initializing Netbox Open API runtime client", map[string]interface{}{
 		"host":    parsedURL.Host,
 		"schemes": desiredRuntimeClientSchemes,
-	}).Debug("Initializing Netbox Open API runtime client")
+	})
 
 	// build http client
-	clientOpts := httptransport.TLSClientOptions{
-		InsecureSkipVerify: cfg.AllowInsecureHttps,
-	}
-
-	trans, err := httptransport.TLSTransport(clientOpts)
+	httpTransport, err := cfg.httpTransport(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	var trans http.RoundTripper = loggingTransport{
+		original: httpTransport,
+		secrets:  []string{apiToken, cfg.Password},
+	}
+
+	if cfg.RequestTimeout > 0 {
+		trans = timeoutTransport{
+			original: trans,
+			timeout:  cfg.RequestTimeout,
+		}
+	}
+
+	if cfg.MaxRequestsPerSec > 0 || cfg.MaxConcurrent > 0 {
+		tflog.Debug(ctx, "Limiting requests to Netbox", map[string]interface{}{
+			"max_requests_per_second": cfg.MaxRequestsPerSec,
+			"max_concurrent_requests": cfg.MaxConcurrent,
+		})
+
+		trans = newRateLimitTransport(trans, cfg.MaxRequestsPerSec, cfg.MaxConcurrent)
+	}
+
+	if cfg.MaxRetries > 0 {
+		tflog.Debug(ctx, "Retrying failed requests to Netbox", map[string]interface{}{
+			"max_retries":    cfg.MaxRetries,
+			"retry_wait_min": cfg.RetryWaitMin.String(),
+			"retry_wait_max": cfg.RetryWaitMax.String(),
+		})
+
+		trans = retryTransport{
+			original:   trans,
+			maxRetries: cfg.MaxRetries,
+			waitMin:    cfg.RetryWaitMin,
+			waitMax:    cfg.RetryWaitMax,
+		}
+	}
+
 	if cfg.Headers != nil && len(cfg.Headers) > 0 {
-		log.WithFields(log.Fields{
-			"custom_headers": cfg.Headers,
-		}).Debug("Setting custom headers on every request to Netbox")
+		headers := make([]string, 0, len(cfg.Headers))
+		for name := range cfg.Headers {
+			headers = append(headers, name)
+		}
+		tflog.Debug(ctx, "Setting custom headers on every request to Netbox", map[string]interface{}{
+			"custom_headers": headers,
+		})
 
 		trans = customHeaderTransport{
 			original: trans,
@@ -69,18 +191,299 @@ func (cfg *Config) Client() (interface{}, error) {
 		}
 	}
 
+	if cfg.RunID != "" && !hasHeader(cfg.Headers, "X-Request-ID") {
+		tflog.Debug(ctx, "Identifying requests to Netbox with the run ID", map[string]interface{}{
+			"run_id": cfg.RunID,
+		})
+
+		trans = requestIDTransport{
+			original: trans,
+			runID:    cfg.RunID,
+		}
+	}
+
+	if cfg.ReadOnly {
+		tflog.Debug(ctx, "Only sending GET requests to Netbox")
+
+		trans = readOnlyTransport{
+			original: trans,
+		}
+	}
+
 	httpClient := &http.Client{
 		Transport: trans,
 	}
 
 	transport := httptransport.NewWithClient(parsedURL.Host, parsedURL.Path+netboxclient.DefaultBasePath, desiredRuntimeClientSchemes, httpClient)
-	transport.DefaultAuthentication = httptransport.APIKeyAuth("Authorization", "header", fmt.Sprintf("Token %v", cfg.APIToken))
-	transport.SetLogger(log.StandardLogger())
-	netboxClient := netboxclient.New(transport, nil)
+	// Requests are logged by loggingTransport, which masks credentials
+	transport.Debug = false
+	netboxClient := netboxclient.New(contextTransport{
+		original: transport,
+		ctx:      valuesContext{ctx},
+	}, nil)
+
+	if apiToken != "" {
+		transport.DefaultAuthentication = tokenAuthentication(apiToken)
+	}
+
+	if cfg.WaitForReady {
+		tflog.Debug(ctx, "Waiting for Netbox to become ready", map[string]interface{}{
+			"startup_timeout": cfg.StartupTimeout.String(),
+		})
+		if err := waitForNetbox(ctx, netboxClient, cfg.StartupTimeout); err != nil {
+			return nil, err
+		}
+	}
+
+	if apiToken == "" {
+		token, err := provisionToken(ctx, netboxClient, cfg.Username, cfg.Password)
+		if err != nil {
+			return nil, err
+		}
+		tflog.Debug(ctx, "Provisioned Netbox API token", map[string]interface{}{
+			"token_id": token.ID,
+		})
+		onShutdown(func() {
+			deleteToken(valuesContext{ctx}, netboxClient, token.ID)
+		})
+		transport.DefaultAuthentication = tokenAuthentication(token.Key)
+
+		if err := setTokenExpiry(ctx, netboxClient, token.ID); err != nil {
+			tflog.Warn(ctx, "Unable to set the expiry of the provisioned Netbox API token, it does not expire if it is not deleted", map[string]interface{}{
+				"token_id": token.ID,
+				"error":    err.Error(),
+			})
+		}
+	}
 
 	return netboxClient, nil
 }
 
+// httpTransport returns the transport that connects to Netbox, configured
+// with the TLS, proxy and connection pooling settings.
+func (cfg *Config) httpTransport(ctx context.Context) (*http.Transport, error) {
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("error while trying to parse proxy URL: %s", err)
+		}
+		tflog.Debug(ctx, "Connecting to Netbox through a proxy", map[string]interface{}{
+			"proxy_url": proxyURL.Redacted(),
+		})
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	httpTransport := http.DefaultTransport.(*http.Transport).Clone()
+	httpTransport.TLSClientConfig = tlsConfig
+	httpTransport.Proxy = proxy
+
+	if cfg.MaxIdleConnsPerHost > 0 {
+		httpTransport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+		if httpTransport.MaxIdleConns < cfg.MaxIdleConnsPerHost {
+			httpTransport.MaxIdleConns = cfg.MaxIdleConnsPerHost
+		}
+	}
+
+	// A negative keep-alive disables TCP keep-alive probes like in
+	// net.Dialer. Idle connections are reused either way.
+	if cfg.KeepAlive != 0 {
+		httpTransport.DialContext = (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: cfg.KeepAlive,
+		}).DialContext
+	}
+
+	tflog.Debug(ctx, "Configuring connections to Netbox", map[string]interface{}{
+		"request_timeout":         cfg.RequestTimeout.String(),
+		"max_idle_conns_per_host": httpTransport.MaxIdleConnsPerHost,
+		"keepalive":               cfg.KeepAlive.String(),
+	})
+
+	return httpTransport, nil
+}
+
+// tlsConfig returns the TLS configuration for connections to Netbox. A
+// custom CA bundle is trusted in addition to the system certificates.
+func (cfg *Config) tlsConfig() (*tls.Config, error) {
+	clientOpts := httptransport.TLSClientOptions{
+		InsecureSkipVerify: cfg.AllowInsecureHttps,
+	}
+
+	caCertPEM := []byte(cfg.CACertPEM)
+	if cfg.CACertFile != "" {
+		var err error
+		caCertPEM, err = os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA certificate file: %s", err)
+		}
+	}
+	if len(caCertPEM) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caCertPEM) {
+			return nil, fmt.Errorf("CA certificate does not contain any PEM-encoded certificate")
+		}
+		clientOpts.LoadedCAPool = pool
+	}
+
+	tlsConfig, err := httptransport.TLSClientAuth(clientOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		certPEM, err := readPEMOrFile(cfg.ClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("error reading client certificate: %s", err)
+		}
+		keyPEM, err := readPEMOrFile(cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error reading client key: %s", err)
+		}
+		certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+// readPEMOrFile returns value itself if it is PEM-encoded and the contents of
+// the file it names otherwise.
+func readPEMOrFile(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
+
+// tokenAuthentication authenticates requests with a Netbox API token.
+func tokenAuthentication(token string) runtime.ClientAuthInfoWriter {
+	return httptransport.APIKeyAuth("Authorization", "header", fmt.Sprintf("Token %v", token))
+}
+
+// getNetboxStatus reads the status endpoint of Netbox. The generated client
+// discards the response body of this endpoint, so it is decoded here.
+func getNetboxStatus(ctx context.Context, api *netboxclient.NetBoxAPI) (map[string]interface{}, error) {
+	var payload map[string]interface{}
+
+	reader := runtime.ClientResponseReaderFunc(func(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+		if response.Code() != http.StatusOK {
+			return nil, runtime.NewAPIError("unexpected response from status endpoint", response, response.Code())
+		}
+		if err := consumer.Consume(response.Body(), &payload); err != nil {
+			return nil, err
+		}
+		return status.NewStatusListOK(), nil
+	})
+
+	params := status.NewStatusListParamsWithContext(ctx)
+	_, err := api.Status.StatusList(params, nil, func(op *runtime.ClientOperation) {
+		op.Reader = reader
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// withRequestBody replaces the request body of an operation. It is used where
+// the generated client models a request body differently from what Netbox
+// accepts.
+func withRequestBody(params runtime.ClientRequestWriter, body interface{}) func(*runtime.ClientOperation) {
+	return func(op *runtime.ClientOperation) {
+		op.Params = runtime.ClientRequestWriterFunc(func(r runtime.ClientRequest, reg strfmt.Registry) error {
+			if err := params.WriteToRequest(r, reg); err != nil {
+				return err
+			}
+			return r.SetBodyParam(body)
+		})
+	}
+}
+
+// withResponseBody makes an operation decode a successful response into
+// payload and return result. It is used where the generated client models a
+// response differently from what Netbox returns. payload is usually a struct
+// that embeds the payload of result and shadows the fields that fail to
+// decode.
+func withResponseBody(payload interface{}, result interface{}) func(*runtime.ClientOperation) {
+	return func(op *runtime.ClientOperation) {
+		op.Reader = runtime.ClientResponseReaderFunc(func(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+			if response.Code() < 200 || response.Code() > 299 {
+				return nil, runtime.NewAPIError("unexpected response from "+op.PathPattern, response, response.Code())
+			}
+			if err := consumer.Consume(response.Body(), payload); err != nil {
+				return nil, err
+			}
+			return result, nil
+		})
+	}
+}
+
+// getNetboxVersion returns the version of the Netbox server.
+func getNetboxVersion(ctx context.Context, api *netboxclient.NetBoxAPI) (*version.Version, error) {
+	payload, err := getNetboxStatus(ctx, api)
+	if err != nil {
+		return nil, err
+	}
+
+	versionString, ok := payload["netbox-version"].(string)
+	if !ok {
+		return nil, fmt.Errorf("status endpoint did not report a netbox-version")
+	}
+	return version.NewVersion(versionString)
+}
+
+// Submit submits an operation, with the context of the transport if it has
+// none. Error responses are decoded into a *netboxError.
+func (t contextTransport) Submit(op *runtime.ClientOperation) (interface{}, error) {
+	if op.Context == nil {
+		op.Context = t.ctx
+	}
+
+	reader := op.Reader
+	op.Reader = runtime.ClientResponseReaderFunc(func(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+		result, err := reader.ReadResponse(response, consumer)
+		var apiError *runtime.APIError
+		if errors.As(err, &apiError) && apiError.Code >= http.StatusBadRequest {
+			return nil, newNetboxError(op.Method, op.PathPattern, apiError, response)
+		}
+		return result, err
+	})
+	return t.original.Submit(op)
+}
+
+// RoundTrip sends the request with a context that times out after the
+// timeout of the transport. The context is canceled once the response body
+// has been closed, so the body can still be read after RoundTrip returns.
+func (t timeoutTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(r.Context(), t.timeout)
+	resp, err := t.original.RoundTrip(r.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = timeoutBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// Close closes the body and releases the context of its request.
+func (b timeoutBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
 // RoundTrip adds the headers specified in the transport on every request.
 func (t customHeaderTransport) RoundTrip(r *http.Request) (*http.Response, error) {
 
@@ -91,3 +494,148 @@ func (t customHeaderTransport) RoundTrip(r *http.Request) (*http.Response, error
 	resp, err := t.original.RoundTrip(r)
 	return resp, err
 }
+
+// RoundTrip sets the X-Request-ID header of the request to the run ID.
+func (t requestIDTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r.Header.Set("X-Request-ID", t.runID)
+	return t.original.RoundTrip(r)
+}
+
+// hasHeader reports whether name is one of the custom headers, ignoring case.
+func hasHeader(headers map[string]interface{}, name string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// RoundTrip sends the request and retries it as long as it failed in a way
+// that is worth retrying. Requests with non-idempotent methods are only
+// retried when Netbox rate limited them, because in that case they have not
+// been processed.
+func (t retryTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+
+	if r.Body != nil && r.Body != http.NoBody && r.GetBody == nil {
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		r.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		r.Body, _ = r.GetBody()
+	}
+
+	for attempt := 0; ; attempt++ {
+		req := r
+		if attempt > 0 {
+			req = r.Clone(r.Context())
+			if r.GetBody != nil {
+				body, err := r.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				req.Body = body
+			}
+		}
+
+		resp, err := t.original.RoundTrip(req)
+		if attempt >= t.maxRetries || !t.shouldRetry(r, resp, err) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+		tflog.Debug(r.Context(), "Retrying request to Netbox", map[string]interface{}{
+			"http_method": r.Method,
+			"http_url":    r.URL.Redacted(),
+			"attempt":     attempt + 1,
+			"wait":        wait.String(),
+		})
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return nil, r.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRetry decides whether a request is retried based on its outcome.
+func (t retryTransport) shouldRetry(r *http.Request, resp *http.Response, err error) bool {
+	if r.Context().Err() != nil {
+		return false
+	}
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if !isIdempotentMethod(r.Method) {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented
+}
+
+// backoff returns how long to wait before the next attempt. A Retry-After
+// header sent by the server takes precedence over the exponential backoff,
+// but neither exceeds the configured maximum wait.
+func (t retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if wait > t.waitMax {
+				return t.waitMax
+			}
+			return wait
+		}
+	}
+
+	wait := t.waitMin << uint(attempt)
+	if wait < 0 || wait>>uint(attempt) != t.waitMin || wait > t.waitMax {
+		// The shift overflowed or the wait exceeds the maximum
+		wait = t.waitMax
+	}
+
+	// Add jitter so that parallel requests don't retry in lockstep
+	if half := int64(wait / 2); half > 0 {
+		wait = time.Duration(half + rand.Int63n(half+1))
+	}
+	return wait
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either
+// a number of seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -1,9 +1,25 @@
 package netbox
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	netboxClient "github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/status"
@@ -17,7 +33,7 @@ func TestValidClientWithAllData(t *testing.T) {
 		ServerURL: "https://localhost:8080",
 	}
 
-	client, err := config.Client()
+	client, err := config.Client(context.Background())
 	assert.NotNil(t, client)
 	assert.NoError(t, err)
 }
@@ -29,7 +45,7 @@ func TestURLMissingSchemaShouldWork(t *testing.T) {
 		ServerURL: "localhost:8080",
 	}
 
-	client, err := config.Client()
+	client, err := config.Client(context.Background())
 	assert.NotNil(t, client)
 	assert.NoError(t, err)
 }
@@ -41,7 +57,7 @@ func TestURLMaleformedUrlShouldFail(t *testing.T) {
 		ServerURL: "xyz:/localhost:8080",
 	}
 
-	_, err := config.Client()
+	_, err := config.Client(context.Background())
 	assert.Error(t, err)
 }
 
@@ -52,7 +68,7 @@ func TestURLMissingPortShouldWork(t *testing.T) {
 		ServerURL: "http://localhost",
 	}
 
-	client, err := config.Client()
+	client, err := config.Client(context.Background())
 	assert.NotNil(t, client)
 	assert.NoError(t, err)
 }
@@ -64,7 +80,7 @@ func TestURLMissingAccessKey(t *testing.T) {
 		ServerURL: "http://localhost",
 	}
 
-	_, err := config.Client()
+	_, err := config.Client(context.Background())
 	assert.Error(t, err)
 }
 
@@ -87,13 +103,519 @@ func TestAdditionalHeadersSet(t *testing.T) {
 		},
 	}
 
-	client, err := config.Client()
+	client, err := config.Client(context.Background())
 	assert.NoError(t, err)
 
 	req := status.NewStatusListParams()
 	client.(*netboxClient.NetBoxAPI).Status.StatusList(req, nil)
 }
 
-/* TODO
-func TestInvalidHttpsCertificate(t *testing.T) {}
-*/
+func TestRequestIDHeader(t *testing.T) {
+
+	testCases := []struct {
+		name      string
+		headers   map[string]interface{}
+		requestID []string
+	}{
+		{
+			name:      "RunID",
+			requestID: []string{"ci-job-1234"},
+		},
+		{
+			name:      "CustomHeader",
+			headers:   map[string]interface{}{"x-request-id": "custom"},
+			requestID: []string{"custom"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var requestID []string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestID = r.Header.Values("X-Request-ID")
+			}))
+			defer ts.Close()
+
+			config := Config{
+				APIToken:  "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+				ServerURL: ts.URL,
+				Headers:   tt.headers,
+				RunID:     "ci-job-1234",
+			}
+
+			client, err := config.Client(context.Background())
+			assert.NoError(t, err)
+
+			req := status.NewStatusListParams()
+			client.(*netboxClient.NetBoxAPI).Status.StatusList(req, nil)
+			assert.Equal(t, tt.requestID, requestID)
+		})
+	}
+}
+
+func TestRetryOnServerError(t *testing.T) {
+
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"netbox-version": "3.1.9"}`))
+	}))
+	defer ts.Close()
+
+	config := Config{
+		APIToken:     "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL:    ts.URL,
+		MaxRetries:   3,
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: 10 * time.Millisecond,
+	}
+
+	client, err := config.Client(context.Background())
+	assert.NoError(t, err)
+
+	req := status.NewStatusListParams()
+	_, err = client.(*netboxClient.NetBoxAPI).Status.StatusList(req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestRetryGivesUpAfterMaxRetries(t *testing.T) {
+
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	config := Config{
+		APIToken:     "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL:    ts.URL,
+		MaxRetries:   2,
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: 10 * time.Millisecond,
+	}
+
+	client, err := config.Client(context.Background())
+	assert.NoError(t, err)
+
+	req := status.NewStatusListParams()
+	_, err = client.(*netboxClient.NetBoxAPI).Status.StatusList(req, nil)
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestRetryOnlyIdempotentMethods(t *testing.T) {
+
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	trans := retryTransport{
+		original:   http.DefaultTransport,
+		maxRetries: 3,
+		waitMin:    time.Millisecond,
+		waitMax:    10 * time.Millisecond,
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(`{"name": "foo"}`))
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestRetryResendsBodyAfterRateLimit(t *testing.T) {
+
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"name": "foo"}`, string(body))
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	trans := retryTransport{
+		original:   http.DefaultTransport,
+		maxRetries: 3,
+		waitMin:    time.Second,
+		waitMax:    time.Minute,
+	}
+
+	// A body without GetBody forces the transport to buffer it
+	req, _ := http.NewRequest(http.MethodPost, ts.URL, io.NopCloser(strings.NewReader(`{"name": "foo"}`)))
+	start := time.Now()
+	resp, err := trans.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRetryBackoff(t *testing.T) {
+
+	trans := retryTransport{waitMin: time.Second, waitMax: time.Minute}
+	for attempt, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		wait := trans.backoff(attempt, nil)
+		assert.GreaterOrEqual(t, wait, expected/2)
+		assert.LessOrEqual(t, wait, expected)
+	}
+
+	// Overflowing and exceeding the maximum both wait the maximum
+	assert.GreaterOrEqual(t, trans.backoff(10, nil), 30*time.Second)
+	assert.GreaterOrEqual(t, trans.backoff(62, nil), 30*time.Second)
+	assert.GreaterOrEqual(t, trans.backoff(100, nil), 30*time.Second)
+
+	// Without a minimum wait, retries are immediate
+	trans.waitMin = 0
+	for attempt := 0; attempt < 5; attempt++ {
+		assert.Equal(t, time.Duration(0), trans.backoff(attempt, nil))
+	}
+}
+
+// testSlowServer answers status requests after delay.
+func testSlowServer(t *testing.T, delay time.Duration, requests *int32) *httptest.Server {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		select {
+		case <-time.After(delay):
+		case <-done:
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"netbox-version": "3.1.9"}`))
+	}))
+	t.Cleanup(func() {
+		close(done)
+		ts.Close()
+	})
+	return ts
+}
+
+func TestRequestTimeout(t *testing.T) {
+
+	var requests int32
+	ts := testSlowServer(t, 10*time.Second, &requests)
+
+	config := Config{
+		APIToken:       "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL:      ts.URL,
+		RequestTimeout: 50 * time.Millisecond,
+		MaxRetries:     2,
+		RetryWaitMin:   time.Millisecond,
+		RetryWaitMax:   time.Millisecond,
+	}
+
+	start := time.Now()
+	err := testStatusRequest(t, config)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	// Every attempt has its own timeout
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestRequestTimeoutReadsResponse(t *testing.T) {
+
+	var requests int32
+	ts := testSlowServer(t, 10*time.Millisecond, &requests)
+
+	err := testStatusRequest(t, Config{
+		APIToken:       "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL:      ts.URL,
+		RequestTimeout: 5 * time.Second,
+	})
+	assert.NoError(t, err)
+}
+
+func TestRequestTimeoutRespectsContext(t *testing.T) {
+
+	var requests int32
+	ts := testSlowServer(t, 10*time.Second, &requests)
+
+	config := Config{
+		APIToken:       "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL:      ts.URL,
+		RequestTimeout: time.Minute,
+		MaxRetries:     2,
+		RetryWaitMin:   time.Millisecond,
+		RetryWaitMax:   time.Millisecond,
+	}
+	client, err := config.Client(context.Background())
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = getNetboxStatus(ctx, client.(*netboxClient.NetBoxAPI))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestRequestWithoutContextHasNoFixedTimeout(t *testing.T) {
+
+	var requests int32
+	ts := testSlowServer(t, 100*time.Millisecond, &requests)
+
+	config := Config{
+		APIToken:  "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL: ts.URL,
+	}
+	client, err := config.Client(context.Background())
+	assert.NoError(t, err)
+
+	// The generated client would abort this request after 10ms
+	req := status.NewStatusListParamsWithTimeout(10 * time.Millisecond)
+	_, err = client.(*netboxClient.NetBoxAPI).Status.StatusList(req, nil)
+	assert.NoError(t, err)
+}
+
+func TestHTTPTransportConnectionSettings(t *testing.T) {
+
+	transport, err := (&Config{}).httpTransport(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, http.DefaultTransport.(*http.Transport).MaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	assert.False(t, transport.DisableKeepAlives)
+
+	transport, err = (&Config{MaxIdleConnsPerHost: 200, KeepAlive: 15 * time.Second}).httpTransport(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 200, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 200, transport.MaxIdleConns)
+	assert.False(t, transport.DisableKeepAlives)
+
+	transport, err = (&Config{KeepAlive: -1}).httpTransport(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, transport.DisableKeepAlives)
+}
+
+func TestHTTPTransportReusesConnectionsWithoutKeepAlive(t *testing.T) {
+
+	var connections int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"netbox-version": "3.1.9"}`))
+	}))
+	ts.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	config := Config{
+		APIToken:  "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL: ts.URL,
+		KeepAlive: -1,
+	}
+	client, err := config.Client(context.Background())
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = getNetboxVersion(context.Background(), client.(*netboxClient.NetBoxAPI))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&connections))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+
+	wait, ok := parseRetryAfter("5")
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, wait)
+
+	wait, ok = parseRetryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), wait)
+
+	_, ok = parseRetryAfter("")
+	assert.False(t, ok)
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+}
+
+// testClientCertificate returns a self-signed client certificate and its key,
+// both PEM-encoded.
+func testClientCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "terraform"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer})
+	return string(certPEM), string(keyPEM)
+}
+
+// testMutualTLSServer starts a status endpoint that only accepts clients
+// presenting clientCert. It returns the server and its PEM-encoded
+// certificate.
+func testMutualTLSServer(t *testing.T, clientCert string) (*httptest.Server, string) {
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM([]byte(clientCert))
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"netbox-version": "3.1.9"}`))
+	}))
+	ts.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+
+	serverCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	return ts, string(serverCert)
+}
+
+func testStatusRequest(t *testing.T, config Config) error {
+	client, err := config.Client(context.Background())
+	if err != nil {
+		return err
+	}
+	_, err = getNetboxVersion(context.Background(), client.(*netboxClient.NetBoxAPI))
+	return err
+}
+
+func TestInvalidHttpsCertificate(t *testing.T) {
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"netbox-version": "3.1.9"}`))
+	}))
+	defer ts.Close()
+
+	err := testStatusRequest(t, Config{
+		APIToken:  "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL: ts.URL,
+	})
+	assert.ErrorContains(t, err, "certificate")
+
+	err = testStatusRequest(t, Config{
+		APIToken:           "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL:          ts.URL,
+		AllowInsecureHttps: true,
+	})
+	assert.NoError(t, err)
+}
+
+func TestMutualTLS(t *testing.T) {
+
+	clientCert, clientKey := testClientCertificate(t)
+	ts, serverCert := testMutualTLSServer(t, clientCert)
+
+	caCertFile := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(caCertFile, []byte(serverCert), 0600))
+	clientCertFile := filepath.Join(t.TempDir(), "client.pem")
+	assert.NoError(t, os.WriteFile(clientCertFile, []byte(clientCert), 0600))
+	clientKeyFile := filepath.Join(t.TempDir(), "client-key.pem")
+	assert.NoError(t, os.WriteFile(clientKeyFile, []byte(clientKey), 0600))
+
+	for _, tt := range []struct {
+		name   string
+		config Config
+		err    string
+	}{
+		{
+			name:   "unknown CA",
+			config: Config{ClientCert: clientCert, ClientKey: clientKey},
+			err:    "certificate",
+		},
+		{
+			name:   "missing client certificate",
+			config: Config{CACertPEM: serverCert},
+			err:    "certificate required",
+		},
+		{
+			name:   "PEM values",
+			config: Config{CACertPEM: serverCert, ClientCert: clientCert, ClientKey: clientKey},
+		},
+		{
+			name:   "files",
+			config: Config{CACertFile: caCertFile, ClientCert: clientCertFile, ClientKey: clientKeyFile},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.APIToken = "07b12b765127747e4afd56cb531b7bf9c61f3c30"
+			tt.config.ServerURL = ts.URL
+			err := testStatusRequest(t, tt.config)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+
+	clientCert, clientKey := testClientCertificate(t)
+	otherCert, _ := testClientCertificate(t)
+
+	for name, config := range map[string]Config{
+		"missing CA file":      {CACertFile: filepath.Join(t.TempDir(), "missing.pem")},
+		"invalid CA":           {CACertPEM: "not a certificate"},
+		"missing client key":   {ClientCert: clientCert},
+		"mismatched key":       {ClientCert: otherCert, ClientKey: clientKey},
+		"missing client files": {ClientCert: "client.pem", ClientKey: "client-key.pem"},
+	} {
+		config.APIToken = "07b12b765127747e4afd56cb531b7bf9c61f3c30"
+		config.ServerURL = "https://localhost:8080"
+		_, err := config.Client(context.Background())
+		assert.Error(t, err, name)
+	}
+}
+
+func TestProxyURL(t *testing.T) {
+
+	var proxied int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&proxied, 1)
+		assert.Equal(t, "netbox.invalid", r.URL.Host)
+		assert.Equal(t, "/api/status/", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"netbox-version": "3.1.9"}`))
+	}))
+	defer proxy.Close()
+
+	err := testStatusRequest(t, Config{
+		APIToken:  "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL: "http://netbox.invalid",
+		ProxyURL:  proxy.URL,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&proxied))
+
+	_, err = (&Config{
+		APIToken:  "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL: "http://netbox.invalid",
+		ProxyURL:  "http://[::1",
+	}).Client(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,208 @@
+package netbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	goruntime "runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	netboxclient "github.com/netbox-community/go-netbox/netbox/client"
+	"github.com/netbox-community/go-netbox/netbox/client/users"
+	"github.com/netbox-community/go-netbox/netbox/models"
+)
+
+// provisionedTokenLifetime is the lifetime of tokens provisioned with a
+// username and password. Tokens are deleted when the provider exits, the
+// expiry only limits the lifetime of tokens that could not be deleted. This
+// includes every token of a provider process that Terraform kills before
+// Shutdown runs.
+const provisionedTokenLifetime = 12 * time.Hour
+
+// tokenDeleteTimeout is the time the provider waits for Netbox to delete a
+// provisioned token on exit.
+const tokenDeleteTimeout = 30 * time.Second
+
+// shutdownHooks are the functions that are run by Shutdown.
+var shutdownHooks struct {
+	sync.Mutex
+	hooks []func()
+}
+
+// onShutdown registers a function that is run by Shutdown.
+func onShutdown(hook func()) {
+	shutdownHooks.Lock()
+	defer shutdownHooks.Unlock()
+	shutdownHooks.hooks = append(shutdownHooks.hooks, hook)
+}
+
+// Shutdown cleans up what the provider created for its own use, like tokens
+// provisioned with a username and password. It is called once the plugin
+// server has stopped, so cleanup is best effort: it does not run when the
+// process is killed first.
+func Shutdown() {
+	shutdownHooks.Lock()
+	hooks := shutdownHooks.hooks
+	shutdownHooks.hooks = nil
+	shutdownHooks.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
+// credentialSources returns the names of the configured sources of the API
+// token.
+func (cfg *Config) credentialSources() []string {
+	sources := []string{}
+	if cfg.APIToken != "" {
+		sources = append(sources, "api_token")
+	}
+	if cfg.APITokenFile != "" {
+		sources = append(sources, "api_token_file")
+	}
+	if cfg.APITokenCommand != "" {
+		sources = append(sources, "api_token_command")
+	}
+	if cfg.Username != "" || cfg.Password != "" {
+		sources = append(sources, "username and password")
+	}
+	return sources
+}
+
+// staticAPIToken returns the API token if it is configured directly, in a
+// file or as a command. It returns an empty token if the token is provisioned
+// with a username and password.
+func (cfg *Config) staticAPIToken(ctx context.Context) (string, error) {
+	sources := cfg.credentialSources()
+	if len(sources) == 0 {
+		return "", fmt.Errorf("missing Netbox credentials: set one of api_token, api_token_file, api_token_command or username and password")
+	}
+	if len(sources) > 1 {
+		return "", fmt.Errorf("conflicting Netbox credentials: only one of api_token, api_token_file, api_token_command or username and password can be set, got %s", strings.Join(sources, ", "))
+	}
+
+	switch {
+	case cfg.APIToken != "":
+		return cfg.APIToken, nil
+	case cfg.APITokenFile != "":
+		return readTokenFile(cfg.APITokenFile)
+	case cfg.APITokenCommand != "":
+		return runTokenCommand(ctx, cfg.APITokenCommand)
+	}
+
+	if cfg.Username == "" || cfg.Password == "" {
+		return "", fmt.Errorf("username and password must be set together")
+	}
+	return "", nil
+}
+
+// readTokenFile reads the API token from a file. Surrounding whitespace, like
+// the trailing newline most editors add, is removed.
+func readTokenFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading api_token_file: %s", err)
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("api_token_file %s is empty", path)
+	}
+	return token, nil
+}
+
+// runTokenCommand runs a command with the shell of the platform and returns
+// what it prints as API token.
+func runTokenCommand(ctx context.Context, command string) (string, error) {
+	var cmd *exec.Cmd
+	if goruntime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error running api_token_command: %s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return "", fmt.Errorf("api_token_command did not print a token")
+	}
+	return token, nil
+}
+
+// provisionToken obtains a new API token for a user from Netbox. The
+// generated client neither sends the credentials nor decodes the token, so
+// both are done here. Netbox ignores every other field when provisioning,
+// see setTokenExpiry.
+func provisionToken(ctx context.Context, api *netboxclient.NetBoxAPI, username string, password string) (*models.Token, error) {
+	var token models.Token
+
+	reader := runtime.ClientResponseReaderFunc(func(response runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+		if response.Code() != http.StatusCreated {
+			return nil, runtime.NewAPIError("unexpected response from token provisioning endpoint", response, response.Code())
+		}
+		if err := consumer.Consume(response.Body(), &token); err != nil {
+			return nil, err
+		}
+		return users.NewUsersTokensProvisionCreateCreated(), nil
+	})
+
+	body := map[string]interface{}{
+		"username": username,
+		"password": password,
+	}
+	params := users.NewUsersTokensProvisionCreateParams().WithContext(ctx)
+	_, err := api.Users.UsersTokensProvisionCreate(params, nil, withRequestBody(params, body), func(op *runtime.ClientOperation) {
+		op.Reader = reader
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error provisioning Netbox API token for user %s: %w", username, err)
+	}
+	if token.Key == "" {
+		return nil, fmt.Errorf("error provisioning Netbox API token for user %s: Netbox did not return a token", username)
+	}
+	return &token, nil
+}
+
+// setTokenExpiry makes a provisioned token expire after
+// provisionedTokenLifetime. The API must already authenticate with the token.
+func setTokenExpiry(ctx context.Context, api *netboxclient.NetBoxAPI, id int64) error {
+	body := map[string]interface{}{
+		"expires":     strfmt.DateTime(time.Now().Add(provisionedTokenLifetime)),
+		"description": "Provisioned by terraform-provider-netbox",
+	}
+	params := users.NewUsersTokensPartialUpdateParams().WithContext(ctx).WithID(id)
+	_, err := api.Users.UsersTokensPartialUpdate(params, nil, withRequestBody(params, body))
+	return err
+}
+
+// deleteToken deletes a provisioned token. Failures are only logged, as users
+// may lack the permission to delete their own tokens.
+func deleteToken(ctx context.Context, api *netboxclient.NetBoxAPI, id int64) {
+	ctx, cancel := context.WithTimeout(ctx, tokenDeleteTimeout)
+	defer cancel()
+
+	params := users.NewUsersTokensDeleteParams().WithContext(ctx).WithID(id)
+	if _, err := api.Users.UsersTokensDelete(params, nil); err != nil {
+		tflog.Warn(ctx, "Unable to delete provisioned Netbox API token, it expires on its own", map[string]interface{}{
+			"token_id": id,
+			"error":    err.Error(),
+		})
+		return
+	}
+	tflog.Debug(ctx, "Deleted provisioned Netbox API token", map[string]interface{}{
+		"token_id": id,
+	})
+}
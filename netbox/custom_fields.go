@@ -16,10 +16,39 @@ var customFieldsSchema = &schema.Schema{
 	},
 }
 
+// getCustomFields returns the custom fields of an object that have a value.
+// Netbox returns custom fields without a value as null.
 func getCustomFields(cf interface{}) map[string]interface{} {
 	cfm, ok := cf.(map[string]interface{})
-	if !ok || len(cfm) == 0 {
+	if !ok {
 		return nil
 	}
-	return cfm
+	values := map[string]interface{}{}
+	for key, value := range cfm {
+		if value != nil {
+			values[key] = value
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// getCustomFieldsUpdate returns the custom fields to send when updating an
+// object. Netbox keeps the values of custom fields it does not receive, so
+// custom fields removed from the configuration are sent as null.
+func getCustomFieldsUpdate(d *schema.ResourceData) map[string]interface{} {
+	oldValue, newValue := d.GetChange(customFieldsKey)
+	cf := map[string]interface{}{}
+	for key := range oldValue.(map[string]interface{}) {
+		cf[key] = nil
+	}
+	for key, value := range newValue.(map[string]interface{}) {
+		cf[key] = value
+	}
+	if len(cf) == 0 {
+		return nil
+	}
+	return cf
 }
@@ -0,0 +1,111 @@
+package netbox
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/netbox-community/go-netbox/netbox/client/ipam"
+	"github.com/netbox-community/go-netbox/netbox/models"
+)
+
+func dataSourceNetboxAvailableIPs() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceNetboxAvailableIPsRead,
+		Description: "Reads the unused IP addresses of a prefix or an IP range, without allocating them. Use `netbox_available_ip_address` to allocate an IP address.",
+		Schema: map[string]*schema.Schema{
+			"prefix_id": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ExactlyOneOf: []string{"prefix_id", "ip_range_id"},
+			},
+			"ip_range_id": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ExactlyOneOf: []string{"prefix_id", "ip_range_id"},
+			},
+			"limit": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1000,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "The maximum number of addresses to return. Defaults to 1000, the default maximum page size of Netbox. Netbox never returns more than its configured `MAX_PAGE_SIZE`.",
+			},
+			"ip_addresses": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The unused IP addresses, ordered by address.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ip_address": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"family": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"vrf_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceNetboxAvailableIPsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+
+	if _, ok := d.GetOk("ip_range_id"); ok {
+		if diags := api.requireNetboxVersion("ip_range_id", "3.0.0"); diags.HasError() {
+			return diags
+		}
+	}
+
+	// The available IPs endpoints are not paginated, but take the number of
+	// addresses to return as limit. Without it Netbox returns only its
+	// default page size.
+	filters := url.Values{}
+	filters.Set("limit", strconv.Itoa(d.Get("limit").(int)))
+
+	var id string
+	var payload []*models.AvailableIP
+	if prefixID, ok := d.GetOk("prefix_id"); ok {
+		params := ipam.NewIpamPrefixesAvailableIpsListParams().WithContext(ctx).WithID(int64(prefixID.(int)))
+		res, err := api.Ipam.IpamPrefixesAvailableIpsList(params, nil, withQueryFilters(filters))
+		if err != nil {
+			return errorDiagnostics(err)
+		}
+		id = "prefix/" + strconv.Itoa(prefixID.(int))
+		payload = res.GetPayload()
+	} else {
+		rangeID := d.Get("ip_range_id").(int)
+		params := ipam.NewIpamIPRangesAvailableIpsListParams().WithContext(ctx).WithID(int64(rangeID))
+		res, err := api.Ipam.IpamIPRangesAvailableIpsList(params, nil, withQueryFilters(filters))
+		if err != nil {
+			return errorDiagnostics(err)
+		}
+		id = "ip-range/" + strconv.Itoa(rangeID)
+		payload = res.GetPayload()
+	}
+
+	s := []map[string]interface{}{}
+	for _, v := range payload {
+		var mapping = make(map[string]interface{})
+		mapping["ip_address"] = v.Address
+		mapping["family"] = v.Family
+		if v.Vrf != nil {
+			mapping["vrf_id"] = v.Vrf.ID
+		}
+		s = append(s, mapping)
+	}
+
+	d.SetId(id)
+	return diag.FromErr(d.Set("ip_addresses", s))
+}
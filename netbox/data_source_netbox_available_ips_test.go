@@ -0,0 +1,103 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccNetboxAvailableIPsDataSource_basic(t *testing.T) {
+
+	testSlug := "avail_ips_ds"
+	testName := testAccGetTestName(testSlug)
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "netbox_prefix" "test" {
+  prefix      = "1.1.5.0/29"
+  description = "%[1]s"
+  status      = "active"
+}
+
+resource "netbox_ip_address" "test" {
+  ip_address = "1.1.5.1/29"
+  status     = "active"
+}
+
+resource "netbox_ip_range" "test" {
+  start_address = "1.1.5.2/29"
+  end_address   = "1.1.5.3/29"
+}
+
+data "netbox_available_ips" "prefix" {
+  depends_on = [netbox_ip_address.test, netbox_ip_range.test]
+  prefix_id  = netbox_prefix.test.id
+  limit      = 2
+}
+
+data "netbox_available_ips" "range" {
+  depends_on  = [netbox_ip_address.test]
+  ip_range_id = netbox_ip_range.test.id
+}`, testName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netbox_available_ips.prefix", "ip_addresses.#", "2"),
+					resource.TestCheckResourceAttr("data.netbox_available_ips.prefix", "ip_addresses.0.ip_address", "1.1.5.4/29"),
+					resource.TestCheckResourceAttr("data.netbox_available_ips.prefix", "ip_addresses.0.family", "4"),
+					resource.TestCheckResourceAttr("data.netbox_available_ips.prefix", "ip_addresses.1.ip_address", "1.1.5.5/29"),
+					resource.TestCheckResourceAttr("data.netbox_available_ips.range", "ip_addresses.#", "2"),
+					resource.TestCheckResourceAttr("data.netbox_available_ips.range", "ip_addresses.0.ip_address", "1.1.5.2/29"),
+				),
+			},
+		},
+	})
+}
+
+func TestUnitNetboxAvailableIPsDataSource(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	prefixID := fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.0.0/29", "status": "active"})
+	rangeID := fake.Seed(t, "ipam/ip-ranges", map[string]interface{}{"start_address": "10.0.0.2/29", "end_address": "10.0.0.4/29", "status": "active"})
+	fake.Seed(t, "ipam/ip-addresses", map[string]interface{}{"address": "10.0.0.3/29", "status": "active"})
+
+	for _, tt := range []struct {
+		config     map[string]interface{}
+		expectedID string
+		expected   []string
+	}{
+		{map[string]interface{}{"prefix_id": int(prefixID)}, fmt.Sprintf("prefix/%d", prefixID), []string{"10.0.0.1/29", "10.0.0.5/29", "10.0.0.6/29"}},
+		{map[string]interface{}{"prefix_id": int(prefixID), "limit": 2}, fmt.Sprintf("prefix/%d", prefixID), []string{"10.0.0.1/29", "10.0.0.5/29"}},
+		{map[string]interface{}{"ip_range_id": int(rangeID)}, fmt.Sprintf("ip-range/%d", rangeID), []string{"10.0.0.2/29", "10.0.0.4/29"}},
+	} {
+		d := schema.TestResourceDataRaw(t, dataSourceNetboxAvailableIPs().Schema, tt.config)
+		diags := dataSourceNetboxAvailableIPsRead(context.Background(), d, api)
+		assert.False(t, diags.HasError(), "%v", diags)
+		assert.Equal(t, tt.expectedID, d.Id())
+
+		addresses := []string{}
+		for _, address := range d.Get("ip_addresses").([]interface{}) {
+			address := address.(map[string]interface{})
+			assert.Equal(t, 4, address["family"])
+			addresses = append(addresses, address["ip_address"].(string))
+		}
+		assert.Equal(t, tt.expected, addresses, "%v", tt.config)
+	}
+	assert.Equal(t, 1, fake.Count("ipam/ip-addresses"))
+}
+
+func TestUnitNetboxAvailableIPsDataSource_defaultLimit(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	prefixID := fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.0.0/24", "status": "active"})
+
+	// Without a limit Netbox would return only its default page size of 50
+	d := schema.TestResourceDataRaw(t, dataSourceNetboxAvailableIPs().Schema, map[string]interface{}{"prefix_id": int(prefixID)})
+	diags := dataSourceNetboxAvailableIPsRead(context.Background(), d, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Len(t, d.Get("ip_addresses").([]interface{}), 254)
+}
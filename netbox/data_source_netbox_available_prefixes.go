@@ -0,0 +1,102 @@
+package netbox
+
+import (
+	"context"
+	"net/netip"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/netbox-community/go-netbox/netbox/client/ipam"
+)
+
+func dataSourceNetboxAvailablePrefixes() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceNetboxAvailablePrefixesRead,
+		Description: "Reads the unused blocks of a prefix, without allocating them. Use `netbox_available_prefix` to allocate a prefix.",
+		Schema: map[string]*schema.Schema{
+			"parent_prefix_id": {
+				Type:     schema.TypeInt,
+				Required: true,
+			},
+			"prefix_length": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(0, 128),
+				Description:  "Only return the blocks a prefix of this length fits into.",
+			},
+			"limit": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1000,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "The maximum number of blocks to return. Defaults to 1000, like `limit` of `netbox_available_ips`.",
+			},
+			"prefixes": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The unused blocks, ordered by address.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"prefix": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"family": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"vrf_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceNetboxAvailablePrefixesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+	prefixID := int64(d.Get("parent_prefix_id").(int))
+
+	params := ipam.NewIpamPrefixesAvailablePrefixesListParams().WithContext(ctx).WithID(prefixID)
+	res, err := api.Ipam.IpamPrefixesAvailablePrefixesList(params, nil)
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+
+	prefixLength, filterLength := d.GetOk("prefix_length")
+	limit := d.Get("limit").(int)
+
+	s := []map[string]interface{}{}
+	for _, v := range res.GetPayload() {
+		if len(s) >= limit {
+			break
+		}
+		// Netbox returns the largest unused blocks, which can hold any
+		// prefix of their length or longer
+		if filterLength {
+			block, err := netip.ParsePrefix(v.Prefix)
+			if err != nil {
+				return errorDiagnostics(err)
+			}
+			if block.Bits() > prefixLength.(int) {
+				continue
+			}
+		}
+
+		var mapping = make(map[string]interface{})
+		mapping["prefix"] = v.Prefix
+		mapping["family"] = v.Family
+		if v.Vrf != nil {
+			mapping["vrf_id"] = v.Vrf.ID
+		}
+		s = append(s, mapping)
+	}
+
+	d.SetId(strconv.FormatInt(prefixID, 10))
+	return diag.FromErr(d.Set("prefixes", s))
+}
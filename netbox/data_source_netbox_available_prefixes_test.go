@@ -0,0 +1,106 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccNetboxAvailablePrefixesDataSource_basic(t *testing.T) {
+
+	testSlug := "avail_pfx_ds"
+	testName := testAccGetTestName(testSlug)
+	resource.Test(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "netbox_prefix" "parent" {
+  prefix      = "1.1.4.0/24"
+  description = "%[1]s"
+  status      = "container"
+}
+
+resource "netbox_prefix" "child" {
+  prefix      = "1.1.4.0/26"
+  description = "%[1]s"
+  status      = "active"
+}
+
+data "netbox_available_prefixes" "all" {
+  depends_on       = [netbox_prefix.child]
+  parent_prefix_id = netbox_prefix.parent.id
+}
+
+data "netbox_available_prefixes" "fitting" {
+  depends_on       = [netbox_prefix.child]
+  parent_prefix_id = netbox_prefix.parent.id
+  prefix_length    = 25
+}`, testName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netbox_available_prefixes.all", "prefixes.#", "2"),
+					resource.TestCheckResourceAttr("data.netbox_available_prefixes.all", "prefixes.0.prefix", "1.1.4.64/26"),
+					resource.TestCheckResourceAttr("data.netbox_available_prefixes.all", "prefixes.0.family", "4"),
+					resource.TestCheckResourceAttr("data.netbox_available_prefixes.all", "prefixes.1.prefix", "1.1.4.128/25"),
+					resource.TestCheckResourceAttr("data.netbox_available_prefixes.fitting", "prefixes.#", "1"),
+					resource.TestCheckResourceAttr("data.netbox_available_prefixes.fitting", "prefixes.0.prefix", "1.1.4.128/25"),
+				),
+			},
+		},
+	})
+}
+
+func TestUnitNetboxAvailablePrefixesDataSource(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	vrfID := fake.Seed(t, "ipam/vrfs", map[string]interface{}{"name": "red"})
+	parentID := fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.0.0/22", "status": "container", "vrf": vrfID})
+	fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.0.0/26", "status": "active", "vrf": vrfID})
+	fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.1.0/24", "status": "active", "vrf": vrfID})
+
+	for _, tt := range []struct {
+		config   map[string]interface{}
+		expected []string
+	}{
+		{map[string]interface{}{}, []string{"10.0.0.64/26", "10.0.0.128/25", "10.0.2.0/23"}},
+		{map[string]interface{}{"prefix_length": 25}, []string{"10.0.0.128/25", "10.0.2.0/23"}},
+		{map[string]interface{}{"prefix_length": 24, "limit": 1}, []string{"10.0.2.0/23"}},
+		{map[string]interface{}{"prefix_length": 22}, []string{}},
+	} {
+		tt.config["parent_prefix_id"] = int(parentID)
+		d := schema.TestResourceDataRaw(t, dataSourceNetboxAvailablePrefixes().Schema, tt.config)
+		diags := dataSourceNetboxAvailablePrefixesRead(context.Background(), d, api)
+		assert.False(t, diags.HasError(), "%v", diags)
+		assert.Equal(t, strconv.FormatInt(parentID, 10), d.Id())
+
+		prefixes := []string{}
+		for _, prefix := range d.Get("prefixes").([]interface{}) {
+			prefix := prefix.(map[string]interface{})
+			assert.Equal(t, 4, prefix["family"])
+			assert.Equal(t, int(vrfID), prefix["vrf_id"])
+			prefixes = append(prefixes, prefix["prefix"].(string))
+		}
+		assert.Equal(t, tt.expected, prefixes, "%v", tt.config)
+	}
+}
+
+func TestUnitNetboxAvailablePrefixesDataSource_defaultLimit(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	parentID := fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.0.0/8", "status": "container"})
+	for i := 0; i < 1100; i++ {
+		fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": fmt.Sprintf("10.%d.%d.0/25", i/256, i%256), "status": "active"})
+	}
+
+	// The limit defaults to the one of netbox_available_ips
+	d := schema.TestResourceDataRaw(t, dataSourceNetboxAvailablePrefixes().Schema, map[string]interface{}{"parent_prefix_id": int(parentID)})
+	diags := dataSourceNetboxAvailablePrefixesRead(context.Background(), d, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Len(t, d.Get("prefixes").([]interface{}), 1000)
+	assert.Equal(t, dataSourceNetboxAvailableIPs().Schema["limit"].Default, dataSourceNetboxAvailablePrefixes().Schema["limit"].Default)
+}
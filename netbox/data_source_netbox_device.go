@@ -6,7 +6,6 @@ import (
 	"strconv"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/dcim"
 )
 
@@ -43,22 +42,17 @@ func dataSourceNetboxDevice() *schema.Resource {
 }
 
 func dataSourceNetboxDeviceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	api := m.(*client.NetBoxAPI)
+	api := m.(*providerState)
 	returnDiag := diag.Diagnostics{}
 	name := d.Get("name").(string)
-	params := dcim.NewDcimDevicesListParams()
+	params := dcim.NewDcimDevicesListParams().WithContext(ctx)
 	params.Name = &name
 	limit := int64(2) // Limit of 2 is enough
 	params.Limit = &limit
 
 	res, err := api.Dcim.DcimDevicesList(params, nil)
 	if err != nil {
-		returnDiag = append(returnDiag, diag.Diagnostic{
-			Severity:      0,
-			Summary:       "",
-			Detail:        err.Error(),
-			AttributePath: nil,
-		})
+		return errorDiagnostics(err)
 	}
 
 	if *res.GetPayload().Count > int64(1) {
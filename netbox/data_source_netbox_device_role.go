@@ -1,17 +1,17 @@
 package netbox
 
 import (
-	"errors"
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/dcim"
 )
 
 func dataSourceNetboxDeviceRole() *schema.Resource {
 	return &schema.Resource{
-		Read: dataSourceNetboxDeviceRoleRead,
+		ReadContext: dataSourceNetboxDeviceRoleRead,
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
@@ -29,25 +29,25 @@ func dataSourceNetboxDeviceRole() *schema.Resource {
 	}
 }
 
-func dataSourceNetboxDeviceRoleRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func dataSourceNetboxDeviceRoleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	name := d.Get("name").(string)
-	params := dcim.NewDcimDeviceRolesListParams()
+	params := dcim.NewDcimDeviceRolesListParams().WithContext(ctx)
 	params.Name = &name
 	limit := int64(2) // Limit of 2 is enough
 	params.Limit = &limit
 
 	res, err := api.Dcim.DcimDeviceRolesList(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	if *res.GetPayload().Count > int64(1) {
-		return errors.New("More than one result. Specify a more narrow filter")
+		return diag.Errorf("More than one result. Specify a more narrow filter")
 	}
 	if *res.GetPayload().Count == int64(0) {
-		return errors.New("No result")
+		return diag.Errorf("No result")
 	}
 	result := res.GetPayload().Results[0]
 	d.SetId(strconv.FormatInt(result.ID, 10))
@@ -1,38 +1,22 @@
 package netbox
 
 import (
-	"errors"
-	"fmt"
+	"context"
 	"regexp"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/virtualization"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func dataSourceNetboxInterfaces() *schema.Resource {
 	return &schema.Resource{
-		Read: dataSourceNetboxInterfaceRead,
+		ReadContext: dataSourceNetboxInterfaceRead,
 		Schema: map[string]*schema.Schema{
-			"filter": {
-				Type:     schema.TypeSet,
-				Optional: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"name": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-						"value": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-					},
-				},
-			},
+			"filter": filterSchema(),
 			"name_regex": {
 				Type:         schema.TypeString,
 				Optional:     true,
@@ -130,53 +114,37 @@ func dataSourceNetboxInterfaces() *schema.Resource {
 	}
 }
 
-func dataSourceNetboxInterfaceRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func dataSourceNetboxInterfaceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
-	params := virtualization.NewVirtualizationInterfacesListParams()
+	filters := getQueryFilters(d, map[string]string{"vm_id": "virtual_machine_id"})
 
-	if filter, ok := d.GetOk("filter"); ok {
-		var filterParams = filter.(*schema.Set)
-		for _, f := range filterParams.List() {
-			k := f.(map[string]interface{})["name"]
-			v := f.(map[string]interface{})["value"]
-			vString := v.(string)
-			switch k {
-			case "cluster_id":
-				params.ClusterID = &vString
-			case "mac_address":
-				params.MacAddress = &vString
-			case "name":
-				params.Name = &vString
-			case "tag":
-				params.Tag = &vString
-			case "vm_id":
-				params.VirtualMachineID = &vString
-			default:
-				return fmt.Errorf("'%s' is not a supported filter parameter", k)
-			}
+	results, err := listAll(0, func(limit int64, offset int64) (int64, []*models.VMInterface, error) {
+		params := virtualization.NewVirtualizationInterfacesListParams().WithContext(ctx).WithLimit(&limit).WithOffset(&offset)
+		res, err := api.Virtualization.VirtualizationInterfacesList(params, nil, withQueryFilters(filters))
+		if err != nil {
+			return 0, nil, err
 		}
-	}
-
-	res, err := api.Virtualization.VirtualizationInterfacesList(params, nil)
+		return *res.GetPayload().Count, res.GetPayload().Results, nil
+	})
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	if *res.GetPayload().Count == int64(0) {
-		return errors.New("no result")
+	if len(results) == 0 {
+		return diag.Errorf("no result")
 	}
 
 	var filteredInterfaces []*models.VMInterface
 	if nameRegex, ok := d.GetOk("name_regex"); ok {
 		r := regexp.MustCompile(nameRegex.(string))
-		for _, vmInterface := range res.GetPayload().Results {
+		for _, vmInterface := range results {
 			if r.MatchString(*vmInterface.Name) {
 				filteredInterfaces = append(filteredInterfaces, vmInterface)
 			}
 		}
 	} else {
-		filteredInterfaces = res.GetPayload().Results
+		filteredInterfaces = results
 	}
 
 	var s []map[string]interface{}
@@ -222,7 +190,7 @@ func dataSourceNetboxInterfaceRead(d *schema.ResourceData, m interface{}) error
 	}
 
 	d.SetId(resource.UniqueId())
-	return d.Set("interfaces", s)
+	return diag.FromErr(d.Set("interfaces", s))
 
 }
 
@@ -1,36 +1,20 @@
 package netbox
 
 import (
-	"errors"
-	"fmt"
+	"context"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func dataSourceNetboxIpAddresses() *schema.Resource {
 	return &schema.Resource{
-		Read: dataSourceNetboxIpAddressesRead,
+		ReadContext: dataSourceNetboxIpAddressesRead,
 		Schema: map[string]*schema.Schema{
-			"filter": {
-				Type:     schema.TypeSet,
-				Optional: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"name": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-						"value": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-					},
-				},
-			},
+			"filter": filterSchema(),
 
 			"ip_addresses": {
 				Type:     schema.TypeList,
@@ -105,44 +89,44 @@ func dataSourceNetboxIpAddresses() *schema.Resource {
 	}
 }
 
-func dataSourceNetboxIpAddressesRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
-
-	params := ipam.NewIpamIPAddressesListParams()
-
-	if filter, ok := d.GetOk("filter"); ok {
-		var filterParams = filter.(*schema.Set)
-		for _, f := range filterParams.List() {
-			k := f.(map[string]interface{})["name"]
-			v := f.(map[string]interface{})["value"]
-			vString := v.(string)
-			switch k {
-			case "dns_name":
-				params.DNSName = &vString
-			case "interface_id":
-				params.InterfaceID = &vString
-			case "device_id":
-				params.DeviceID = &vString
-			case "ip_address":
-				params.Address = &vString
-			case "vm_interface_id":
-				params.VminterfaceID = &vString
-			default:
-				return fmt.Errorf("'%s' is not a supported filter parameter", k)
-			}
+func dataSourceNetboxIpAddressesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+
+	filters := getQueryFilters(d, map[string]string{
+		"ip_address":      "address",
+		"vm_interface_id": "vminterface_id",
+	})
+
+	results, err := listAll(0, func(limit int64, offset int64) (int64, []*models.IPAddress, error) {
+		params := ipam.NewIpamIPAddressesListParams().WithContext(ctx).WithLimit(&limit).WithOffset(&offset)
+		// Like readIPAddress, skip the assigned objects the generated client
+		// fails to decode
+		var payload struct {
+			Count   int64 `json:"count"`
+			Results []struct {
+				*models.IPAddress
+				AssignedObject interface{} `json:"assigned_object"`
+			} `json:"results"`
 		}
-	}
-
-	res, err := api.Ipam.IpamIPAddressesList(params, nil)
+		_, err := api.Ipam.IpamIPAddressesList(params, nil, withQueryFilters(filters), withResponseBody(&payload, ipam.NewIpamIPAddressesListOK()))
+		if err != nil {
+			return 0, nil, err
+		}
+		addresses := make([]*models.IPAddress, 0, len(payload.Results))
+		for _, result := range payload.Results {
+			addresses = append(addresses, result.IPAddress)
+		}
+		return payload.Count, addresses, nil
+	})
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	if *res.GetPayload().Count == int64(0) {
-		return errors.New("no result")
+	if len(results) == 0 {
+		return diag.Errorf("no result")
 	}
 
-	filteredIpAddresses := res.GetPayload().Results
+	filteredIpAddresses := results
 
 	var s []map[string]interface{}
 	for _, v := range filteredIpAddresses {
@@ -165,7 +149,7 @@ func dataSourceNetboxIpAddressesRead(d *schema.ResourceData, m interface{}) erro
 	}
 
 	d.SetId(resource.UniqueId())
-	return d.Set("ip_addresses", s)
+	return diag.FromErr(d.Set("ip_addresses", s))
 
 }
 
@@ -1,10 +1,13 @@
 package netbox
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestAccNetboxIpAddressesDataSource_basic(t *testing.T) {
@@ -161,3 +164,25 @@ data "netbox_ip_addresses" "test_list" {
 		},
 	})
 }
+
+func TestUnitNetboxIpAddressesDataSource_assigned(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	interfaceID := fake.Seed(t, "dcim/interfaces", map[string]interface{}{"name": "eth0", "type": "1000base-t"})
+	fake.Seed(t, "ipam/ip-addresses", map[string]interface{}{
+		"address":              "192.0.2.1/24",
+		"status":               "active",
+		"assigned_object_type": "dcim.interface",
+		"assigned_object_id":   interfaceID,
+	})
+	fake.Seed(t, "ipam/ip-addresses", map[string]interface{}{"address": "192.0.2.2/24", "status": "active"})
+
+	d := schema.TestResourceDataRaw(t, dataSourceNetboxIpAddresses().Schema, map[string]interface{}{})
+	diags := dataSourceNetboxIpAddressesRead(context.Background(), d, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	addresses := []string{}
+	for _, address := range d.Get("ip_addresses").([]interface{}) {
+		addresses = append(addresses, address.(map[string]interface{})["ip_address"].(string))
+	}
+	assert.Equal(t, []string{"192.0.2.1/24", "192.0.2.2/24"}, addresses)
+}
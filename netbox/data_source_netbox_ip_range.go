@@ -1,18 +1,18 @@
 package netbox
 
 import (
-	"errors"
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 )
 
 func dataSourceNetboxIpRange() *schema.Resource {
 	return &schema.Resource{
-		Read: dataSourceNetboxIpRangeRead,
+		ReadContext: dataSourceNetboxIpRangeRead,
 		Schema: map[string]*schema.Schema{
 			"id": &schema.Schema{
 				Type:     schema.TypeInt,
@@ -27,12 +27,12 @@ func dataSourceNetboxIpRange() *schema.Resource {
 	}
 }
 
-func dataSourceNetboxIpRangeRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func dataSourceNetboxIpRangeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	contains := d.Get("contains").(string)
 
-	params := ipam.NewIpamIPRangesListParams()
+	params := ipam.NewIpamIPRangesListParams().WithContext(ctx)
 	params.Contains = &contains
 
 	limit := int64(2) // Limit of 2 is enough
@@ -40,14 +40,14 @@ func dataSourceNetboxIpRangeRead(d *schema.ResourceData, m interface{}) error {
 
 	res, err := api.Ipam.IpamIPRangesList(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	if *res.GetPayload().Count > int64(1) {
-		return errors.New("More than one result. Specify a more narrow filter")
+		return diag.Errorf("More than one result. Specify a more narrow filter")
 	}
 	if *res.GetPayload().Count == int64(0) {
-		return errors.New("No result")
+		return diag.Errorf("No result")
 	}
 	result := res.GetPayload().Results[0]
 	d.Set("id", result.ID)
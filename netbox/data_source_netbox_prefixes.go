@@ -0,0 +1,410 @@
+package netbox
+
+import (
+	"context"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/netbox-community/go-netbox/netbox/client/ipam"
+	"github.com/netbox-community/go-netbox/netbox/models"
+)
+
+func dataSourceNetboxPrefixes() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceNetboxPrefixesRead,
+		Description: "Reads the prefixes matching the filters, like `vrf_id`, `site_id`, `role_id`, `tenant_id`, `tag`, `family`, `status`, `within`, `contains` or `mask_length`, along with their place in the prefix hierarchy.",
+		Schema: map[string]*schema.Schema{
+			"filter": filterSchema(),
+			"limit": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "The maximum number of prefixes to read from Netbox. Defaults to all matching prefixes.",
+			},
+			"include_utilization": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Compute the `utilization` of each prefix. Netbox does not return the utilization in its API, so this reads the child prefixes, IP addresses and IP ranges of every prefix.",
+			},
+			"prefixes": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"prefix": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"family": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"vrf_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"site_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"vlan_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"role_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"tenant_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"is_pool": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"mark_utilized": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"tags": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"custom_fields": {
+							Type:     schema.TypeMap,
+							Computed: true,
+						},
+						"created": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"last_updated": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"depth": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The number of prefixes in the same VRF that contain the prefix.",
+						},
+						"children": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The number of prefixes in the same VRF that the prefix contains.",
+						},
+						"utilization": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The utilization of the prefix in percent, computed like Netbox does. Only set if `include_utilization` is true.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceNetboxPrefixesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+
+	filters := getQueryFilters(d, nil)
+
+	var limit int64
+	if value, ok := d.GetOk("limit"); ok {
+		limit = int64(value.(int))
+	}
+
+	results, err := listAll(limit, func(limit int64, offset int64) (int64, []*models.Prefix, error) {
+		params := ipam.NewIpamPrefixesListParams().WithContext(ctx).WithLimit(&limit).WithOffset(&offset)
+		res, err := api.Ipam.IpamPrefixesList(params, nil, withQueryFilters(filters))
+		if err != nil {
+			return 0, nil, err
+		}
+		return *res.GetPayload().Count, res.GetPayload().Results, nil
+	})
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+
+	if len(results) == 0 {
+		return diag.Errorf("no result")
+	}
+
+	utilization := &prefixUtilization{api: api, ranges: map[string][]addressInterval{}}
+
+	var s []map[string]interface{}
+	for _, v := range results {
+		var mapping = make(map[string]interface{})
+
+		mapping["id"] = v.ID
+		mapping["prefix"] = v.Prefix
+		if v.Family != nil {
+			mapping["family"] = v.Family.Value
+		}
+		if v.Status != nil {
+			mapping["status"] = v.Status.Value
+		}
+		if v.Vrf != nil {
+			mapping["vrf_id"] = v.Vrf.ID
+		}
+		if v.Site != nil {
+			mapping["site_id"] = v.Site.ID
+		}
+		if v.Vlan != nil {
+			mapping["vlan_id"] = v.Vlan.ID
+		}
+		if v.Role != nil {
+			mapping["role_id"] = v.Role.ID
+		}
+		if v.Tenant != nil {
+			mapping["tenant_id"] = v.Tenant.ID
+		}
+		mapping["is_pool"] = v.IsPool
+		mapping["mark_utilized"] = v.MarkUtilized
+		mapping["description"] = v.Description
+		var tags []string
+		for _, t := range v.Tags {
+			tags = append(tags, *t.Name)
+		}
+		mapping["tags"] = tags
+		mapping["custom_fields"] = v.CustomFields
+		mapping["created"] = v.Created.String()
+		mapping["last_updated"] = v.LastUpdated.String()
+		mapping["depth"] = v.Depth
+		mapping["children"] = v.Children
+
+		if d.Get("include_utilization").(bool) {
+			percent, err := utilization.get(ctx, v)
+			if err != nil {
+				return errorDiagnostics(err)
+			}
+			mapping["utilization"] = percent
+		}
+
+		s = append(s, mapping)
+	}
+
+	d.SetId(resource.UniqueId())
+	return diag.FromErr(d.Set("prefixes", s))
+}
+
+// prefixUtilization computes the utilization of prefixes like Netbox does
+// in its UI. The IP ranges of each VRF are read once and cached in ranges.
+type prefixUtilization struct {
+	api    *providerState
+	ranges map[string][]addressInterval
+}
+
+// addressInterval is an inclusive interval of IP addresses, represented as
+// integers to count them.
+type addressInterval struct {
+	is4   bool
+	first *big.Int
+	last  *big.Int
+}
+
+// addressObject holds the fields of the prefixes, IP addresses and IP ranges
+// the utilization of a prefix is computed from. Only these fields are
+// decoded, which also avoids the fields the generated models fail to decode.
+type addressObject struct {
+	Prefix       string `json:"prefix"`
+	Address      string `json:"address"`
+	StartAddress string `json:"start_address"`
+	EndAddress   string `json:"end_address"`
+}
+
+type addressObjectList struct {
+	Count   int64           `json:"count"`
+	Results []addressObject `json:"results"`
+}
+
+// get returns the utilization of a prefix in percent. The utilization of
+// containers is the share of addresses covered by child prefixes, the
+// utilization of other prefixes the share of addresses covered by child IP
+// addresses and IP ranges.
+func (u *prefixUtilization) get(ctx context.Context, prefix *models.Prefix) (int64, error) {
+	if prefix.MarkUtilized {
+		return 100, nil
+	}
+	parsed, err := netip.ParsePrefix(*prefix.Prefix)
+	if err != nil {
+		return 0, err
+	}
+	parsed = parsed.Masked()
+
+	vrfID := "null"
+	if prefix.Vrf != nil {
+		vrfID = strconv.FormatInt(prefix.Vrf.ID, 10)
+	}
+
+	var intervals []addressInterval
+	if prefix.Status != nil && prefix.Status.Value != nil && *prefix.Status.Value == "container" {
+		// Like in Netbox, containers in the global table count the child
+		// prefixes of all VRFs
+		filters := url.Values{"within": {parsed.String()}}
+		if prefix.Vrf != nil {
+			filters.Set("vrf_id", vrfID)
+		}
+		children, err := u.list(ctx, "prefixes", filters)
+		if err != nil {
+			return 0, err
+		}
+		for _, child := range children {
+			if childPrefix, err := netip.ParsePrefix(child.Prefix); err == nil {
+				intervals = append(intervals, prefixInterval(childPrefix))
+			}
+		}
+	} else {
+		ranges, err := u.vrfRanges(ctx, vrfID)
+		if err != nil {
+			return 0, err
+		}
+		bounds := prefixInterval(parsed)
+		for _, r := range ranges {
+			if r.is4 == bounds.is4 && r.first.Cmp(bounds.first) >= 0 && r.last.Cmp(bounds.last) <= 0 {
+				intervals = append(intervals, r)
+			}
+		}
+
+		addresses, err := u.list(ctx, "ip-addresses", url.Values{"parent": {parsed.String()}, "vrf_id": {vrfID}})
+		if err != nil {
+			return 0, err
+		}
+		for _, address := range addresses {
+			if addressPrefix, err := netip.ParsePrefix(address.Address); err == nil {
+				value := addressToInt(addressPrefix.Addr())
+				intervals = append(intervals, addressInterval{is4: addressPrefix.Addr().Is4(), first: value, last: value})
+			}
+		}
+	}
+
+	size := new(big.Int).Lsh(big.NewInt(1), uint(parsed.Addr().BitLen()-parsed.Bits()))
+	if parsed.Addr().Is4() && parsed.Bits() < 31 && !prefix.IsPool {
+		// The network and broadcast addresses cannot be used
+		size.Sub(size, big.NewInt(2))
+	}
+
+	used := addressIntervalsSize(intervals)
+	percent := used.Mul(used, big.NewInt(100)).Div(used, size).Int64()
+	if percent > 100 {
+		percent = 100
+	}
+	return percent, nil
+}
+
+// vrfRanges returns the IP ranges of a VRF, or of the global table for the
+// VRF ID null.
+func (u *prefixUtilization) vrfRanges(ctx context.Context, vrfID string) ([]addressInterval, error) {
+	if ranges, ok := u.ranges[vrfID]; ok {
+		return ranges, nil
+	}
+	objects, err := u.list(ctx, "ip-ranges", url.Values{"vrf_id": {vrfID}})
+	if err != nil {
+		return nil, err
+	}
+	ranges := []addressInterval{}
+	for _, object := range objects {
+		start, err := netip.ParsePrefix(object.StartAddress)
+		if err != nil {
+			continue
+		}
+		end, err := netip.ParsePrefix(object.EndAddress)
+		if err != nil {
+			continue
+		}
+		ranges = append(ranges, addressInterval{is4: start.Addr().Is4(), first: addressToInt(start.Addr()), last: addressToInt(end.Addr())})
+	}
+	u.ranges[vrfID] = ranges
+	return ranges, nil
+}
+
+// list reads all prefixes, IP addresses or IP ranges matching the filters.
+func (u *prefixUtilization) list(ctx context.Context, endpoint string, filters url.Values) ([]addressObject, error) {
+	return listAll(0, func(limit int64, offset int64) (int64, []addressObject, error) {
+		var page addressObjectList
+		var err error
+		switch endpoint {
+		case "prefixes":
+			params := ipam.NewIpamPrefixesListParams().WithContext(ctx).WithLimit(&limit).WithOffset(&offset)
+			_, err = u.api.Ipam.IpamPrefixesList(params, nil, withQueryFilters(filters), withResponseBody(&page, ipam.NewIpamPrefixesListOK()))
+		case "ip-addresses":
+			params := ipam.NewIpamIPAddressesListParams().WithContext(ctx).WithLimit(&limit).WithOffset(&offset)
+			_, err = u.api.Ipam.IpamIPAddressesList(params, nil, withQueryFilters(filters), withResponseBody(&page, ipam.NewIpamIPAddressesListOK()))
+		case "ip-ranges":
+			params := ipam.NewIpamIPRangesListParams().WithContext(ctx).WithLimit(&limit).WithOffset(&offset)
+			_, err = u.api.Ipam.IpamIPRangesList(params, nil, withQueryFilters(filters), withResponseBody(&page, ipam.NewIpamIPRangesListOK()))
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		return page.Count, page.Results, nil
+	})
+}
+
+func addressToInt(address netip.Addr) *big.Int {
+	bytes := address.AsSlice()
+	return new(big.Int).SetBytes(bytes)
+}
+
+func prefixInterval(prefix netip.Prefix) addressInterval {
+	prefix = prefix.Masked()
+	first := addressToInt(prefix.Addr())
+	size := new(big.Int).Lsh(big.NewInt(1), uint(prefix.Addr().BitLen()-prefix.Bits()))
+	last := new(big.Int).Add(first, size)
+	return addressInterval{is4: prefix.Addr().Is4(), first: first, last: last.Sub(last, big.NewInt(1))}
+}
+
+// addressIntervalsSize returns the number of addresses covered by the
+// intervals, counting overlapping addresses once.
+func addressIntervalsSize(intervals []addressInterval) *big.Int {
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].first.Cmp(intervals[j].first) < 0 })
+
+	total := new(big.Int)
+	var current *addressInterval
+	for i := range intervals {
+		next := intervals[i]
+		if current != nil && next.first.Cmp(new(big.Int).Add(current.last, big.NewInt(1))) <= 0 {
+			if next.last.Cmp(current.last) > 0 {
+				current.last = next.last
+			}
+			continue
+		}
+		if current != nil {
+			total.Add(total, intervalSize(*current))
+		}
+		current = &addressInterval{first: next.first, last: next.last}
+	}
+	if current != nil {
+		total.Add(total, intervalSize(*current))
+	}
+	return total
+}
+
+func intervalSize(interval addressInterval) *big.Int {
+	size := new(big.Int).Sub(interval.last, interval.first)
+	return size.Add(size, big.NewInt(1))
+}
@@ -0,0 +1,163 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccNetboxPrefixesDataSource_basic(t *testing.T) {
+
+	testSlug := "prefixes_ds_basic"
+	testName := testAccGetTestName(testSlug)
+	resource.ParallelTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		PreCheck:  func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "netbox_vrf" "test" {
+  name = "%[1]s"
+}
+
+resource "netbox_prefix" "parent" {
+  prefix = "10.42.0.0/22"
+  status = "container"
+  vrf_id = netbox_vrf.test.id
+}
+
+resource "netbox_prefix" "child" {
+  prefix = "10.42.1.0/24"
+  status = "active"
+  vrf_id = netbox_vrf.test.id
+}
+
+resource "netbox_ip_address" "test" {
+  ip_address = "10.42.1.1/24"
+  status     = "active"
+  vrf_id     = netbox_vrf.test.id
+}
+
+data "netbox_prefixes" "test" {
+  depends_on          = [netbox_prefix.parent, netbox_prefix.child, netbox_ip_address.test]
+  include_utilization = true
+
+  filter {
+    name  = "vrf_id"
+    value = netbox_vrf.test.id
+  }
+  filter {
+    name  = "within"
+    value = "10.42.0.0/16"
+  }
+}`, testName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.netbox_prefixes.test", "prefixes.#", "2"),
+					resource.TestCheckResourceAttr("data.netbox_prefixes.test", "prefixes.0.prefix", "10.42.0.0/22"),
+					resource.TestCheckResourceAttr("data.netbox_prefixes.test", "prefixes.0.depth", "0"),
+					resource.TestCheckResourceAttr("data.netbox_prefixes.test", "prefixes.0.children", "1"),
+					resource.TestCheckResourceAttr("data.netbox_prefixes.test", "prefixes.0.utilization", "25"),
+					resource.TestCheckResourceAttr("data.netbox_prefixes.test", "prefixes.1.prefix", "10.42.1.0/24"),
+					resource.TestCheckResourceAttr("data.netbox_prefixes.test", "prefixes.1.depth", "1"),
+					resource.TestCheckResourceAttr("data.netbox_prefixes.test", "prefixes.1.utilization", "0"),
+					resource.TestCheckResourceAttrPair("data.netbox_prefixes.test", "prefixes.1.vrf_id", "netbox_vrf.test", "id"),
+				),
+			},
+		},
+	})
+}
+
+func TestUnitNetboxPrefixesDataSource_filter(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	redID := fake.Seed(t, "ipam/vrfs", map[string]interface{}{"name": "red"})
+	fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.0.0/22", "status": "container", "vrf": nil})
+	fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.0.0/24", "status": "active", "vrf": nil})
+	fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.1.0/24", "status": "reserved", "vrf": nil})
+	fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.0.0/24", "status": "active", "vrf": redID})
+
+	for _, tt := range []struct {
+		filters  []interface{}
+		expected []string
+	}{
+		{[]interface{}{map[string]interface{}{"name": "status", "value": "container"}}, []string{"10.0.0.0/22"}},
+		{[]interface{}{map[string]interface{}{"name": "within", "value": "10.0.0.0/22"}}, []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.0.0/24"}},
+		{[]interface{}{
+			map[string]interface{}{"name": "mask_length", "value": "24"},
+			map[string]interface{}{"name": "vrf_id", "value": fmt.Sprint(redID)},
+		}, []string{"10.0.0.0/24"}},
+	} {
+		d := schema.TestResourceDataRaw(t, dataSourceNetboxPrefixes().Schema, map[string]interface{}{"filter": tt.filters})
+		diags := dataSourceNetboxPrefixesRead(context.Background(), d, api)
+		assert.False(t, diags.HasError(), "%v", diags)
+		prefixes := []string{}
+		for _, prefix := range d.Get("prefixes").([]interface{}) {
+			prefixes = append(prefixes, prefix.(map[string]interface{})["prefix"].(string))
+		}
+		assert.Equal(t, tt.expected, prefixes, "%v", tt.filters)
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceNetboxPrefixes().Schema, map[string]interface{}{
+		"filter": []interface{}{map[string]interface{}{"name": "vrf_id", "value": "null"}},
+	})
+	diags := dataSourceNetboxPrefixesRead(context.Background(), d, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	hierarchy := map[string][2]int{}
+	for _, prefix := range d.Get("prefixes").([]interface{}) {
+		prefix := prefix.(map[string]interface{})
+		hierarchy[prefix["prefix"].(string)] = [2]int{prefix["depth"].(int), prefix["children"].(int)}
+	}
+	assert.Equal(t, map[string][2]int{
+		"10.0.0.0/22": {0, 2},
+		"10.0.0.0/24": {1, 0},
+		"10.0.1.0/24": {1, 0},
+	}, hierarchy)
+}
+
+func TestUnitNetboxPrefixesDataSource_utilization(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	redID := fake.Seed(t, "ipam/vrfs", map[string]interface{}{"name": "red"})
+	fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.0.0/22", "status": "container", "vrf": nil})
+	fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.0.0/24", "status": "active", "vrf": nil})
+	fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.1.0/24", "status": "active", "vrf": nil, "is_pool": true})
+	fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.2.0/25", "status": "active", "vrf": nil, "mark_utilized": true})
+	fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.0.0/24", "status": "active", "vrf": redID})
+	fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "2001:db8::/64", "status": "active", "vrf": nil})
+	fake.Seed(t, "ipam/ip-addresses", map[string]interface{}{"address": "10.0.0.1/24", "status": "active", "vrf": nil})
+	fake.Seed(t, "ipam/ip-addresses", map[string]interface{}{"address": "10.0.0.2/24", "status": "active", "vrf": nil})
+	fake.Seed(t, "ipam/ip-addresses", map[string]interface{}{"address": "10.0.0.10/24", "status": "active", "vrf": nil})
+	fake.Seed(t, "ipam/ip-addresses", map[string]interface{}{"address": "10.0.0.3/24", "status": "active", "vrf": redID})
+	fake.Seed(t, "ipam/ip-addresses", map[string]interface{}{"address": "2001:db8::1/64", "status": "active", "vrf": nil})
+	fake.Seed(t, "ipam/ip-ranges", map[string]interface{}{"start_address": "10.0.0.10/24", "end_address": "10.0.0.19/24", "status": "active", "vrf": nil})
+	fake.Seed(t, "ipam/ip-ranges", map[string]interface{}{"start_address": "10.0.1.0/24", "end_address": "10.0.1.127/24", "status": "active", "vrf": nil})
+
+	d := schema.TestResourceDataRaw(t, dataSourceNetboxPrefixes().Schema, map[string]interface{}{"include_utilization": true})
+	diags := dataSourceNetboxPrefixesRead(context.Background(), d, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+
+	utilization := map[string]int{}
+	for _, prefix := range d.Get("prefixes").([]interface{}) {
+		prefix := prefix.(map[string]interface{})
+		key := prefix["prefix"].(string)
+		if prefix["vrf_id"].(int) != 0 {
+			key += "@red"
+		}
+		utilization[key] = prefix["utilization"].(int)
+	}
+	assert.Equal(t, map[string]int{
+		// 640 of 1024 addresses are covered by child prefixes
+		"10.0.0.0/22": 62,
+		// 12 of 254 usable addresses, the range overlaps one IP address
+		"10.0.0.0/24": 4,
+		// Pools have no network and broadcast address
+		"10.0.1.0/24":     50,
+		"10.0.2.0/25":     100,
+		"10.0.0.0/24@red": 0,
+		"2001:db8::/64":   0,
+	}, utilization)
+}
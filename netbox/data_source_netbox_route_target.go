@@ -0,0 +1,62 @@
+package netbox
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/netbox-community/go-netbox/netbox/client/ipam"
+)
+
+func dataSourceNetboxRouteTarget() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceNetboxRouteTargetRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"tenant_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceNetboxRouteTargetRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+
+	name := d.Get("name").(string)
+	params := ipam.NewIpamRouteTargetsListParams().WithContext(ctx)
+	params.Name = &name
+	limit := int64(2) // Limit of 2 is enough
+	params.Limit = &limit
+
+	res, err := api.Ipam.IpamRouteTargetsList(params, nil)
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+
+	if *res.GetPayload().Count > int64(1) {
+		return diag.Errorf("More than one result. Specify a more narrow filter")
+	}
+	if *res.GetPayload().Count == int64(0) {
+		return diag.Errorf("No result")
+	}
+	result := res.GetPayload().Results[0]
+	d.SetId(strconv.FormatInt(result.ID, 10))
+	d.Set("name", result.Name)
+	d.Set("description", result.Description)
+	if result.Tenant != nil {
+		d.Set("tenant_id", result.Tenant.ID)
+	} else {
+		d.Set("tenant_id", nil)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package netbox
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccNetboxRouteTargetDataSource_basic(t *testing.T) {
+
+	testSlug := "rt_ds"
+	testName := testAccGetTestName(testSlug)
+	resource.ParallelTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "netbox_route_target" "test" {
+  name        = "%[1]s"
+  description = "%[1]s description"
+}
+
+data "netbox_route_target" "test" {
+  depends_on = [netbox_route_target.test]
+  name       = "%[1]s"
+}`, testName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.netbox_route_target.test", "id", "netbox_route_target.test", "id"),
+					resource.TestCheckResourceAttr("data.netbox_route_target.test", "description", testName+" description"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,29 @@
+package netbox
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceNetboxRun() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceNetboxRunRead,
+		Description: "Returns the run ID the provider sends as `X-Request-ID` header with every request to Netbox, unless the `headers` of the provider set that header. In that case the header of `headers` is sent instead.\n\n> Netbox does not store the `X-Request-ID` header. Set `changelog_comment` in the provider to record the run ID in the journal of every object the provider creates or updates.",
+		Schema: map[string]*schema.Schema{
+			"run_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The `run_id` of the provider, or the UUID generated for this run if it is not set.",
+			},
+		},
+	}
+}
+
+func dataSourceNetboxRunRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+
+	d.SetId(api.runID)
+	return diag.FromErr(d.Set("run_id", api.runID))
+}
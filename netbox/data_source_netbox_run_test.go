@@ -0,0 +1,35 @@
+package netbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccNetboxRunDataSource_basic(t *testing.T) {
+
+	resource.ParallelTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `data "netbox_run" "test" {}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.netbox_run.test", "run_id"),
+					resource.TestCheckResourceAttrPair("data.netbox_run.test", "id", "data.netbox_run.test", "run_id"),
+				),
+			},
+		},
+	})
+}
+
+func TestUnitNetboxRunDataSource(t *testing.T) {
+	r := Provider().DataSourcesMap["netbox_run"]
+	d := r.TestResourceData()
+
+	diags := r.ReadContext(context.Background(), d, &providerState{runID: "ci-job-1234"})
+	assert.Empty(t, diags)
+	assert.Equal(t, "ci-job-1234", d.Id())
+	assert.Equal(t, "ci-job-1234", d.Get("run_id"))
+}
@@ -1,36 +1,20 @@
 package netbox
 
 import (
-	"errors"
-	"fmt"
+	"context"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/tenancy"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func dataSourceNetboxTenants() *schema.Resource {
 	return &schema.Resource{
-		Read: dataSourceNetboxTenantsRead,
+		ReadContext: dataSourceNetboxTenantsRead,
 		Schema: map[string]*schema.Schema{
-			"filter": {
-				Type:     schema.TypeSet,
-				Optional: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"name": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-						"value": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-					},
-				},
-			},
+			"filter": filterSchema(),
 
 			"tenants": {
 				Type:     schema.TypeList,
@@ -141,38 +125,28 @@ func dataSourceNetboxTenants() *schema.Resource {
 	}
 }
 
-func dataSourceNetboxTenantsRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
-
-	params := tenancy.NewTenancyTenantsListParams()
-
-	if filter, ok := d.GetOk("filter"); ok {
-		var filterParams = filter.(*schema.Set)
-		for _, f := range filterParams.List() {
-			k := f.(map[string]interface{})["name"]
-			v := f.(map[string]interface{})["value"]
-			vString := v.(string)
-			switch k {
-			case "name":
-				params.Name = &vString
-			case "slug":
-				params.Slug = &vString
-			default:
-				return fmt.Errorf("'%s' is not a supported filter parameter", k)
-			}
-		}
-	}
+func dataSourceNetboxTenantsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+
+	filters := getQueryFilters(d, nil)
 
-	res, err := api.Tenancy.TenancyTenantsList(params, nil)
+	results, err := listAll(0, func(limit int64, offset int64) (int64, []*models.Tenant, error) {
+		params := tenancy.NewTenancyTenantsListParams().WithContext(ctx).WithLimit(&limit).WithOffset(&offset)
+		res, err := api.Tenancy.TenancyTenantsList(params, nil, withQueryFilters(filters))
+		if err != nil {
+			return 0, nil, err
+		}
+		return *res.GetPayload().Count, res.GetPayload().Results, nil
+	})
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	if *res.GetPayload().Count == int64(0) {
-		return errors.New("no result")
+	if len(results) == 0 {
+		return diag.Errorf("no result")
 	}
 
-	filteredTenants := res.GetPayload().Results
+	filteredTenants := results
 
 	var s []map[string]interface{}
 	for _, v := range filteredTenants {
@@ -203,7 +177,7 @@ func dataSourceNetboxTenantsRead(d *schema.ResourceData, m interface{}) error {
 	}
 
 	d.SetId(resource.UniqueId())
-	return d.Set("tenants", s)
+	return diag.FromErr(d.Set("tenants", s))
 
 }
 
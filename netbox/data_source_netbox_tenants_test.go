@@ -1,10 +1,13 @@
 package netbox
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestAccNetboxTenantsDataSource_basic(t *testing.T) {
@@ -66,6 +69,39 @@ data "netbox_tenants" "test" {
 	})
 }
 
+func TestUnitNetboxTenantsDataSource_filter(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	fake.Seed(t, "tenancy/tenants", map[string]interface{}{"name": "alpha", "slug": "alpha", "custom_fields": map[string]interface{}{"owner": "ops"}})
+	fake.Seed(t, "tenancy/tenants", map[string]interface{}{"name": "beta", "slug": "beta", "custom_fields": map[string]interface{}{"owner": "dev"}})
+	fake.Seed(t, "tenancy/tenants", map[string]interface{}{"name": "gamma", "slug": "gamma", "custom_fields": map[string]interface{}{"owner": "ops"}})
+
+	for _, tt := range []struct {
+		filters  []interface{}
+		expected []string
+	}{
+		{[]interface{}{map[string]interface{}{"name": "name__ic", "value": "A"}}, []string{"alpha", "beta", "gamma"}},
+		{[]interface{}{map[string]interface{}{"name": "slug__n", "value": "beta"}}, []string{"alpha", "gamma"}},
+		{[]interface{}{
+			map[string]interface{}{"name": "name", "value": "alpha"},
+			map[string]interface{}{"name": "name", "value": "beta"},
+		}, []string{"alpha", "beta"}},
+		{[]interface{}{
+			map[string]interface{}{"name": "cf_owner", "value": "ops"},
+			map[string]interface{}{"name": "name__isw", "value": "g"},
+		}, []string{"gamma"}},
+	} {
+		d := schema.TestResourceDataRaw(t, dataSourceNetboxTenants().Schema, map[string]interface{}{"filter": tt.filters})
+		diags := dataSourceNetboxTenantsRead(context.Background(), d, api)
+		assert.False(t, diags.HasError(), "%v", diags)
+		names := []string{}
+		for _, tenant := range d.Get("tenants").([]interface{}) {
+			names = append(names, tenant.(map[string]interface{})["name"].(string))
+		}
+		assert.Equal(t, tt.expected, names, "%v", tt.filters)
+	}
+}
+
 func TestAccNetboxTenantsDataSource_tenantgroups(t *testing.T) {
 
 	testSlug := "tnt_ds_tenant_group_filter"
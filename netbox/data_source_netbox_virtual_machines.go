@@ -1,47 +1,33 @@
 package netbox
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"regexp"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/virtualization"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func dataSourceNetboxVirtualMachine() *schema.Resource {
 	return &schema.Resource{
-		Read: dataSourceNetboxVirtualMachineRead,
+		ReadContext: dataSourceNetboxVirtualMachineRead,
 		Schema: map[string]*schema.Schema{
-			"filter": {
-				Type:     schema.TypeSet,
-				Optional: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"name": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-						"value": {
-							Type:     schema.TypeString,
-							Required: true,
-						},
-					},
-				},
-			},
+			"filter": filterSchema(),
 			"name_regex": {
 				Type:         schema.TypeString,
 				Optional:     true,
 				ValidateFunc: validation.StringIsValidRegExp,
 			},
 			"limit": {
-				Type:     schema.TypeInt,
-				Optional: true,
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The maximum number of virtual machines to read from Netbox. Defaults to all matching virtual machines, as does 0.",
 			},
 			"vms": {
 				Type:     schema.TypeList,
@@ -134,65 +120,42 @@ func dataSourceNetboxVirtualMachine() *schema.Resource {
 	}
 }
 
-func dataSourceNetboxVirtualMachineRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
-
-	params := virtualization.NewVirtualizationVirtualMachinesListParams()
+func dataSourceNetboxVirtualMachineRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
-	if filter, ok := d.GetOk("filter"); ok {
-		var filterParams = filter.(*schema.Set)
-		for _, f := range filterParams.List() {
-			k := f.(map[string]interface{})["name"]
-			v := f.(map[string]interface{})["value"]
-			switch k {
-			case "cluster_id":
-				var clusterString = v.(string)
-				params.ClusterID = &clusterString
-			case "cluster_group":
-				var clusterGroupString = v.(string)
-				params.ClusterGroup = &clusterGroupString
-			case "name":
-				var nameString = v.(string)
-				params.Name = &nameString
-			case "region":
-				var regionString = v.(string)
-				params.Region = &regionString
-			case "role":
-				var roleString = v.(string)
-				params.Role = &roleString
-			case "site":
-				var siteString = v.(string)
-				params.Site = &siteString
-			default:
-				return fmt.Errorf("'%s' is not a supported filter parameter", k)
-			}
-		}
-	}
+	filters := getQueryFilters(d, nil)
 
-	if limit, ok := d.GetOk("limit"); ok {
-		limitInt := int64(limit.(int))
-		params.Limit = &limitInt
+	var limit int64
+	if value, ok := d.GetOk("limit"); ok {
+		limit = int64(value.(int))
 	}
 
-	res, err := api.Virtualization.VirtualizationVirtualMachinesList(params, nil)
+	results, err := listAll(limit, func(limit int64, offset int64) (int64, []*models.VirtualMachineWithConfigContext, error) {
+		params := virtualization.NewVirtualizationVirtualMachinesListParams().WithContext(ctx).WithLimit(&limit).WithOffset(&offset)
+		res, err := api.Virtualization.VirtualizationVirtualMachinesList(params, nil, withQueryFilters(filters))
+		if err != nil {
+			return 0, nil, err
+		}
+		return *res.GetPayload().Count, res.GetPayload().Results, nil
+	})
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	if *res.GetPayload().Count == int64(0) {
-		return errors.New("no result")
+	if len(results) == 0 {
+		return diag.Errorf("no result")
 	}
 
 	var filteredVms []*models.VirtualMachineWithConfigContext
 	if nameRegex, ok := d.GetOk("name_regex"); ok {
 		r := regexp.MustCompile(nameRegex.(string))
-		for _, vm := range res.GetPayload().Results {
+		for _, vm := range results {
 			if r.MatchString(*vm.Name) {
 				filteredVms = append(filteredVms, vm)
 			}
 		}
 	} else {
-		filteredVms = res.GetPayload().Results
+		filteredVms = results
 	}
 
 	var s []map[string]interface{}
@@ -267,5 +230,5 @@ func dataSourceNetboxVirtualMachineRead(d *schema.ResourceData, m interface{}) e
 	}
 
 	d.SetId(resource.UniqueId())
-	return d.Set("vms", s)
+	return diag.FromErr(d.Set("vms", s))
 }
@@ -1,10 +1,14 @@
 package netbox
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestAccNetboxVirtualMachinesDataSource_basic(t *testing.T) {
@@ -62,6 +66,43 @@ func TestAccNetboxVirtualMachinesDataSource_basic(t *testing.T) {
 	})
 }
 
+func TestUnitNetboxVirtualMachinesDataSource_pagination(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	clusterID := fake.Seed(t, "virtualization/clusters", map[string]interface{}{"name": "cluster"})
+	for i := 0; i < 1234; i++ {
+		fake.Seed(t, "virtualization/virtual-machines", map[string]interface{}{
+			"name":    fmt.Sprintf("vm-%04d", i),
+			"cluster": clusterID,
+			"status":  "active",
+		})
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceNetboxVirtualMachine().Schema, map[string]interface{}{})
+	diags := dataSourceNetboxVirtualMachineRead(context.Background(), d, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	vms := d.Get("vms").([]interface{})
+	assert.Len(t, vms, 1234)
+	assert.Equal(t, "vm-1233", vms[1233].(map[string]interface{})["name"])
+
+	d = schema.TestResourceDataRaw(t, dataSourceNetboxVirtualMachine().Schema, map[string]interface{}{
+		"limit":      1100,
+		"name_regex": "0$",
+	})
+	diags = dataSourceNetboxVirtualMachineRead(context.Background(), d, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Len(t, d.Get("vms").([]interface{}), 110)
+
+	// A limit of 0 reads all virtual machines like before limits were validated
+	config := map[string]interface{}{"limit": 0}
+	diags = dataSourceNetboxVirtualMachine().Validate(terraform.NewResourceConfigRaw(config))
+	assert.False(t, diags.HasError(), "%v", diags)
+	d = schema.TestResourceDataRaw(t, dataSourceNetboxVirtualMachine().Schema, config)
+	diags = dataSourceNetboxVirtualMachineRead(context.Background(), d, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Len(t, d.Get("vms").([]interface{}), 1234)
+}
+
 func testAccNetboxVirtualMachineDataSourceDependencies(testName string) string {
 	return testAccNetboxVirtualMachineFullDependencies(testName) + fmt.Sprintf(`
 resource "netbox_virtual_machine" "test0" {
@@ -1,53 +1,95 @@
 package netbox
 
 import (
-	"errors"
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 )
 
 func dataSourceNetboxVrf() *schema.Resource {
 	return &schema.Resource{
-		Read: dataSourceNetboxVrfRead,
+		ReadContext: dataSourceNetboxVrfRead,
 		Schema: map[string]*schema.Schema{
 			"name": {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				AtLeastOneOf: []string{"name", "rd"},
+			},
+			"rd": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				AtLeastOneOf: []string{"name", "rd"},
+				Description:  "Route distinguisher of the VRF.",
 			},
 			"tenant_id": {
 				Type:     schema.TypeInt,
 				Optional: true,
 			},
+			"enforce_unique": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"import_targets": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+				Description: "IDs of the route targets the VRF imports routes from.",
+			},
+			"export_targets": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+				Description: "IDs of the route targets the VRF exports routes to.",
+			},
 		},
 	}
 }
 
-func dataSourceNetboxVrfRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func dataSourceNetboxVrfRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
-	name := d.Get("name").(string)
-	params := ipam.NewIpamVrfsListParams()
-	params.Name = &name
+	params := ipam.NewIpamVrfsListParams().WithContext(ctx)
+	if name, ok := d.GetOk("name"); ok {
+		params.Name = strToPtr(name.(string))
+	}
+	if rd, ok := d.GetOk("rd"); ok {
+		params.Rd = strToPtr(rd.(string))
+	}
 	limit := int64(2) // Limit of 2 is enough
 	params.Limit = &limit
 
 	res, err := api.Ipam.IpamVrfsList(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	if *res.GetPayload().Count > int64(1) {
-		return errors.New("More than one result. Specify a more narrow filter")
+		return diag.Errorf("More than one result. Specify a more narrow filter")
 	}
 	if *res.GetPayload().Count == int64(0) {
-		return errors.New("No result")
+		return diag.Errorf("No result")
 	}
 	result := res.GetPayload().Results[0]
 	d.SetId(strconv.FormatInt(result.ID, 10))
 	d.Set("name", result.Name)
+	d.Set("rd", result.Rd)
+	d.Set("enforce_unique", result.EnforceUnique)
+	d.Set("description", result.Description)
+	d.Set("import_targets", flattenRouteTargetIDs(result.ImportTargets))
+	d.Set("export_targets", flattenRouteTargetIDs(result.ExportTargets))
 	if result.Tenant != nil {
 		d.Set("tenant_id", result.Tenant.ID)
 	} else {
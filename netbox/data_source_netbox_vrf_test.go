@@ -1,10 +1,15 @@
 package netbox
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestAccNetboxVrfDataSource_basic(t *testing.T) {
@@ -31,3 +36,51 @@ data "netbox_vrf" "test" {
 		},
 	})
 }
+
+func TestAccNetboxVrfDataSource_rd(t *testing.T) {
+
+	testSlug := "vrf_ds_rd"
+	testName := testAccGetTestName(testSlug)
+	rd := fmt.Sprintf("65001:%d", acctest.RandIntRange(1, 65535))
+	resource.ParallelTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "netbox_vrf" "test" {
+  name = "%[1]s"
+  rd   = "%[2]s"
+}
+
+data "netbox_vrf" "test" {
+  depends_on = [netbox_vrf.test]
+  rd         = "%[2]s"
+}`, testName, rd),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.netbox_vrf.test", "id", "netbox_vrf.test", "id"),
+					resource.TestCheckResourceAttr("data.netbox_vrf.test", "name", testName),
+				),
+			},
+		},
+	})
+}
+
+func TestUnitNetboxVrfDataSource_rd(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	targetID := fake.Seed(t, "ipam/route-targets", map[string]interface{}{"name": "65000:1"})
+	fake.Seed(t, "ipam/vrfs", map[string]interface{}{"name": "red", "rd": "65000:100", "enforce_unique": true})
+	blueID := fake.Seed(t, "ipam/vrfs", map[string]interface{}{"name": "blue", "rd": "65000:200", "enforce_unique": false, "import_targets": []interface{}{targetID}})
+
+	d := schema.TestResourceDataRaw(t, dataSourceNetboxVrf().Schema, map[string]interface{}{"rd": "65000:200"})
+	diags := dataSourceNetboxVrfRead(context.Background(), d, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, strconv.FormatInt(blueID, 10), d.Id())
+	assert.Equal(t, "blue", d.Get("name"))
+	assert.Equal(t, false, d.Get("enforce_unique"))
+	assert.Equal(t, []interface{}{int(targetID)}, d.Get("import_targets").(*schema.Set).List())
+
+	d = schema.TestResourceDataRaw(t, dataSourceNetboxVrf().Schema, map[string]interface{}{"name": "blue", "rd": "65000:100"})
+	diags = dataSourceNetboxVrfRead(context.Background(), d, api)
+	assert.True(t, diags.HasError())
+}
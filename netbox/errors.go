@@ -0,0 +1,280 @@
+package netbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/runtime"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// errorIsNotFound reports whether err is a 404 response from Netbox. The
+// generated client returns a *runtime.APIError for status codes that are not
+// part of the API spec. Typed *Default responses expose the status code via
+// a Code method instead.
+func errorIsNotFound(err error) bool {
+	var apiError *runtime.APIError
+	if errors.As(err, &apiError) {
+		return apiError.IsCode(http.StatusNotFound)
+	}
+
+	var defaultResponse interface{ Code() int }
+	if errors.As(err, &defaultResponse) {
+		return defaultResponse.Code() == http.StatusNotFound
+	}
+
+	return false
+}
+
+// netboxError is an error response from Netbox with the messages decoded from
+// its body.
+type netboxError struct {
+	apiError *runtime.APIError
+	// Method and Path identify the operation that failed.
+	Method string
+	Path   string
+	// RequestID is the ID Netbox assigned to the request.
+	RequestID string
+	// FieldErrors maps the fields Netbox rejected to its messages.
+	FieldErrors map[string][]string
+	// Messages are errors that do not concern a single field.
+	Messages []string
+}
+
+// maxErrorBodySize is the number of bytes of an error response that are read
+// at most.
+const maxErrorBodySize = 1 << 20
+
+// nonFieldErrorKeys are keys of error bodies that hold errors of the whole
+// request rather than of a field.
+var nonFieldErrorKeys = []string{"detail", "non_field_errors", "__all__", "error"}
+
+// newNetboxError decodes the body of an error response. Netbox returns
+// validation errors as an object that maps field names to messages, and
+// other errors as an object with a detail message.
+func newNetboxError(method string, path string, apiError *runtime.APIError, response runtime.ClientResponse) *netboxError {
+	e := &netboxError{
+		apiError:    apiError,
+		Method:      method,
+		Path:        path,
+		RequestID:   response.GetHeader("X-Request-ID"),
+		FieldErrors: map[string][]string{},
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body(), maxErrorBodySize))
+	if err != nil || len(bytes.TrimSpace(body)) == 0 {
+		return e
+	}
+
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		// Django answers unhandled exceptions with an HTML page
+		if strings.HasPrefix(response.GetHeader("Content-Type"), "text/plain") {
+			e.Messages = []string{strings.TrimSpace(string(body))}
+		}
+		return e
+	}
+
+	fields, ok := payload.(map[string]interface{})
+	if !ok {
+		e.Messages = errorMessages(payload, "")
+		return e
+	}
+	for field, value := range fields {
+		if isNonFieldErrorKey(field) {
+			e.Messages = append(e.Messages, errorMessages(value, "")...)
+		} else if messages := errorMessages(value, ""); len(messages) > 0 {
+			e.FieldErrors[field] = messages
+		}
+	}
+	sort.Strings(e.Messages)
+	return e
+}
+
+func isNonFieldErrorKey(key string) bool {
+	for _, nonFieldKey := range nonFieldErrorKeys {
+		if key == nonFieldKey {
+			return true
+		}
+	}
+	return false
+}
+
+// errorMessages flattens the messages of an error body. Errors of nested
+// objects are prefixed with their path below the field.
+func errorMessages(value interface{}, prefix string) []string {
+	var messages []string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		if prefix != "" {
+			v = prefix + ": " + v
+		}
+		messages = append(messages, v)
+	case []interface{}:
+		for i, item := range v {
+			itemPrefix := prefix
+			if _, ok := item.(string); !ok {
+				itemPrefix = fmt.Sprintf("%s[%d]", prefix, i)
+			}
+			messages = append(messages, errorMessages(item, itemPrefix)...)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			keyPrefix := key
+			if prefix != "" {
+				keyPrefix = prefix + "." + key
+			}
+			messages = append(messages, errorMessages(v[key], keyPrefix)...)
+		}
+	default:
+		messages = append(messages, errorMessages(fmt.Sprint(v), prefix)...)
+	}
+	return messages
+}
+
+// fields returns the names of the rejected fields in order.
+func (e *netboxError) fields() []string {
+	fields := make([]string, 0, len(e.FieldErrors))
+	for field := range e.FieldErrors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// request describes the failed request and its outcome.
+func (e *netboxError) request() string {
+	request := fmt.Sprintf("%s %s returned %d %s", e.Method, e.Path, e.apiError.Code, http.StatusText(e.apiError.Code))
+	if e.RequestID != "" {
+		request += fmt.Sprintf(" (request ID %s)", e.RequestID)
+	}
+	return request
+}
+
+func (e *netboxError) Error() string {
+	messages := append([]string{}, e.Messages...)
+	for _, field := range e.fields() {
+		for _, message := range e.FieldErrors[field] {
+			messages = append(messages, field+": "+message)
+		}
+	}
+	if len(messages) == 0 {
+		return e.request()
+	}
+	return e.request() + ": " + strings.Join(messages, "; ")
+}
+
+// Unwrap returns the error of the generated client, so that errorIsNotFound
+// and errors.As keep working.
+func (e *netboxError) Unwrap() error {
+	return e.apiError
+}
+
+// errorDiagnostics translates err into diagnostics. An error response from
+// Netbox results in one diagnostic per rejected field, whose attribute path
+// names the field. withErrorDiagnostics points these paths at the attributes
+// of the resource.
+func errorDiagnostics(err error) diag.Diagnostics {
+	if err == nil {
+		return nil
+	}
+
+	var netboxErr *netboxError
+	if !errors.As(err, &netboxErr) {
+		return diag.FromErr(err)
+	}
+
+	var diags diag.Diagnostics
+	if len(netboxErr.Messages) > 0 || len(netboxErr.FieldErrors) == 0 {
+		detail := netboxErr.request()
+		if len(netboxErr.Messages) > 0 {
+			detail = strings.Join(netboxErr.Messages, "\n") + "\n\n" + detail
+		}
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Netbox returned %d %s", netboxErr.apiError.Code, http.StatusText(netboxErr.apiError.Code)),
+			Detail:   detail,
+		})
+	}
+	for _, field := range netboxErr.fields() {
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       fmt.Sprintf("Netbox rejected the value of %s", field),
+			Detail:        strings.Join(netboxErr.FieldErrors[field], "\n") + "\n\n" + netboxErr.request(),
+			AttributePath: cty.GetAttrPath(field),
+		})
+	}
+	return diags
+}
+
+// attributeForField returns the attribute of a schema that holds the value of
+// a Netbox field. References to other objects are usually named after the
+// field with an _id suffix.
+func attributeForField(s map[string]*schema.Schema, field string) (string, bool) {
+	for _, name := range []string{field, field + "_id", field + "_ids"} {
+		if _, ok := s[name]; ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// resolveAttributePaths points the attribute paths of diagnostics that name a
+// Netbox field at the matching attribute of the schema. Paths of fields
+// without a matching attribute are removed.
+func resolveAttributePaths(diags diag.Diagnostics, s map[string]*schema.Schema) diag.Diagnostics {
+	for i, d := range diags {
+		if len(d.AttributePath) != 1 {
+			continue
+		}
+		step, ok := d.AttributePath[0].(cty.GetAttrStep)
+		if !ok {
+			continue
+		}
+		if name, ok := attributeForField(s, step.Name); ok {
+			diags[i].AttributePath = cty.GetAttrPath(name)
+		} else {
+			diags[i].AttributePath = nil
+		}
+	}
+	return diags
+}
+
+// crudContextFunc is the signature shared by the CRUD functions of resources
+// and data sources.
+type crudContextFunc = func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics
+
+// withErrorDiagnostics makes the CRUD functions of a resource or data source
+// translate errors from Netbox into diagnostics with attribute paths of its
+// schema.
+func withErrorDiagnostics(r *schema.Resource) *schema.Resource {
+	wrap := func(f crudContextFunc) crudContextFunc {
+		if f == nil {
+			return nil
+		}
+		return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			return resolveAttributePaths(f(ctx, d, m), r.Schema)
+		}
+	}
+
+	r.CreateContext = wrap(r.CreateContext)
+	r.ReadContext = wrap(r.ReadContext)
+	r.UpdateContext = wrap(r.UpdateContext)
+	r.DeleteContext = wrap(r.DeleteContext)
+	return r
+}
@@ -0,0 +1,225 @@
+package netbox
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/stretchr/testify/assert"
+)
+
+type testDefaultResponse struct {
+	code int
+}
+
+func (o *testDefaultResponse) Code() int {
+	return o.code
+}
+
+func (o *testDefaultResponse) Error() string {
+	return fmt.Sprintf("[GET /ipam/prefixes/{id}/][%d] ipam_prefixes_read default", o.code)
+}
+
+func TestErrorIsNotFound(t *testing.T) {
+
+	assert.True(t, errorIsNotFound(runtime.NewAPIError("ipam_prefixes_read", nil, 404)))
+	assert.False(t, errorIsNotFound(runtime.NewAPIError("ipam_prefixes_read", nil, 400)))
+
+	assert.True(t, errorIsNotFound(&testDefaultResponse{code: 404}))
+	assert.False(t, errorIsNotFound(&testDefaultResponse{code: 500}))
+
+	assert.True(t, errorIsNotFound(fmt.Errorf("wrapped: %w", runtime.NewAPIError("ipam_prefixes_read", nil, 404))))
+	assert.False(t, errorIsNotFound(errors.New("dial tcp: connection refused")))
+	assert.False(t, errorIsNotFound(nil))
+}
+
+// testErrorResponse is an error response of the generated client.
+type testErrorResponse struct {
+	code        int
+	contentType string
+	body        string
+}
+
+func (r testErrorResponse) Code() int                  { return r.code }
+func (r testErrorResponse) Message() string            { return http.StatusText(r.code) }
+func (r testErrorResponse) GetHeaders(string) []string { return nil }
+func (r testErrorResponse) Body() io.ReadCloser        { return io.NopCloser(strings.NewReader(r.body)) }
+
+func (r testErrorResponse) GetHeader(name string) string {
+	switch name {
+	case "Content-Type":
+		return r.contentType
+	case "X-Request-ID":
+		return "4f8c7a3e"
+	}
+	return ""
+}
+
+func testNetboxError(code int, contentType string, body string) *netboxError {
+	response := testErrorResponse{code: code, contentType: contentType, body: body}
+	return newNetboxError(http.MethodPost, "/ipam/prefixes/", runtime.NewAPIError("unexpected response", response, code), response)
+}
+
+func TestNewNetboxError(t *testing.T) {
+	testCases := []struct {
+		name        string
+		code        int
+		contentType string
+		body        string
+		fields      map[string][]string
+		messages    []string
+		message     string
+	}{
+		{
+			name:        "FieldErrors",
+			code:        400,
+			contentType: "application/json",
+			body:        `{"prefix": ["Enter a valid IPv4 or IPv6 address with optional mask."], "status": ["\"foo\" is not a valid choice."]}`,
+			fields: map[string][]string{
+				"prefix": {"Enter a valid IPv4 or IPv6 address with optional mask."},
+				"status": {`"foo" is not a valid choice.`},
+			},
+			message: `POST /ipam/prefixes/ returned 400 Bad Request (request ID 4f8c7a3e): prefix: Enter a valid IPv4 or IPv6 address with optional mask.; status: "foo" is not a valid choice.`,
+		},
+		{
+			name:        "NestedFieldErrors",
+			code:        400,
+			contentType: "application/json",
+			body:        `{"tags": [{}, {"name": ["This field is required."]}]}`,
+			fields: map[string][]string{
+				"tags": {"[1].name: This field is required."},
+			},
+			message: `POST /ipam/prefixes/ returned 400 Bad Request (request ID 4f8c7a3e): tags: [1].name: This field is required.`,
+		},
+		{
+			name:        "Detail",
+			code:        409,
+			contentType: "application/json",
+			body:        `{"detail": "Insufficient space is available to accommodate the requested prefix size(s)"}`,
+			fields:      map[string][]string{},
+			messages:    []string{"Insufficient space is available to accommodate the requested prefix size(s)"},
+			message:     `POST /ipam/prefixes/ returned 409 Conflict (request ID 4f8c7a3e): Insufficient space is available to accommodate the requested prefix size(s)`,
+		},
+		{
+			name:        "NonFieldErrors",
+			code:        400,
+			contentType: "application/json",
+			body:        `{"non_field_errors": ["The fields name, site must make a unique set."]}`,
+			fields:      map[string][]string{},
+			messages:    []string{"The fields name, site must make a unique set."},
+			message:     `POST /ipam/prefixes/ returned 400 Bad Request (request ID 4f8c7a3e): The fields name, site must make a unique set.`,
+		},
+		{
+			name:        "List",
+			code:        400,
+			contentType: "application/json",
+			body:        `["Cannot delete some instances of model 'Prefix' because they are referenced through protected foreign keys."]`,
+			fields:      map[string][]string{},
+			messages:    []string{"Cannot delete some instances of model 'Prefix' because they are referenced through protected foreign keys."},
+			message:     `POST /ipam/prefixes/ returned 400 Bad Request (request ID 4f8c7a3e): Cannot delete some instances of model 'Prefix' because they are referenced through protected foreign keys.`,
+		},
+		{
+			name:        "HTML",
+			code:        500,
+			contentType: "text/html",
+			body:        `<html><body>Server Error</body></html>`,
+			fields:      map[string][]string{},
+			message:     `POST /ipam/prefixes/ returned 500 Internal Server Error (request ID 4f8c7a3e)`,
+		},
+		{
+			name:        "Empty",
+			code:        403,
+			contentType: "application/json",
+			body:        ``,
+			fields:      map[string][]string{},
+			message:     `POST /ipam/prefixes/ returned 403 Forbidden (request ID 4f8c7a3e)`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testNetboxError(tt.code, tt.contentType, tt.body)
+			assert.Equal(t, tt.fields, err.FieldErrors)
+			assert.Equal(t, tt.messages, err.Messages)
+			assert.Equal(t, "4f8c7a3e", err.RequestID)
+			assert.Equal(t, tt.message, err.Error())
+
+			var apiError *runtime.APIError
+			assert.ErrorAs(t, err, &apiError)
+			assert.Equal(t, tt.code, apiError.Code)
+		})
+	}
+}
+
+func TestErrorDiagnostics(t *testing.T) {
+	assert.Nil(t, errorDiagnostics(nil))
+	assert.Equal(t, diag.FromErr(errors.New("dial tcp: connection refused")), errorDiagnostics(errors.New("dial tcp: connection refused")))
+
+	diags := errorDiagnostics(fmt.Errorf("creating prefix: %w", testNetboxError(400, "application/json", `{"detail": "Invalid request.", "vrf": ["Invalid pk \"4242\" - object does not exist."], "prefix": ["Duplicate prefix found."]}`)))
+	assert.Equal(t, diag.Diagnostics{
+		{
+			Severity: diag.Error,
+			Summary:  "Netbox returned 400 Bad Request",
+			Detail:   "Invalid request.\n\nPOST /ipam/prefixes/ returned 400 Bad Request (request ID 4f8c7a3e)",
+		},
+		{
+			Severity:      diag.Error,
+			Summary:       "Netbox rejected the value of prefix",
+			Detail:        "Duplicate prefix found.\n\nPOST /ipam/prefixes/ returned 400 Bad Request (request ID 4f8c7a3e)",
+			AttributePath: cty.GetAttrPath("prefix"),
+		},
+		{
+			Severity:      diag.Error,
+			Summary:       "Netbox rejected the value of vrf",
+			Detail:        "Invalid pk \"4242\" - object does not exist.\n\nPOST /ipam/prefixes/ returned 400 Bad Request (request ID 4f8c7a3e)",
+			AttributePath: cty.GetAttrPath("vrf"),
+		},
+	}, diags)
+
+	diags = resolveAttributePaths(diags, resourceNetboxPrefix().Schema)
+	assert.Nil(t, diags[0].AttributePath)
+	assert.Equal(t, cty.GetAttrPath("prefix"), diags[1].AttributePath)
+	assert.Equal(t, cty.GetAttrPath("vrf_id"), diags[2].AttributePath)
+
+	diags = resolveAttributePaths(errorDiagnostics(testNetboxError(400, "application/json", `{"scope": ["Unknown."]}`)), resourceNetboxPrefix().Schema)
+	assert.Nil(t, diags[0].AttributePath)
+}
+
+func TestUnitWithErrorDiagnostics(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	r := Provider().ResourcesMap["netbox_prefix"]
+	assert.Nil(t, r.Create)
+	assert.NotNil(t, r.CreateContext)
+
+	_, diags := testUnitApply(t, r, nil, map[string]interface{}{
+		"prefix": "10.1.0.0/24",
+		"status": "active",
+		"vrf_id": 4242,
+	}, api)
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, "Netbox rejected the value of vrf", diags[0].Summary)
+		assert.Equal(t, cty.GetAttrPath("vrf_id"), diags[0].AttributePath)
+		assert.Regexp(t, `request ID fake-request-\d+`, diags[0].Detail)
+	}
+
+	state, diags := testUnitApply(t, r, nil, map[string]interface{}{
+		"prefix": "10.1.0.0/24",
+		"status": "active",
+	}, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+
+	// Objects deleted outside of Terraform are still detected
+	id, _ := strconv.ParseInt(state.ID, 10, 64)
+	fake.Delete("ipam/prefixes", id)
+	state, diags = testUnitRefresh(t, r, state, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Nil(t, state)
+}
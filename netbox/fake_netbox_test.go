@@ -0,0 +1,1398 @@
+package netbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"os"
+	"os/exec"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/netbox-community/go-netbox/netbox/client"
+	"github.com/netbox-community/go-netbox/netbox/client/ipam"
+	"github.com/netbox-community/go-netbox/netbox/client/tenancy"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeNetboxModels maps the API endpoints of the object types managed by
+// the provider to the go-netbox model Netbox answers with. The fake uses the
+// models to turn the writable representation it receives into the nested
+// representation it returns, so it stays in sync with the generated client.
+var fakeNetboxModels = map[string]interface{}{
+	"circuits/circuit-terminations":   models.CircuitTermination{},
+	"circuits/circuit-types":          models.CircuitType{},
+	"circuits/circuits":               models.Circuit{},
+	"circuits/providers":              models.Provider{},
+	"dcim/device-roles":               models.DeviceRole{},
+	"dcim/device-types":               models.DeviceType{},
+	"dcim/devices":                    models.DeviceWithConfigContext{},
+	"dcim/interfaces":                 models.Interface{},
+	"dcim/manufacturers":              models.Manufacturer{},
+	"dcim/platforms":                  models.Platform{},
+	"dcim/regions":                    models.Region{},
+	"dcim/sites":                      models.Site{},
+	"extras/custom-fields":            models.CustomField{},
+	"extras/journal-entries":          models.JournalEntry{},
+	"extras/tags":                     models.Tag{},
+	"ipam/aggregates":                 models.Aggregate{},
+	"ipam/ip-addresses":               models.IPAddress{},
+	"ipam/ip-ranges":                  models.IPRange{},
+	"ipam/prefixes":                   models.Prefix{},
+	"ipam/rirs":                       models.RIR{},
+	"ipam/roles":                      models.Role{},
+	"ipam/route-targets":              models.RouteTarget{},
+	"ipam/services":                   models.Service{},
+	"ipam/vlan-groups":                models.VLANGroup{},
+	"ipam/vlans":                      models.VLAN{},
+	"ipam/vrfs":                       models.VRF{},
+	"tenancy/tenant-groups":           models.TenantGroup{},
+	"tenancy/tenants":                 models.Tenant{},
+	"users/tokens":                    models.Token{},
+	"users/users":                     models.User{},
+	"virtualization/cluster-groups":   models.ClusterGroup{},
+	"virtualization/cluster-types":    models.ClusterType{},
+	"virtualization/clusters":         models.Cluster{},
+	"virtualization/interfaces":       models.VMInterface{},
+	"virtualization/virtual-machines": models.VirtualMachineWithConfigContext{},
+}
+
+// fakeNetboxContentTypes maps the content types generic relations can refer
+// to, like the scope of a VLAN group, to the endpoint of their objects.
+var fakeNetboxContentTypes = map[string]string{
+	"dcim.interface":              "dcim/interfaces",
+	"dcim.region":                 "dcim/regions",
+	"dcim.site":                   "dcim/sites",
+	"ipam.prefix":                 "ipam/prefixes",
+	"tenancy.tenant":              "tenancy/tenants",
+	"virtualization.cluster":      "virtualization/clusters",
+	"virtualization.clustergroup": "virtualization/cluster-groups",
+	"virtualization.vminterface":  "virtualization/interfaces",
+}
+
+// fakeNetboxGenericRelations are the fields Netbox renders the object of a
+// generic relation to. The relation itself is set by the writable fields
+// <field>_type and <field>_id.
+var fakeNetboxGenericRelations = []string{"assigned_object", "scope"}
+
+// fakeNetboxDisplayFields are the fields Netbox builds the display string of
+// an object from, in order of preference.
+var fakeNetboxDisplayFields = []string{"name", "address", "prefix", "cid", "username", "rd", "key", "vid"}
+
+// fakeNetbox is an in-memory stand-in for the Netbox API. It implements
+// CRUD, list filters and pagination for the endpoints in fakeNetboxModels as
+// well as the endpoints that allocate IP addresses, prefixes and VLANs, so
+// the CRUD functions of the provider can be tested without a Netbox
+// instance.
+type fakeNetbox struct {
+	*httptest.Server
+
+	// Version is the version reported by the status endpoint.
+	Version string
+
+	mu        sync.Mutex
+	lastID    int64
+	objects   map[string]map[int64]map[string]interface{}
+	endpoints map[string]string
+	requests  []string
+}
+
+// fakeNetboxError is an error response of the fake. Its body mirrors the
+// bodies Netbox returns for validation errors.
+type fakeNetboxError struct {
+	status int
+	body   interface{}
+}
+
+func (e *fakeNetboxError) Error() string {
+	return fmt.Sprintf("%d: %v", e.status, e.body)
+}
+
+func fakeNetboxFieldError(field string, format string, args ...interface{}) *fakeNetboxError {
+	return &fakeNetboxError{
+		status: http.StatusBadRequest,
+		body:   map[string][]string{field: {fmt.Sprintf(format, args...)}},
+	}
+}
+
+var fakeNetboxNotFound = &fakeNetboxError{
+	status: http.StatusNotFound,
+	body:   map[string]string{"detail": "Not found."},
+}
+
+// newFakeNetbox starts a fake Netbox that is shut down when the test ends.
+func newFakeNetbox(t *testing.T) *fakeNetbox {
+	f := &fakeNetbox{
+		Version:   "3.1.9",
+		objects:   map[string]map[int64]map[string]interface{}{},
+		endpoints: map[string]string{},
+	}
+	for endpoint, model := range fakeNetboxModels {
+		f.objects[endpoint] = map[int64]map[string]interface{}{}
+		name := reflect.TypeOf(model).Name()
+		f.endpoints[name] = endpoint
+		f.endpoints[strings.TrimSuffix(name, "WithConfigContext")] = endpoint
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// providerState returns a provider state with a client for the fake.
+func (f *fakeNetbox) providerState(t *testing.T) *providerState {
+	config := Config{
+		APIToken:  "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL: f.URL,
+	}
+	netboxClient, err := config.Client(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &providerState{NetBoxAPI: netboxClient.(*client.NetBoxAPI)}
+}
+
+// providerConfig returns a provider block that points the provider to the
+// fake.
+func (f *fakeNetbox) providerConfig() string {
+	return fmt.Sprintf(`
+provider "netbox" {
+  server_url = "%s"
+  api_token  = "07b12b765127747e4afd56cb531b7bf9c61f3c30"
+}
+`, f.URL)
+}
+
+// Seed stores an object given in its writable representation without going
+// through the API and returns its ID.
+func (f *fakeNetbox) Seed(t *testing.T, endpoint string, body map[string]interface{}) int64 {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	object, err := f.create(endpoint, decodeFakeNetboxJSON(raw).(map[string]interface{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return object["id"].(int64)
+}
+
+// Get returns a copy of a stored object, or nil if it does not exist.
+func (f *fakeNetbox) Get(endpoint string, id int64) map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	object, ok := f.objects[endpoint][id]
+	if !ok {
+		return nil
+	}
+	return copyFakeNetboxObject(object)
+}
+
+// List returns copies of the stored objects of an endpoint, ordered by ID.
+func (f *fakeNetbox) List(endpoint string) []map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	objects := []map[string]interface{}{}
+	for _, object := range f.sorted(endpoint) {
+		objects = append(objects, copyFakeNetboxObject(object))
+	}
+	return objects
+}
+
+// Delete removes an object without going through the API.
+func (f *fakeNetbox) Delete(endpoint string, id int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.objects[endpoint], id)
+}
+
+// Count returns the number of objects stored for an endpoint.
+func (f *fakeNetbox) Count(endpoint string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.objects[endpoint])
+}
+
+// Requests returns the method and path of every request the fake received.
+func (f *fakeNetbox) Requests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+func (f *fakeNetbox) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	var body interface{}
+	if r.Body != nil {
+		var buf bytes.Buffer
+		buf.ReadFrom(r.Body)
+		if buf.Len() > 0 {
+			body = decodeFakeNetboxJSON(buf.Bytes())
+		}
+	}
+
+	status, payload, err := f.route(r, body)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Request-ID", fmt.Sprintf("fake-request-%d", len(f.requests)))
+	if err != nil {
+		apiError, ok := err.(*fakeNetboxError)
+		if !ok {
+			apiError = &fakeNetboxError{status: http.StatusInternalServerError, body: map[string]string{"detail": err.Error()}}
+		}
+		w.WriteHeader(apiError.status)
+		json.NewEncoder(w).Encode(apiError.body)
+		return
+	}
+	w.WriteHeader(status)
+	if payload != nil {
+		json.NewEncoder(w).Encode(payload)
+	}
+}
+
+func (f *fakeNetbox) route(r *http.Request, body interface{}) (int, interface{}, error) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/"), "/")
+	if path == "status" {
+		return http.StatusOK, map[string]interface{}{"django-version": "3.2.12", "netbox-version": f.Version}, nil
+	}
+
+	if path == "users/tokens/provision" && r.Method == http.MethodPost {
+		return f.provisionToken(body)
+	}
+
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 {
+		return 0, nil, fakeNetboxNotFound
+	}
+	endpoint := parts[0] + "/" + parts[1]
+	if _, ok := fakeNetboxModels[endpoint]; !ok {
+		return 0, nil, fakeNetboxNotFound
+	}
+
+	if len(parts) == 2 {
+		switch r.Method {
+		case http.MethodGet:
+			objects, err := f.filter(endpoint, r.URL.Query())
+			if err != nil {
+				return 0, nil, err
+			}
+			if endpoint == "ipam/prefixes" {
+				objects = f.prefixHierarchy(objects)
+			}
+			return f.list(r, objects)
+		case http.MethodPost:
+			return f.createMany(endpoint, body)
+		}
+		return 0, nil, &fakeNetboxError{status: http.StatusMethodNotAllowed, body: map[string]string{"detail": "Method not allowed."}}
+	}
+
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return 0, nil, fakeNetboxNotFound
+	}
+	object, ok := f.objects[endpoint][id]
+	if !ok {
+		return 0, nil, fakeNetboxNotFound
+	}
+
+	if len(parts) == 4 {
+		return f.allocate(r, endpoint+"/"+parts[3], object, body)
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		return http.StatusOK, object, nil
+	case http.MethodPut, http.MethodPatch:
+		fields, ok := body.(map[string]interface{})
+		if !ok {
+			return 0, nil, fakeNetboxFieldError("non_field_errors", "Invalid data. Expected a dictionary.")
+		}
+		updated, err := f.update(endpoint, object, fields)
+		if err != nil {
+			return 0, nil, err
+		}
+		return http.StatusOK, updated, nil
+	case http.MethodDelete:
+		delete(f.objects[endpoint], id)
+		return http.StatusNoContent, nil, nil
+	}
+	return 0, nil, &fakeNetboxError{status: http.StatusMethodNotAllowed, body: map[string]string{"detail": "Method not allowed."}}
+}
+
+// provisionToken creates a token for the given credentials. Like Netbox 3.1
+// it ignores every other field, so provisioned tokens never expire.
+func (f *fakeNetbox) provisionToken(body interface{}) (int, interface{}, error) {
+	credentials, _ := body.(map[string]interface{})
+	if credentials["username"] == nil || credentials["password"] == nil {
+		return 0, nil, &fakeNetboxError{status: http.StatusForbidden, body: map[string]string{"detail": "Invalid username/password"}}
+	}
+	object, err := f.create("users/tokens", map[string]interface{}{})
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusCreated, object, nil
+}
+
+// createMany creates a single object or, like Netbox, a list of objects in
+// one request.
+func (f *fakeNetbox) createMany(endpoint string, body interface{}) (int, interface{}, error) {
+	if list, ok := body.([]interface{}); ok {
+		created := []interface{}{}
+		for _, item := range list {
+			fields, ok := item.(map[string]interface{})
+			if !ok {
+				return 0, nil, fakeNetboxFieldError("non_field_errors", "Invalid data. Expected a dictionary.")
+			}
+			object, err := f.create(endpoint, fields)
+			if err != nil {
+				return 0, nil, err
+			}
+			created = append(created, object)
+		}
+		return http.StatusCreated, created, nil
+	}
+
+	fields, ok := body.(map[string]interface{})
+	if !ok {
+		return 0, nil, fakeNetboxFieldError("non_field_errors", "Invalid data. Expected a dictionary.")
+	}
+	object, err := f.create(endpoint, fields)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusCreated, object, nil
+}
+
+func (f *fakeNetbox) create(endpoint string, fields map[string]interface{}) (map[string]interface{}, error) {
+	f.lastID++
+	object := map[string]interface{}{
+		"id":            f.lastID,
+		"url":           fmt.Sprintf("%s/api/%s/%d/", f.URL, endpoint, f.lastID),
+		"tags":          []interface{}{},
+		"custom_fields": map[string]interface{}{},
+	}
+	// Netbox returns every field of an object, unset ones as null
+	modelType := reflect.TypeOf(fakeNetboxModels[endpoint])
+	for i := 0; i < modelType.NumField(); i++ {
+		if name := fakeNetboxJSONName(modelType.Field(i)); object[name] == nil {
+			object[name] = nil
+		}
+	}
+	if endpoint == "users/tokens" {
+		object["key"] = fmt.Sprintf("%040d", f.lastID)
+	}
+	if err := f.apply(endpoint, object, fields); err != nil {
+		f.lastID--
+		return nil, err
+	}
+	f.objects[endpoint][f.lastID] = object
+	return object, nil
+}
+
+func (f *fakeNetbox) update(endpoint string, object map[string]interface{}, fields map[string]interface{}) (map[string]interface{}, error) {
+	updated := copyFakeNetboxObject(object)
+	if err := f.apply(endpoint, updated, fields); err != nil {
+		return nil, err
+	}
+	f.objects[endpoint][updated["id"].(int64)] = updated
+	return updated, nil
+}
+
+// apply sets the given writable fields on an object. References to other
+// objects are replaced by their nested representation and choice values by
+// a value and label pair.
+func (f *fakeNetbox) apply(endpoint string, object map[string]interface{}, fields map[string]interface{}) error {
+	modelType := reflect.TypeOf(fakeNetboxModels[endpoint])
+	for name, value := range fields {
+		if name == "id" {
+			continue
+		}
+		if name == "custom_fields" {
+			// Netbox keeps the values of custom fields that are not sent
+			merged := map[string]interface{}{}
+			if current, ok := object[name].(map[string]interface{}); ok {
+				for key, value := range current {
+					merged[key] = value
+				}
+			}
+			values, ok := value.(map[string]interface{})
+			if !ok {
+				return fakeNetboxFieldError(name, "Invalid data. Expected a dictionary, but got %T.", value)
+			}
+			for key, value := range values {
+				merged[key] = value
+			}
+			object[name] = merged
+			continue
+		}
+		field, ok := fakeNetboxField(modelType, name)
+		if !ok || value == nil {
+			object[name] = value
+			continue
+		}
+
+		fieldType := field.Type
+		switch {
+		case isFakeNetboxChoice(fieldType):
+			object[name] = map[string]interface{}{"value": value, "label": fakeNetboxLabel(value)}
+		case isFakeNetboxNested(fieldType):
+			nested, err := f.nested(fieldType.Elem(), name, value)
+			if err != nil {
+				return err
+			}
+			object[name] = nested
+		case fieldType.Kind() == reflect.Slice && isFakeNetboxNested(fieldType.Elem()):
+			values, ok := value.([]interface{})
+			if !ok {
+				return fakeNetboxFieldError(name, "Expected a list of items but got type %T.", value)
+			}
+			nestedList := []interface{}{}
+			for _, item := range values {
+				nested, err := f.nested(fieldType.Elem().Elem(), name, item)
+				if err != nil {
+					return err
+				}
+				nestedList = append(nestedList, nested)
+			}
+			object[name] = nestedList
+		default:
+			object[name] = value
+		}
+	}
+
+	for _, field := range fakeNetboxGenericRelations {
+		if _, ok := fakeNetboxField(modelType, field+"_type"); !ok {
+			continue
+		}
+		related, err := f.related(field, object[field+"_type"], object[field+"_id"])
+		if err != nil {
+			return err
+		}
+		object[field] = related
+	}
+
+	for _, field := range []string{"primary_ip4", "primary_ip6"} {
+		if _, ok := fakeNetboxField(modelType, "primary_ip"); ok && object[field] != nil {
+			object["primary_ip"] = object[field]
+		}
+	}
+	if family := fakeNetboxFamily(object); family != 0 {
+		if _, ok := fakeNetboxField(modelType, "family"); ok {
+			object["family"] = map[string]interface{}{"value": family, "label": fmt.Sprintf("IPv%d", family)}
+		}
+	}
+	object["display"] = fakeNetboxDisplay(object)
+	return nil
+}
+
+// nested returns the nested representation of the object referenced by a
+// writable field. Objects are referenced by ID, tags by name and slug.
+func (f *fakeNetbox) nested(nestedType reflect.Type, field string, value interface{}) (map[string]interface{}, error) {
+	endpoint, ok := f.endpoints[strings.TrimPrefix(nestedType.Name(), "Nested")]
+	if !ok {
+		return nil, fakeNetboxFieldError(field, "Related objects of type %s are not supported by the fake.", nestedType.Name())
+	}
+
+	var referenced map[string]interface{}
+	switch value := value.(type) {
+	case int64:
+		referenced = f.objects[endpoint][value]
+		if referenced == nil {
+			return nil, fakeNetboxFieldError(field, "Invalid pk \"%d\" - object does not exist.", value)
+		}
+	case map[string]interface{}:
+		for _, candidate := range f.sorted(endpoint) {
+			if matchesFakeNetboxAttributes(candidate, value) {
+				referenced = candidate
+				break
+			}
+		}
+		if referenced == nil {
+			return nil, fakeNetboxFieldError(field, "Related object not found using the provided attributes: %v", value)
+		}
+	default:
+		return nil, fakeNetboxFieldError(field, "Incorrect type. Expected pk value, received %T.", value)
+	}
+
+	nested := map[string]interface{}{}
+	for i := 0; i < nestedType.NumField(); i++ {
+		name := fakeNetboxJSONName(nestedType.Field(i))
+		value, ok := referenced[name]
+		if !ok {
+			continue
+		}
+		// Nested objects carry plain values where full objects carry
+		// choices, for example the family of an IP address.
+		if choice, ok := value.(map[string]interface{}); ok && !isFakeNetboxChoice(nestedType.Field(i).Type) {
+			if choiceValue, ok := choice["value"]; ok {
+				value = choiceValue
+			}
+		}
+		nested[name] = value
+	}
+	return nested, nil
+}
+
+// related returns the brief representation of the object a generic relation
+// refers to, or nil if the relation is not set.
+func (f *fakeNetbox) related(field string, contentType interface{}, id interface{}) (map[string]interface{}, error) {
+	if contentType == nil || id == nil {
+		return nil, nil
+	}
+	endpoint, ok := fakeNetboxContentTypes[fmt.Sprint(contentType)]
+	if !ok {
+		return nil, fakeNetboxFieldError(field+"_type", "Invalid content type: %v", contentType)
+	}
+	objectID, _ := id.(int64)
+	referenced := f.objects[endpoint][objectID]
+	if referenced == nil {
+		return nil, fakeNetboxFieldError(field+"_id", "Related object not found using the provided numeric ID: %v", id)
+	}
+
+	related := map[string]interface{}{}
+	for _, name := range []string{"id", "url", "display", "name", "slug"} {
+		if value, ok := referenced[name]; ok {
+			related[name] = value
+		}
+	}
+	return related, nil
+}
+
+// list returns one page of objects, paginated with limit and offset like
+// Netbox does.
+func (f *fakeNetbox) list(r *http.Request, objects []map[string]interface{}) (int, interface{}, error) {
+	query := r.URL.Query()
+	limit := 50
+	if value := query.Get("limit"); value != "" {
+		limit, _ = strconv.Atoi(value)
+		if limit <= 0 || limit > 1000 {
+			limit = 1000
+		}
+	}
+	offset, _ := strconv.Atoi(query.Get("offset"))
+
+	results := []interface{}{}
+	for i := offset; i < len(objects) && i < offset+limit; i++ {
+		results = append(results, objects[i])
+	}
+
+	page := func(offset int) interface{} {
+		if offset < 0 || offset >= len(objects) {
+			return nil
+		}
+		pageQuery := r.URL.Query()
+		pageQuery.Set("limit", strconv.Itoa(limit))
+		pageQuery.Set("offset", strconv.Itoa(offset))
+		return fmt.Sprintf("%s%s?%s", f.URL, r.URL.Path, pageQuery.Encode())
+	}
+	var previous interface{}
+	if offset > 0 {
+		previous = page(0)
+		if offset > limit {
+			previous = page(offset - limit)
+		}
+	}
+
+	return http.StatusOK, map[string]interface{}{
+		"count":    len(objects),
+		"next":     page(offset + limit),
+		"previous": previous,
+		"results":  results,
+	}, nil
+}
+
+// filter returns the objects of an endpoint matching the query, ordered by
+// ID. Different filters are ANDed, repeated filters are ORed. Filters on
+// fields that do not exist are ignored, like in Netbox.
+func (f *fakeNetbox) filter(endpoint string, query url.Values) ([]map[string]interface{}, error) {
+	objects := []map[string]interface{}{}
+	for _, object := range f.sorted(endpoint) {
+		matches := true
+		for key, values := range query {
+			switch key {
+			case "limit", "offset", "ordering", "brief":
+				continue
+			}
+			ok, err := matchesFakeNetboxFilter(object, key, values)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
+// prefixHierarchy returns copies of prefixes with the _depth and children
+// fields Netbox adds to listed prefixes: the number of prefixes in the same
+// VRF containing and contained in each prefix.
+func (f *fakeNetbox) prefixHierarchy(prefixes []map[string]interface{}) []map[string]interface{} {
+	all := f.sorted("ipam/prefixes")
+	vrf := func(object map[string]interface{}) interface{} {
+		return fakeNetboxNestedValues(object["vrf"], "id")[0]
+	}
+	result := []map[string]interface{}{}
+	for _, prefix := range prefixes {
+		parsed, err := netip.ParsePrefix(fmt.Sprint(prefix["prefix"]))
+		if err != nil {
+			result = append(result, prefix)
+			continue
+		}
+		depth, children := 0, 0
+		for _, other := range all {
+			otherParsed, err := netip.ParsePrefix(fmt.Sprint(other["prefix"]))
+			if err != nil || vrf(other) != vrf(prefix) || otherParsed.Masked() == parsed.Masked() {
+				continue
+			}
+			switch {
+			case otherParsed.Bits() < parsed.Bits() && otherParsed.Masked().Contains(parsed.Addr()):
+				depth++
+			case otherParsed.Bits() > parsed.Bits() && parsed.Masked().Contains(otherParsed.Addr()):
+				children++
+			}
+		}
+		prefix = copyFakeNetboxObject(prefix)
+		prefix["_depth"] = depth
+		prefix["children"] = children
+		result = append(result, prefix)
+	}
+	return result
+}
+
+func (f *fakeNetbox) sorted(endpoint string) []map[string]interface{} {
+	ids := []int64{}
+	for id := range f.objects[endpoint] {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	objects := []map[string]interface{}{}
+	for _, id := range ids {
+		objects = append(objects, f.objects[endpoint][id])
+	}
+	return objects
+}
+
+// allocate serves the endpoints listing and allocating the available IP
+// addresses, prefixes and VLANs of an object.
+func (f *fakeNetbox) allocate(r *http.Request, action string, parent map[string]interface{}, body interface{}) (int, interface{}, error) {
+	var available func(n int, request map[string]interface{}) ([]map[string]interface{}, error)
+	var endpoint string
+
+	switch action {
+	case "ipam/prefixes/available-ips", "ipam/ip-ranges/available-ips":
+		endpoint = "ipam/ip-addresses"
+		available = func(n int, request map[string]interface{}) ([]map[string]interface{}, error) {
+			return f.availableIPs(parent, n), nil
+		}
+	case "ipam/prefixes/available-prefixes":
+		endpoint = "ipam/prefixes"
+		available = func(n int, request map[string]interface{}) ([]map[string]interface{}, error) {
+			return f.availablePrefixes(parent, n, request)
+		}
+	case "ipam/vlan-groups/available-vlans":
+		endpoint = "ipam/vlans"
+		available = func(n int, request map[string]interface{}) ([]map[string]interface{}, error) {
+			return f.availableVLANs(parent, n), nil
+		}
+	default:
+		return 0, nil, fakeNetboxNotFound
+	}
+
+	if r.Method == http.MethodGet {
+		// Like Netbox, only the available IPs and VLANs are limited
+		limit := 50
+		if value := r.URL.Query().Get("limit"); value != "" {
+			limit, _ = strconv.Atoi(value)
+		}
+		if endpoint == "ipam/prefixes" {
+			limit = math.MaxInt
+		}
+		results, err := available(limit, nil)
+		if err != nil {
+			return 0, nil, err
+		}
+		for _, result := range results {
+			if family := fakeNetboxFamily(result); family != 0 {
+				result["family"] = family
+			}
+			if parent["vrf"] != nil {
+				result["vrf"] = parent["vrf"]
+			}
+		}
+		return http.StatusOK, results, nil
+	}
+	if r.Method != http.MethodPost {
+		return 0, nil, &fakeNetboxError{status: http.StatusMethodNotAllowed, body: map[string]string{"detail": "Method not allowed."}}
+	}
+
+	requests, isList := body.([]interface{})
+	if !isList {
+		requests = []interface{}{body}
+	}
+	created := []interface{}{}
+	for _, item := range requests {
+		request, _ := item.(map[string]interface{})
+		if request == nil {
+			request = map[string]interface{}{}
+		}
+		candidates, err := available(1, request)
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(candidates) == 0 {
+			return http.StatusNoContent, nil, nil
+		}
+		fields := copyFakeNetboxObject(request)
+		for key, value := range candidates[0] {
+			fields[key] = value
+		}
+		if vrf, ok := parent["vrf"].(map[string]interface{}); ok && fields["vrf"] == nil {
+			fields["vrf"] = vrf["id"]
+		}
+		object, err := f.create(endpoint, fields)
+		if err != nil {
+			return 0, nil, err
+		}
+		created = append(created, object)
+	}
+	if !isList {
+		return http.StatusCreated, created[0], nil
+	}
+	return http.StatusCreated, created, nil
+}
+
+// availableIPs returns up to n addresses of a prefix or an IP range that are
+// not used by an IP address. Like in Netbox, addresses in IP ranges are not
+// available in prefixes, and the network and broadcast addresses of regular
+// IPv4 prefixes are never available.
+func (f *fakeNetbox) availableIPs(parent map[string]interface{}, n int) []map[string]interface{} {
+	var first, last netip.Addr
+	var bits int
+	var ranges [][2]netip.Addr
+
+	if prefixString, ok := parent["prefix"].(string); ok {
+		prefix, err := netip.ParsePrefix(prefixString)
+		if err != nil {
+			return nil
+		}
+		prefix = prefix.Masked()
+		first, last, bits = prefix.Addr(), lastFakeNetboxAddr(prefix), prefix.Bits()
+		isPool, _ := parent["is_pool"].(bool)
+		if first.Is4() && !isPool && bits < 31 {
+			first, last = first.Next(), last.Prev()
+		}
+		for _, ipRange := range f.objects["ipam/ip-ranges"] {
+			start, end, _ := fakeNetboxRange(ipRange)
+			if start.IsValid() && prefix.Contains(start) {
+				ranges = append(ranges, [2]netip.Addr{start, end})
+			}
+		}
+	} else {
+		var ok bool
+		first, last, ok = fakeNetboxRange(parent)
+		if !ok {
+			return nil
+		}
+		bits = netip.MustParsePrefix(parent["start_address"].(string)).Bits()
+	}
+
+	used := map[netip.Addr]bool{}
+	for _, ipAddress := range f.objects["ipam/ip-addresses"] {
+		address, err := netip.ParsePrefix(fmt.Sprint(ipAddress["address"]))
+		if err == nil {
+			used[address.Addr()] = true
+		}
+	}
+
+	available := []map[string]interface{}{}
+	for address := first; address.IsValid() && address.Compare(last) <= 0 && len(available) < n; address = address.Next() {
+		if used[address] || inFakeNetboxRanges(address, ranges) {
+			continue
+		}
+		available = append(available, map[string]interface{}{
+			"address": netip.PrefixFrom(address, bits).String(),
+		})
+	}
+	return available
+}
+
+// availablePrefixes returns up to n unused blocks of a prefix. When a prefix
+// length is requested, the first block that fits a prefix of that length is
+// narrowed down to it.
+func (f *fakeNetbox) availablePrefixes(parent map[string]interface{}, n int, request map[string]interface{}) ([]map[string]interface{}, error) {
+	prefix, err := netip.ParsePrefix(fmt.Sprint(parent["prefix"]))
+	if err != nil {
+		return nil, err
+	}
+	prefix = prefix.Masked()
+
+	children := []netip.Prefix{}
+	for _, candidate := range f.objects["ipam/prefixes"] {
+		child, err := netip.ParsePrefix(fmt.Sprint(candidate["prefix"]))
+		if err == nil && child.Bits() > prefix.Bits() && prefix.Contains(child.Addr()) {
+			children = append(children, child.Masked())
+		}
+	}
+	blocks := freeFakeNetboxBlocks(prefix, children)
+
+	var prefixLength int64 = -1
+	if request != nil {
+		value, ok := request["prefix_length"].(int64)
+		if !ok {
+			return nil, fakeNetboxFieldError("prefix_length", "This field is required.")
+		}
+		prefixLength = value
+	}
+
+	available := []map[string]interface{}{}
+	for _, block := range blocks {
+		if len(available) >= n {
+			break
+		}
+		if prefixLength >= 0 {
+			if int64(block.Bits()) > prefixLength {
+				continue
+			}
+			block = netip.PrefixFrom(block.Addr(), int(prefixLength))
+		}
+		available = append(available, map[string]interface{}{
+			"prefix": block.String(),
+		})
+	}
+	return available, nil
+}
+
+// availableVLANs returns up to n VIDs of a VLAN group that are not used by a
+// VLAN of the group.
+func (f *fakeNetbox) availableVLANs(group map[string]interface{}, n int) []map[string]interface{} {
+	minVID, maxVID := int64(1), int64(4094)
+	if value, ok := group["min_vid"].(int64); ok {
+		minVID = value
+	}
+	if value, ok := group["max_vid"].(int64); ok {
+		maxVID = value
+	}
+
+	used := map[int64]bool{}
+	for _, vlan := range f.objects["ipam/vlans"] {
+		if nested, ok := vlan["group"].(map[string]interface{}); ok && nested["id"] == group["id"] {
+			used[vlan["vid"].(int64)] = true
+		}
+	}
+
+	available := []map[string]interface{}{}
+	for vid := minVID; vid <= maxVID && len(available) < n; vid++ {
+		if !used[vid] {
+			available = append(available, map[string]interface{}{
+				"vid":   vid,
+				"group": group["id"],
+			})
+		}
+	}
+	return available
+}
+
+// matchesFakeNetboxFilter reports whether an object matches any of the
+// values of a filter. Filters can be suffixed with a lookup expression like
+// __n or __ic, and cf_ filters match custom fields.
+func matchesFakeNetboxFilter(object map[string]interface{}, key string, values []string) (bool, error) {
+	field, lookup := key, ""
+	if i := strings.Index(key, "__"); i >= 0 {
+		field, lookup = key[:i], key[i+2:]
+	}
+	negated := false
+	switch lookup {
+	case "n", "nic", "nie", "nisw", "niew":
+		negated = true
+		lookup = strings.TrimPrefix(lookup, "n")
+	}
+
+	candidates, ok := fakeNetboxFilterCandidates(object, field)
+	if !ok {
+		return true, nil
+	}
+
+	matches := false
+	for _, value := range values {
+		for _, candidate := range candidates {
+			ok, err := matchesFakeNetboxLookup(field, candidate, lookup, value)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				matches = true
+			}
+		}
+	}
+	return matches != negated, nil
+}
+
+// fakeNetboxFilterCandidates returns the values of an object a filter is
+// compared against, and whether the filter applies to the object at all.
+func fakeNetboxFilterCandidates(object map[string]interface{}, field string) ([]interface{}, bool) {
+	switch {
+	case field == "q":
+		return []interface{}{object["display"], object["description"]}, true
+	case field == "tag":
+		candidates := []interface{}{}
+		for _, tag := range object["tags"].([]interface{}) {
+			candidates = append(candidates, tag.(map[string]interface{})["slug"])
+		}
+		return candidates, true
+	case strings.HasPrefix(field, "cf_"):
+		customFields, _ := object["custom_fields"].(map[string]interface{})
+		return []interface{}{customFields[strings.TrimPrefix(field, "cf_")]}, true
+	case field == "within" || field == "within_include" || field == "contains" || field == "parent" || field == "mask_length":
+		for _, name := range []string{"prefix", "address"} {
+			if value, ok := object[name]; ok {
+				return []interface{}{value}, true
+			}
+		}
+		return nil, false
+	}
+
+	value, ok := object[field]
+	if !ok && strings.HasSuffix(field, "_id") {
+		if nested, isNested := object[strings.TrimSuffix(field, "_id")]; isNested {
+			return fakeNetboxNestedValues(nested, "id"), true
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if choice, ok := value["value"]; ok {
+			return []interface{}{choice}, true
+		}
+		return fakeNetboxNestedValues(value, "slug", "name"), true
+	case []interface{}:
+		candidates := []interface{}{}
+		for _, item := range value {
+			if nested, ok := item.(map[string]interface{}); ok {
+				candidates = append(candidates, fakeNetboxNestedValues(nested, "id")...)
+			} else {
+				candidates = append(candidates, item)
+			}
+		}
+		return candidates, true
+	}
+	return []interface{}{value}, true
+}
+
+func fakeNetboxNestedValues(value interface{}, fields ...string) []interface{} {
+	nested, ok := value.(map[string]interface{})
+	if !ok {
+		return []interface{}{nil}
+	}
+	for _, field := range fields {
+		if fieldValue, ok := nested[field]; ok {
+			return []interface{}{fieldValue}
+		}
+	}
+	return []interface{}{nil}
+}
+
+func matchesFakeNetboxLookup(field string, candidate interface{}, lookup string, value string) (bool, error) {
+	candidateString := fmt.Sprint(candidate)
+	if candidate == nil {
+		candidateString = "null"
+	}
+
+	switch field {
+	case "within", "within_include", "contains", "parent":
+		prefix, err := netip.ParsePrefix(candidateString)
+		if err != nil {
+			return false, nil
+		}
+		filter, err := netip.ParsePrefix(value)
+		if err != nil {
+			return false, fakeNetboxFieldError(field, "Enter a valid CIDR.")
+		}
+		prefix, filter = prefix.Masked(), filter.Masked()
+		switch field {
+		case "within":
+			return filter.Contains(prefix.Addr()) && prefix.Bits() > filter.Bits(), nil
+		case "contains":
+			return prefix.Contains(filter.Addr()) && prefix.Bits() <= filter.Bits(), nil
+		}
+		return filter.Contains(prefix.Addr()) && prefix.Bits() >= filter.Bits(), nil
+	case "mask_length":
+		prefix, err := netip.ParsePrefix(candidateString)
+		if err != nil {
+			return false, nil
+		}
+		candidateString = strconv.Itoa(prefix.Bits())
+	case "q":
+		lookup = "ic"
+	}
+
+	switch lookup {
+	case "":
+		return candidateString == value, nil
+	case "ic":
+		return strings.Contains(strings.ToLower(candidateString), strings.ToLower(value)), nil
+	case "ie":
+		return strings.EqualFold(candidateString, value), nil
+	case "isw":
+		return strings.HasPrefix(strings.ToLower(candidateString), strings.ToLower(value)), nil
+	case "iew":
+		return strings.HasSuffix(strings.ToLower(candidateString), strings.ToLower(value)), nil
+	case "gt", "gte", "lt", "lte":
+		a, err := strconv.ParseFloat(candidateString, 64)
+		if err != nil {
+			return false, nil
+		}
+		b, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false, fakeNetboxFieldError(field, "Enter a number.")
+		}
+		switch lookup {
+		case "gt":
+			return a > b, nil
+		case "gte":
+			return a >= b, nil
+		case "lt":
+			return a < b, nil
+		}
+		return a <= b, nil
+	}
+	return false, fakeNetboxFieldError(field, "Unsupported lookup %q.", lookup)
+}
+
+func matchesFakeNetboxAttributes(object map[string]interface{}, attributes map[string]interface{}) bool {
+	for key, value := range attributes {
+		if fmt.Sprint(object[key]) != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// freeFakeNetboxBlocks returns the largest CIDR blocks of a prefix that do
+// not overlap any of its children.
+func freeFakeNetboxBlocks(prefix netip.Prefix, children []netip.Prefix) []netip.Prefix {
+	overlapping := false
+	for _, child := range children {
+		if child.Contains(prefix.Addr()) && child.Bits() <= prefix.Bits() {
+			return nil
+		}
+		if prefix.Overlaps(child) {
+			overlapping = true
+		}
+	}
+	if !overlapping {
+		return []netip.Prefix{prefix}
+	}
+
+	lower := netip.PrefixFrom(prefix.Addr(), prefix.Bits()+1)
+	upper := netip.PrefixFrom(lastFakeNetboxAddr(lower).Next(), prefix.Bits()+1)
+	return append(freeFakeNetboxBlocks(lower, children), freeFakeNetboxBlocks(upper, children)...)
+}
+
+func lastFakeNetboxAddr(prefix netip.Prefix) netip.Addr {
+	address := prefix.Masked().Addr().AsSlice()
+	for bit := prefix.Bits(); bit < len(address)*8; bit++ {
+		address[bit/8] |= 1 << (7 - bit%8)
+	}
+	last, _ := netip.AddrFromSlice(address)
+	return last
+}
+
+func fakeNetboxRange(ipRange map[string]interface{}) (netip.Addr, netip.Addr, bool) {
+	start, err := netip.ParsePrefix(fmt.Sprint(ipRange["start_address"]))
+	if err != nil {
+		return netip.Addr{}, netip.Addr{}, false
+	}
+	end, err := netip.ParsePrefix(fmt.Sprint(ipRange["end_address"]))
+	if err != nil {
+		return netip.Addr{}, netip.Addr{}, false
+	}
+	return start.Addr(), end.Addr(), true
+}
+
+func inFakeNetboxRanges(address netip.Addr, ranges [][2]netip.Addr) bool {
+	for _, r := range ranges {
+		if address.Compare(r[0]) >= 0 && address.Compare(r[1]) <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func fakeNetboxFamily(object map[string]interface{}) int {
+	for _, field := range []string{"prefix", "address", "start_address"} {
+		if value, ok := object[field].(string); ok {
+			if prefix, err := netip.ParsePrefix(value); err == nil {
+				if prefix.Addr().Is4() {
+					return 4
+				}
+				return 6
+			}
+		}
+	}
+	return 0
+}
+
+func fakeNetboxDisplay(object map[string]interface{}) string {
+	for _, field := range fakeNetboxDisplayFields {
+		if value, ok := object[field]; ok && value != nil {
+			return fmt.Sprint(value)
+		}
+	}
+	return fmt.Sprint(object["id"])
+}
+
+func fakeNetboxLabel(value interface{}) string {
+	label := strings.ReplaceAll(fmt.Sprint(value), "-", " ")
+	if label == "" {
+		return label
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+func fakeNetboxField(modelType reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < modelType.NumField(); i++ {
+		if fakeNetboxJSONName(modelType.Field(i)) == name {
+			return modelType.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func fakeNetboxJSONName(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("json"), ",")[0]
+}
+
+// isFakeNetboxChoice reports whether a model field holds a choice, which
+// go-netbox models as a struct with a value and a label.
+func isFakeNetboxChoice(fieldType reflect.Type) bool {
+	if fieldType.Kind() != reflect.Ptr || fieldType.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	_, hasValue := fieldType.Elem().FieldByName("Value")
+	_, hasLabel := fieldType.Elem().FieldByName("Label")
+	return hasValue && hasLabel
+}
+
+func isFakeNetboxNested(fieldType reflect.Type) bool {
+	return fieldType.Kind() == reflect.Ptr && fieldType.Elem().Kind() == reflect.Struct && strings.HasPrefix(fieldType.Elem().Name(), "Nested")
+}
+
+// decodeFakeNetboxJSON decodes JSON with integers as int64, so IDs can be
+// compared directly.
+func decodeFakeNetboxJSON(raw []byte) interface{} {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil
+	}
+	return normalizeFakeNetboxJSON(value)
+}
+
+func normalizeFakeNetboxJSON(value interface{}) interface{} {
+	switch value := value.(type) {
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		f, _ := value.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = normalizeFakeNetboxJSON(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalizeFakeNetboxJSON(item)
+		}
+	}
+	return value
+}
+
+func copyFakeNetboxObject(object map[string]interface{}) map[string]interface{} {
+	raw, _ := json.Marshal(object)
+	return decodeFakeNetboxJSON(raw).(map[string]interface{})
+}
+
+// testUnitProviderFactories creates a new provider for every Terraform run,
+// so unit tests can run in parallel against different fakes.
+var testUnitProviderFactories = map[string]func() (*schema.Provider, error){
+	"netbox": func() (*schema.Provider, error) {
+		return Provider(), nil
+	},
+}
+
+// testUnitPreCheck skips tests that run Terraform against the fake when the
+// Terraform CLI is not available, instead of letting the SDK download it.
+func testUnitPreCheck(t *testing.T) {
+	if os.Getenv("TF_ACC_TERRAFORM_PATH") != "" {
+		return
+	}
+	if _, err := exec.LookPath("terraform"); err != nil {
+		t.Skip("Terraform CLI not found in PATH and TF_ACC_TERRAFORM_PATH not set")
+	}
+}
+
+// testUnitApply plans and applies a resource configuration the way
+// Terraform does, which runs the CRUD functions of the resource without the
+// Terraform CLI. A nil configuration destroys the resource.
+func testUnitApply(t *testing.T, r *schema.Resource, state *terraform.InstanceState, config map[string]interface{}, meta interface{}) (*terraform.InstanceState, diag.Diagnostics) {
+	ctx := context.Background()
+	diff := &terraform.InstanceDiff{Destroy: true}
+	if config != nil {
+		var err error
+		diff, err = r.Diff(ctx, state, terraform.NewResourceConfigRaw(config), meta)
+		if err != nil {
+			return state, diag.FromErr(err)
+		}
+	}
+	return r.Apply(ctx, state, diff, meta)
+}
+
+// testUnitRefresh reads a resource the way Terraform does before planning.
+func testUnitRefresh(t *testing.T, r *schema.Resource, state *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, diag.Diagnostics) {
+	return r.RefreshWithoutUpgrade(context.Background(), state, meta)
+}
+
+func TestFakeNetbox_pagination(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	for i := 0; i < 5; i++ {
+		fake.Seed(t, "tenancy/tenants", map[string]interface{}{"name": fmt.Sprintf("tenant-%d", i), "slug": fmt.Sprintf("tenant-%d", i)})
+	}
+
+	limit := int64(2)
+	params := tenancy.NewTenancyTenantsListParams().WithLimit(&limit)
+	names := []string{}
+	for offset := int64(0); ; offset += limit {
+		params.SetOffset(&offset)
+		res, err := api.Tenancy.TenancyTenantsList(params, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), *res.GetPayload().Count)
+		for _, tenant := range res.GetPayload().Results {
+			names = append(names, *tenant.Name)
+		}
+		if res.GetPayload().Next == nil {
+			break
+		}
+	}
+	assert.Equal(t, []string{"tenant-0", "tenant-1", "tenant-2", "tenant-3", "tenant-4"}, names)
+}
+
+func TestFakeNetbox_filters(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	fake.Seed(t, "extras/tags", map[string]interface{}{"name": "gold", "slug": "gold"})
+	groupID := fake.Seed(t, "tenancy/tenant-groups", map[string]interface{}{"name": "group", "slug": "group"})
+	fake.Seed(t, "tenancy/tenants", map[string]interface{}{"name": "Alpha", "slug": "alpha", "group": groupID, "tags": []interface{}{map[string]interface{}{"name": "gold"}}})
+	fake.Seed(t, "tenancy/tenants", map[string]interface{}{"name": "Beta", "slug": "beta", "custom_fields": map[string]interface{}{"owner": "ops"}})
+	fake.Seed(t, "tenancy/tenants", map[string]interface{}{"name": "Gamma", "slug": "gamma"})
+
+	for _, tt := range []struct {
+		query    string
+		expected []string
+	}{
+		{"name=Alpha", []string{"Alpha"}},
+		{"name=Alpha&name=Gamma", []string{"Alpha", "Gamma"}},
+		{"name__n=Alpha&name__n=Gamma", []string{"Beta"}},
+		{"name__ic=ET", []string{"Beta"}},
+		{"slug__isw=g", []string{"Gamma"}},
+		{"group_id=" + strconv.FormatInt(groupID, 10), []string{"Alpha"}},
+		{"group=group", []string{"Alpha"}},
+		{"tag=gold", []string{"Alpha"}},
+		{"cf_owner=ops", []string{"Beta"}},
+		{"q=mm", []string{"Gamma"}},
+		{"id__gte=4", []string{"Beta", "Gamma"}},
+		{"unknown=ignored", []string{"Alpha", "Beta", "Gamma"}},
+	} {
+		res, err := http.Get(fake.URL + "/api/tenancy/tenants/?" + tt.query)
+		assert.NoError(t, err)
+		var page struct {
+			Results []models.Tenant `json:"results"`
+		}
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(&page))
+		res.Body.Close()
+		names := []string{}
+		for _, tenant := range page.Results {
+			names = append(names, *tenant.Name)
+		}
+		assert.Equal(t, tt.expected, names, tt.query)
+	}
+
+	// Nested objects are decoded by the generated client
+	res, err := api.Tenancy.TenancyTenantsList(tenancy.NewTenancyTenantsListParams().WithTag(strToPtr("gold")), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "group", *res.GetPayload().Results[0].Group.Slug)
+	assert.Equal(t, "gold", *res.GetPayload().Results[0].Tags[0].Slug)
+}
+
+func TestFakeNetbox_invalidReference(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+
+	data := &models.WritableTenant{Name: strToPtr("tenant"), Slug: strToPtr("tenant"), Group: int64ToPtr(42), Tags: []*models.NestedTag{}}
+	_, err := api.Tenancy.TenancyTenantsCreate(tenancy.NewTenancyTenantsCreateParams().WithData(data), nil)
+	assert.Error(t, err)
+	assert.Equal(t, 0, fake.Count("tenancy/tenants"))
+}
+
+func TestFakeNetbox_availableIPs(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	prefixID := fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.0.0/29", "status": "active"})
+	fake.Seed(t, "ipam/ip-addresses", map[string]interface{}{"address": "10.0.0.1/29", "status": "active"})
+	fake.Seed(t, "ipam/ip-ranges", map[string]interface{}{"start_address": "10.0.0.3/29", "end_address": "10.0.0.4/29", "status": "active"})
+	ipv6PrefixID := fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "2001:db8::/64", "status": "active"})
+
+	res, err := api.Ipam.IpamPrefixesAvailableIpsList(ipam.NewIpamPrefixesAvailableIpsListParams().WithID(prefixID), nil)
+	assert.NoError(t, err)
+	addresses := []string{}
+	for _, ip := range res.GetPayload() {
+		addresses = append(addresses, ip.Address)
+	}
+	assert.Equal(t, []string{"10.0.0.2/29", "10.0.0.5/29", "10.0.0.6/29"}, addresses)
+
+	res, err = api.Ipam.IpamPrefixesAvailableIpsList(ipam.NewIpamPrefixesAvailableIpsListParams().WithID(ipv6PrefixID), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "2001:db8::/64", res.GetPayload()[0].Address)
+}
+
+func TestFakeNetbox_availablePrefixes(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	prefixID := fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.0.0/24", "status": "container"})
+	fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.0.0/26", "status": "active"})
+
+	res, err := api.Ipam.IpamPrefixesAvailablePrefixesList(ipam.NewIpamPrefixesAvailablePrefixesListParams().WithID(prefixID), nil)
+	assert.NoError(t, err)
+	prefixes := []string{}
+	for _, prefix := range res.GetPayload() {
+		prefixes = append(prefixes, prefix.Prefix)
+	}
+	assert.Equal(t, []string{"10.0.0.64/26", "10.0.0.128/25"}, prefixes)
+}
@@ -0,0 +1,86 @@
+package netbox
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// reservedQueryParameters are query parameters that are set by the provider
+// itself and therefore cannot be used as filters.
+var reservedQueryParameters = []string{"limit", "offset", "brief", "format"}
+
+// filterSchema returns the schema of the filter block shared by the plural
+// data sources.
+func filterSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Description: "A filter that is passed to Netbox as query parameter. Filters with different names must all match, filters with the same name are combined with OR.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:     schema.TypeString,
+					Required: true,
+					ValidateFunc: validation.All(
+						validation.StringMatch(regexp.MustCompile(`^[A-Za-z0-9_]+$`), "must be a Netbox query parameter"),
+						validation.StringNotInSlice(reservedQueryParameters, false),
+					),
+					Description: "The name of the query parameter, including lookup expressions like `name__ic` or `vid__gte`. Use `cf_<name>` to filter by custom fields.",
+				},
+				"value": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+			},
+		},
+	}
+}
+
+// getQueryFilters returns the query parameters for the filter blocks of a
+// plural data source. Aliases map filter names that data sources accepted
+// before any query parameter could be used to their query parameter.
+func getQueryFilters(d *schema.ResourceData, aliases map[string]string) url.Values {
+	filters := url.Values{}
+	filterSet, ok := d.GetOk("filter")
+	if !ok {
+		return filters
+	}
+	for _, f := range filterSet.(*schema.Set).List() {
+		name := f.(map[string]interface{})["name"].(string)
+		value := f.(map[string]interface{})["value"].(string)
+		if alias, ok := aliases[name]; ok {
+			name = alias
+		}
+		filters.Add(name, value)
+	}
+	for _, values := range filters {
+		sort.Strings(values)
+	}
+	return filters
+}
+
+// withQueryFilters adds query parameters to an operation. It is used to pass
+// filters to Netbox that the generated client does not know about.
+func withQueryFilters(filters url.Values) func(*runtime.ClientOperation) {
+	return func(op *runtime.ClientOperation) {
+		params := op.Params
+		op.Params = runtime.ClientRequestWriterFunc(func(r runtime.ClientRequest, reg strfmt.Registry) error {
+			if err := params.WriteToRequest(r, reg); err != nil {
+				return err
+			}
+			for name, values := range filters {
+				if err := r.SetQueryParam(name, values...); err != nil {
+					return fmt.Errorf("error setting filter %s: %w", name, err)
+				}
+			}
+			return nil
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package netbox
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/netbox-community/go-netbox/netbox/client/tenancy"
+	"github.com/stretchr/testify/assert"
+)
+
+func testFilterResourceData(t *testing.T, filters ...[2]string) *schema.ResourceData {
+	filterList := []interface{}{}
+	for _, f := range filters {
+		filterList = append(filterList, map[string]interface{}{"name": f[0], "value": f[1]})
+	}
+	return schema.TestResourceDataRaw(t, map[string]*schema.Schema{"filter": filterSchema()}, map[string]interface{}{
+		"filter": filterList,
+	})
+}
+
+func TestGetQueryFilters(t *testing.T) {
+	d := testFilterResourceData(t,
+		[2]string{"name", "b"},
+		[2]string{"name", "a"},
+		[2]string{"vm_id", "1"},
+		[2]string{"cf_owner", "ops"},
+		[2]string{"vid__gte", "100"},
+	)
+
+	filters := getQueryFilters(d, map[string]string{"vm_id": "virtual_machine_id"})
+	assert.Equal(t, url.Values{
+		"name":               {"a", "b"},
+		"virtual_machine_id": {"1"},
+		"cf_owner":           {"ops"},
+		"vid__gte":           {"100"},
+	}, filters)
+}
+
+func TestGetQueryFilters_none(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{"filter": filterSchema()}, map[string]interface{}{})
+	assert.Empty(t, getQueryFilters(d, nil))
+}
+
+func TestFilterSchema_validation(t *testing.T) {
+	validate := filterSchema().Elem.(*schema.Resource).Schema["name"].ValidateFunc
+	for name, valid := range map[string]bool{
+		"name":      true,
+		"name__ic":  true,
+		"cf_owner":  true,
+		"tag":       true,
+		"limit":     false,
+		"offset":    false,
+		"name&a=b":  false,
+		"site name": false,
+	} {
+		_, errs := validate(name, "name")
+		assert.Equal(t, valid, len(errs) == 0, name)
+	}
+}
+
+func TestWithQueryFilters(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		fake.Seed(t, "tenancy/tenants", map[string]interface{}{"name": name, "slug": name})
+	}
+
+	params := tenancy.NewTenancyTenantsListParams().WithSlug(strToPtr("alpha"))
+	res, err := api.Tenancy.TenancyTenantsList(params, nil, withQueryFilters(url.Values{"name__ic": {"ALP", "GAM"}}))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *res.GetPayload().Count)
+	assert.Equal(t, "alpha", *res.GetPayload().Results[0].Name)
+
+	params = tenancy.NewTenancyTenantsListParams()
+	res, err = api.Tenancy.TenancyTenantsList(params, nil, withQueryFilters(url.Values{"name__ic": {"ALP", "GAM"}}))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), *res.GetPayload().Count)
+}
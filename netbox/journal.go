@@ -0,0 +1,100 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/netbox-community/go-netbox/netbox/client/extras"
+	"github.com/netbox-community/go-netbox/netbox/models"
+)
+
+// journalObjectTypes are the content types of the objects of resources that
+// support journal entries. Netbox 3.1 only keeps journals for primary
+// models, not for organizational models like roles and groups.
+var journalObjectTypes = map[string]string{
+	"netbox_aggregate":            "ipam.aggregate",
+	"netbox_available_ip_address": "ipam.ipaddress",
+	"netbox_available_prefix":     "ipam.prefix",
+	"netbox_available_vlan":       "ipam.vlan",
+	"netbox_circuit":              "circuits.circuit",
+	"netbox_circuit_provider":     "circuits.provider",
+	"netbox_cluster":              "virtualization.cluster",
+	"netbox_device":               "dcim.device",
+	"netbox_device_type":          "dcim.devicetype",
+	"netbox_interface":            "virtualization.vminterface",
+	"netbox_ip_address":           "ipam.ipaddress",
+	"netbox_ip_range":             "ipam.iprange",
+	"netbox_prefix":               "ipam.prefix",
+	"netbox_route_target":         "ipam.routetarget",
+	"netbox_service":              "ipam.service",
+	"netbox_site":                 "dcim.site",
+	"netbox_tenant":               "tenancy.tenant",
+	"netbox_virtual_machine":      "virtualization.virtualmachine",
+	"netbox_vlan":                 "ipam.vlan",
+	"netbox_vrf":                  "ipam.vrf",
+}
+
+// withRunJournal makes the Create and Update functions of a resource add a
+// journal entry with the changelog comment and the run ID to the object
+// they changed, if the provider has a changelog comment. Unlike the
+// X-Request-ID header, Netbox keeps journal entries with the object.
+func withRunJournal(name string, r *schema.Resource) *schema.Resource {
+	objectType, ok := journalObjectTypes[name]
+	if !ok {
+		return r
+	}
+
+	journal := func(f crudContextFunc) crudContextFunc {
+		if f == nil {
+			return nil
+		}
+		return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			diags := f(ctx, d, m)
+			state, ok := m.(*providerState)
+			if !ok || state.changelogComment == "" || diags.HasError() || d.Id() == "" {
+				return diags
+			}
+			id, err := strconv.ParseInt(d.Id(), 10, 64)
+			if err != nil {
+				return diags
+			}
+			if err := addRunJournalEntry(ctx, state, objectType, id); err != nil {
+				// The change itself succeeded, so it is kept in the state
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Warning,
+					Summary:  fmt.Sprintf("Unable to add journal entry to %s %d", objectType, id),
+					Detail:   fmt.Sprintf("The object was changed, but recording changelog_comment in its journal failed: %s", err),
+				})
+			}
+			return diags
+		}
+	}
+
+	r.CreateContext = journal(r.CreateContext)
+	r.UpdateContext = journal(r.UpdateContext)
+	return r
+}
+
+// addRunJournalEntry adds a journal entry with the changelog comment and the
+// run ID to an object.
+func addRunJournalEntry(ctx context.Context, state *providerState, objectType string, id int64) error {
+	comments := fmt.Sprintf("%s (run %s)", state.changelogComment, state.runID)
+	data := &models.WritableJournalEntry{
+		AssignedObjectType: &objectType,
+		AssignedObjectID:   &id,
+		Comments:           &comments,
+		Kind:               "info",
+		Tags:               []*models.NestedTag{},
+	}
+	params := extras.NewExtrasJournalEntriesCreateParams().WithContext(ctx).WithData(data)
+
+	// Only the ID is needed, which also avoids decoding the assigned object
+	var payload struct {
+		ID int64 `json:"id"`
+	}
+	_, err := state.Extras.ExtrasJournalEntriesCreate(params, nil, withResponseBody(&payload, extras.NewExtrasJournalEntriesCreateCreated()))
+	return err
+}
@@ -0,0 +1,74 @@
+package netbox
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitRunJournal(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	api.runID = "ci-job-1234"
+	resources := Provider().ResourcesMap
+
+	// Without a changelog comment no journal entries are added
+	config := map[string]interface{}{"name": "untracked", "slug": "untracked"}
+	_, diags := testUnitApply(t, resources["netbox_tenant"], nil, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, 0, fake.Count("extras/journal-entries"))
+
+	api.changelogComment = "Applied by CI"
+	config = map[string]interface{}{"name": "tenant", "slug": "tenant"}
+	state, diags := testUnitApply(t, resources["netbox_tenant"], nil, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	config["name"] = "renamed"
+	state, diags = testUnitApply(t, resources["netbox_tenant"], state, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+
+	// Deleting the object removes its journal in Netbox, so nothing is added
+	_, diags = testUnitApply(t, resources["netbox_tenant"], state, nil, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+
+	// Organizational objects have no journal
+	_, diags = testUnitApply(t, resources["netbox_tenant_group"], nil, map[string]interface{}{"name": "group", "slug": "group"}, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+
+	entries := fake.List("extras/journal-entries")
+	if assert.Len(t, entries, 2) {
+		for _, entry := range entries {
+			assert.Equal(t, "tenancy.tenant", entry["assigned_object_type"])
+			assert.Equal(t, state.ID, strconv.FormatInt(entry["assigned_object_id"].(int64), 10))
+			assert.Equal(t, "Applied by CI (run ci-job-1234)", entry["comments"])
+		}
+	}
+}
+
+func TestUnitRunJournalFailure(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	api.runID = "ci-job-1234"
+	api.changelogComment = "Applied by CI"
+
+	// A journal entry for an object type the fake does not know fails, which
+	// must not fail the change itself
+	journalObjectTypes["netbox_tenant"] = "tenancy.unknown"
+	t.Cleanup(func() { journalObjectTypes["netbox_tenant"] = "tenancy.tenant" })
+	r := withRunJournal("netbox_tenant", resourceNetboxTenant())
+
+	state, diags := testUnitApply(t, r, nil, map[string]interface{}{"name": "tenant", "slug": "tenant"}, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.NotEmpty(t, state.ID)
+	if assert.Len(t, diags, 1) {
+		assert.Contains(t, diags[0].Summary, "Unable to add journal entry to tenancy.unknown")
+	}
+	assert.Equal(t, 1, fake.Count("tenancy/tenants"))
+}
+
+func TestJournalObjectTypes(t *testing.T) {
+	resources := Provider().ResourcesMap
+	for name := range journalObjectTypes {
+		assert.Contains(t, resources, name)
+	}
+}
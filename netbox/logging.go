@@ -0,0 +1,255 @@
+package netbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// httpLogSubsystem is the logging subsystem of requests to Netbox. Its level
+// can be set independently of the provider via TF_LOG_PROVIDER_NETBOX_HTTP.
+const httpLogSubsystem = "http"
+
+// maskedLogValue replaces sensitive values in logs.
+const maskedLogValue = "***"
+
+// maxLoggedBodySize is the number of bytes of a request or response body that
+// is logged at most.
+const maxLoggedBodySize = 64 << 10
+
+// sensitiveLogHeaders are headers whose values are masked in logs.
+var sensitiveLogHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// sensitiveLogKeys are keys of JSON bodies whose values are masked in logs.
+// Netbox returns tokens as key and webhook secrets as secret.
+var sensitiveLogKeys = []string{"api_token", "key", "netbox_token", "password", "secret", "token"}
+
+// loggingTransport is a transport that logs every request to Netbox and its
+// outcome at DEBUG level and their headers and bodies at TRACE level.
+// Credentials are masked, including any occurrence of the given secrets.
+type loggingTransport struct {
+	original http.RoundTripper
+	secrets  []string
+}
+
+// valuesContext is a context that carries the values of its parent, such as
+// its loggers, but neither its deadline nor its cancellation.
+type valuesContext struct {
+	context.Context
+}
+
+func (valuesContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (valuesContext) Done() <-chan struct{}       { return nil }
+func (valuesContext) Err() error                  { return nil }
+
+// RoundTrip logs the request, sends it and logs the response.
+func (t loggingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx := tflog.NewSubsystem(r.Context(), httpLogSubsystem,
+		tflog.WithLevelFromEnv("TF_LOG_PROVIDER_NETBOX", httpLogSubsystem),
+		tflog.WithRootFields(),
+	)
+	ctx = tflog.SubsystemWith(ctx, httpLogSubsystem, "http_method", r.Method)
+	ctx = tflog.SubsystemWith(ctx, httpLogSubsystem, "http_url", loggedText{text: r.URL.Redacted(), transport: t})
+	// The request ID is the run ID unless headers set another one
+	if requestID := r.Header.Get("X-Request-ID"); requestID != "" {
+		ctx = tflog.SubsystemWith(ctx, httpLogSubsystem, "http_request_id", requestID)
+	}
+
+	// Bodies are only read into memory when the entry is written at TRACE
+	// level, before the request is sent
+	tflog.SubsystemTrace(ctx, httpLogSubsystem, "Sending request to Netbox", map[string]interface{}{
+		"http_request_headers": loggedHeaders{headers: r.Header, transport: t},
+		"http_request_body":    loggedBody{body: &capturedBody{target: &r.Body}, transport: t},
+	})
+
+	start := time.Now()
+	resp, err := t.original.RoundTrip(r)
+	duration := time.Since(start)
+	if err != nil {
+		tflog.SubsystemDebug(ctx, httpLogSubsystem, "Request to Netbox failed", map[string]interface{}{
+			"http_duration_ms": duration.Milliseconds(),
+			"error":            loggedText{text: err.Error(), transport: t},
+		})
+		return nil, err
+	}
+
+	fields := map[string]interface{}{
+		"http_status":      resp.StatusCode,
+		"http_duration_ms": duration.Milliseconds(),
+	}
+	// Netbox ignores the X-Request-ID header of requests and records its own
+	// request ID in the change log. Logging it next to http_request_id ties
+	// change log entries to the run that made them.
+	if requestID := resp.Header.Get("X-Request-ID"); requestID != "" {
+		fields["netbox_request_id"] = requestID
+	}
+	tflog.SubsystemDebug(ctx, httpLogSubsystem, "Received response from Netbox", fields)
+	tflog.SubsystemTrace(ctx, httpLogSubsystem, "Response from Netbox", map[string]interface{}{
+		"http_status":           resp.StatusCode,
+		"http_response_headers": loggedHeaders{headers: resp.Header, transport: t},
+		"http_response_body":    loggedBody{body: &capturedBody{target: &resp.Body}, transport: t},
+	})
+	return resp, nil
+}
+
+// mask replaces every occurrence of a secret of the transport in value.
+func (t loggingTransport) mask(value string) string {
+	for _, secret := range t.secrets {
+		if secret != "" {
+			value = strings.ReplaceAll(value, secret, maskedLogValue)
+		}
+	}
+	return value
+}
+
+// maskHeaders returns a copy of headers with the values of sensitive headers
+// masked.
+func (t loggingTransport) maskHeaders(headers http.Header) map[string]string {
+	masked := make(map[string]string, len(headers))
+	for name, values := range headers {
+		value := t.mask(strings.Join(values, ", "))
+		for _, sensitive := range sensitiveLogHeaders {
+			if strings.EqualFold(name, sensitive) {
+				value = maskedLogValue
+			}
+		}
+		masked[name] = value
+	}
+	return masked
+}
+
+// loggedText is a text in logs, like a URL or an error, that is only masked
+// if the log entry is actually written.
+type loggedText struct {
+	text      string
+	transport loggingTransport
+}
+
+func (v loggedText) String() string {
+	return v.transport.mask(v.text)
+}
+
+// MarshalJSON formats the text as JSON string for JSON logs.
+func (v loggedText) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.String())
+}
+
+// loggedHeaders are request or response headers in logs. They are only
+// masked if the log entry is actually written.
+type loggedHeaders struct {
+	headers   http.Header
+	transport loggingTransport
+}
+
+func (h loggedHeaders) String() string {
+	formatted, _ := h.MarshalJSON()
+	return string(formatted)
+}
+
+// MarshalJSON formats the headers as JSON object for JSON logs.
+func (h loggedHeaders) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.transport.maskHeaders(h.headers))
+}
+
+// capturedBody is a request or response body that is read into memory the
+// first time it is logged. The original body is replaced by the buffered
+// copy, so it can still be sent or decoded afterwards.
+type capturedBody struct {
+	target *io.ReadCloser
+	once   sync.Once
+	data   []byte
+}
+
+// bytes returns the body. If reading it fails, the error is returned to
+// whoever reads the replaced body.
+func (c *capturedBody) bytes() []byte {
+	c.once.Do(func() {
+		body := *c.target
+		if body == nil || body == http.NoBody {
+			return
+		}
+		data, err := io.ReadAll(body)
+		body.Close()
+		c.data = data
+		if err != nil {
+			*c.target = io.NopCloser(io.MultiReader(bytes.NewReader(data), errorReader{err: err}))
+			return
+		}
+		*c.target = io.NopCloser(bytes.NewReader(data))
+	})
+	return c.data
+}
+
+// errorReader is a reader that always fails with err.
+type errorReader struct {
+	err error
+}
+
+func (r errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// loggedBody is a request or response body in logs. It is only read,
+// masked and formatted if the log entry is actually written.
+type loggedBody struct {
+	body      *capturedBody
+	transport loggingTransport
+}
+
+// String returns the body with the values of sensitive keys masked if it is
+// JSON, truncated to maxLoggedBodySize.
+func (b loggedBody) String() string {
+	body := b.body.bytes()
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err == nil {
+		if masked, err := json.Marshal(maskLogValue(value)); err == nil {
+			body = masked
+		}
+	}
+
+	logged := b.transport.mask(string(body))
+	if len(logged) > maxLoggedBodySize {
+		logged = logged[:maxLoggedBodySize] + "... (truncated)"
+	}
+	return logged
+}
+
+// MarshalJSON formats the body as JSON string for JSON logs.
+func (b loggedBody) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.String())
+}
+
+// maskLogValue masks the values of sensitive keys in a decoded JSON value.
+func maskLogValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if isSensitiveLogKey(key) && item != nil {
+				v[key] = maskedLogValue
+			} else {
+				v[key] = maskLogValue(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = maskLogValue(item)
+		}
+	}
+	return value
+}
+
+func isSensitiveLogKey(key string) bool {
+	for _, sensitive := range sensitiveLogKeys {
+		if strings.EqualFold(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,234 @@
+package netbox
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	netboxClient "github.com/netbox-community/go-netbox/netbox/client"
+	"github.com/netbox-community/go-netbox/netbox/client/status"
+	"github.com/netbox-community/go-netbox/netbox/client/tenancy"
+	"github.com/netbox-community/go-netbox/netbox/client/users"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const testLogToken = "0123456789abcdef0123456789abcdef01234567"
+
+// testLogServer echoes request bodies and leaks the token in every way a
+// response can.
+func testLogServer(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "sessionid="+testLogToken)
+		w.Header().Set("X-Request-ID", "netbox-request")
+		switch {
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			w.Write(body)
+		case strings.HasPrefix(r.URL.Path, "/api/users/tokens/"):
+			w.Write([]byte(`{"count": 1, "results": [{"id": 1, "key": "` + testLogToken + `", "description": "token ` + testLogToken + `"}]}`))
+		default:
+			w.Write([]byte(`{"netbox-version": "3.1.9", "netbox_token": "` + testLogToken + `"}`))
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func testLogClient(t *testing.T, ctx context.Context, ts *httptest.Server) *netboxClient.NetBoxAPI {
+	config := Config{
+		APIToken:  testLogToken,
+		ServerURL: ts.URL,
+		Headers:   map[string]interface{}{"X-Netbox-Token": testLogToken},
+		RunID:     "ci-job-1234",
+	}
+	client, err := config.Client(ctx)
+	assert.NoError(t, err)
+	return client.(*netboxClient.NetBoxAPI)
+}
+
+func TestLoggingTransport(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_NETBOX_HTTP", "TRACE")
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	api := testLogClient(t, ctx, testLogServer(t))
+
+	_, err := getNetboxStatus(ctx, api)
+	assert.NoError(t, err)
+
+	name := "tenant"
+	_, err = api.Tenancy.TenancyTenantsCreate(tenancy.NewTenancyTenantsCreateParamsWithContext(ctx).WithData(&models.WritableTenant{
+		Name:        &name,
+		Slug:        &name,
+		Description: "tenant with " + testLogToken,
+	}), nil)
+	assert.NoError(t, err)
+
+	key := testLogToken
+	_, err = api.Users.UsersTokensList(users.NewUsersTokensListParamsWithContext(ctx).WithKey(&key), nil)
+	assert.NoError(t, err)
+
+	entries, err := tflogtest.MultilineJSONDecode(bytes.NewReader(output.Bytes()))
+	assert.NoError(t, err)
+
+	var requests, responses int
+	for _, entry := range entries {
+		switch entry["@message"] {
+		case "Sending request to Netbox":
+			requests++
+			assert.Equal(t, "TRACE", strings.ToUpper(entry["@level"].(string)))
+			assert.Contains(t, entry, "http_request_headers")
+		case "Received response from Netbox":
+			responses++
+			assert.Equal(t, "DEBUG", strings.ToUpper(entry["@level"].(string)))
+			assert.Contains(t, entry, "http_method")
+			assert.Contains(t, entry, "http_url")
+			assert.Contains(t, entry, "http_status")
+			assert.Contains(t, entry, "http_duration_ms")
+			assert.Equal(t, "ci-job-1234", entry["http_request_id"])
+			assert.Equal(t, "netbox-request", entry["netbox_request_id"])
+		}
+	}
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, 3, responses)
+
+	assert.Contains(t, output.String(), `\"name\":\"tenant\"`)
+	assert.Contains(t, output.String(), maskedLogValue)
+	assert.NotContains(t, output.String(), testLogToken)
+}
+
+func TestLoggingTransportDebug(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_NETBOX_HTTP", "DEBUG")
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	api := testLogClient(t, ctx, testLogServer(t))
+
+	_, err := getNetboxStatus(ctx, api)
+	assert.NoError(t, err)
+
+	assert.Contains(t, output.String(), "Received response from Netbox")
+	assert.NotContains(t, output.String(), "Sending request to Netbox")
+	assert.NotContains(t, output.String(), "netbox-version")
+}
+
+func TestLoggingTransportWithoutOperationContext(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_NETBOX_HTTP", "TRACE")
+
+	var output bytes.Buffer
+	ctx, cancel := context.WithCancel(tflogtest.RootLogger(context.Background(), &output))
+	api := testLogClient(t, ctx, testLogServer(t))
+	// Operations without a context must not depend on the lifetime of the
+	// context the client was created with
+	cancel()
+
+	_, err := api.Status.StatusList(status.NewStatusListParams(), nil)
+	assert.NoError(t, err)
+
+	assert.Contains(t, output.String(), "Received response from Netbox")
+	assert.NotContains(t, output.String(), testLogToken)
+}
+
+func TestLoggingTransportReadsBodiesOnlyAtTrace(t *testing.T) {
+
+	for _, level := range []string{"DEBUG", "TRACE"} {
+		t.Run(level, func(t *testing.T) {
+			t.Setenv("TF_LOG_PROVIDER_NETBOX_HTTP", level)
+
+			var output bytes.Buffer
+			ctx := tflogtest.RootLogger(context.Background(), &output)
+
+			requestBody := io.NopCloser(strings.NewReader(`{"name": "foo"}`))
+			responseBody := io.NopCloser(strings.NewReader(`{"id": 1}`))
+			transport := loggingTransport{original: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				assert.Equal(t, level == "DEBUG", r.Body == requestBody)
+				body, _ := io.ReadAll(r.Body)
+				assert.Equal(t, `{"name": "foo"}`, string(body))
+				return &http.Response{StatusCode: http.StatusCreated, Header: http.Header{}, Body: responseBody}, nil
+			})}
+
+			req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "https://netbox.example.com/api/tenancy/tenants/", requestBody)
+			resp, err := transport.RoundTrip(req)
+			assert.NoError(t, err)
+			assert.Equal(t, level == "DEBUG", resp.Body == responseBody)
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, `{"id": 1}`, string(body))
+			assert.Equal(t, level == "TRACE", strings.Contains(output.String(), `{\"id\":1}`))
+		})
+	}
+}
+
+func testCapturedBody(body string) *capturedBody {
+	target := io.NopCloser(strings.NewReader(body))
+	return &capturedBody{target: &target}
+}
+
+func TestLoggedBody(t *testing.T) {
+	transport := loggingTransport{secrets: []string{"s3cr3t"}}
+
+	testCases := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "Empty",
+			body:     "",
+			expected: "",
+		},
+		{
+			name:     "SensitiveKeys",
+			body:     `{"key": "abc", "Password": "abc", "netbox_token": "abc", "name": "abc"}`,
+			expected: `{"Password":"***","key":"***","name":"abc","netbox_token":"***"}`,
+		},
+		{
+			name:     "Nested",
+			body:     `{"results": [{"id": 1, "token": {"id": 2}}, {"id": 3, "secret": null}]}`,
+			expected: `{"results":[{"id":1,"token":"***"},{"id":3,"secret":null}]}`,
+		},
+		{
+			name:     "SecretInValue",
+			body:     `{"description": "the secret is s3cr3t"}`,
+			expected: `{"description":"the secret is ***"}`,
+		},
+		{
+			name:     "NotJSON",
+			body:     `token=s3cr3t`,
+			expected: `token=***`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, loggedBody{body: testCapturedBody(tt.body), transport: transport}.String())
+		})
+	}
+
+	long := loggedBody{body: testCapturedBody(strings.Repeat("a", maxLoggedBodySize+1)), transport: transport}.String()
+	assert.True(t, strings.HasSuffix(long, "... (truncated)"))
+}
+
+func TestMaskHeaders(t *testing.T) {
+	transport := loggingTransport{secrets: []string{"s3cr3t"}}
+	headers := http.Header{
+		"Authorization": {"Token s3cr3t"},
+		"Cookie":        {"csrftoken=abc"},
+		"X-Custom":      {"Bearer s3cr3t"},
+		"Accept":        {"application/json"},
+	}
+
+	assert.Equal(t, map[string]string{
+		"Authorization": maskedLogValue,
+		"Cookie":        maskedLogValue,
+		"X-Custom":      "Bearer " + maskedLogValue,
+		"Accept":        "application/json",
+	}, transport.maskHeaders(headers))
+}
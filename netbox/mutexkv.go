@@ -0,0 +1,45 @@
+package netbox
+
+import (
+	"fmt"
+	"sync"
+)
+
+// mutexKV is a keyed mutex. Callers holding the lock for a key block other
+// callers trying to lock the same key, while different keys don't interfere.
+// The zero value is ready to use.
+type mutexKV struct {
+	lock  sync.Mutex
+	store map[string]*sync.Mutex
+}
+
+// Lock locks the mutex for the given key, creating it if necessary.
+func (m *mutexKV) Lock(key string) {
+	m.get(key).Lock()
+}
+
+// Unlock unlocks the mutex for the given key.
+func (m *mutexKV) Unlock(key string) {
+	m.get(key).Unlock()
+}
+
+func (m *mutexKV) get(key string) *sync.Mutex {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.store == nil {
+		m.store = make(map[string]*sync.Mutex)
+	}
+	mutex, ok := m.store[key]
+	if !ok {
+		mutex = &sync.Mutex{}
+		m.store[key] = mutex
+	}
+	return mutex
+}
+
+// allocationLockKey returns the key under which allocations from the given
+// parent object are serialized.
+func allocationLockKey(parentType string, parentID int64) string {
+	return fmt.Sprintf("%s/%d", parentType, parentID)
+}
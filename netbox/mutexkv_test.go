@@ -0,0 +1,51 @@
+package netbox
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutexKVLocksPerKey(t *testing.T) {
+
+	var m mutexKV
+	m.Lock("prefix/1")
+
+	// A different key is not blocked
+	done := make(chan struct{})
+	go func() {
+		m.Lock("prefix/2")
+		m.Unlock("prefix/2")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("locking a different key blocked")
+	}
+
+	// The same key is blocked until it is unlocked
+	locked := make(chan struct{})
+	go func() {
+		m.Lock("prefix/1")
+		close(locked)
+		m.Unlock("prefix/1")
+	}()
+	select {
+	case <-locked:
+		t.Fatal("locking the same key did not block")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	m.Unlock("prefix/1")
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("unlocking the key did not release the waiting caller")
+	}
+}
+
+func TestAllocationLockKey(t *testing.T) {
+	assert.Equal(t, "ip-range/42", allocationLockKey("ip-range", 42))
+}
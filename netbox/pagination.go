@@ -0,0 +1,97 @@
+package netbox
+
+import (
+	"sync"
+)
+
+// listPageSize is the number of objects requested per page. Netbox caps it
+// at its MAX_PAGE_SIZE setting, which defaults to 1000.
+var listPageSize int64 = 1000
+
+// listWorkers is the number of pages that are fetched concurrently.
+const listWorkers = 4
+
+// listPageFunc fetches the objects of a list endpoint starting at offset. It
+// returns the total number of objects matching the request along with the
+// objects of the page.
+type listPageFunc[T any] func(limit int64, offset int64) (int64, []T, error)
+
+// listAll fetches the objects of a list endpoint across all pages, or at
+// most limit objects if limit is greater than zero. The first page reveals
+// the number of objects and the page size Netbox actually uses; the remaining
+// pages are then fetched concurrently by a bounded number of workers.
+func listAll[T any](limit int64, fetch listPageFunc[T]) ([]T, error) {
+	pageSize := listPageSize
+	if limit > 0 && limit < pageSize {
+		pageSize = limit
+	}
+
+	count, results, err := fetch(pageSize, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := count
+	if limit > 0 && limit < wanted {
+		wanted = limit
+	}
+	if int64(len(results)) >= wanted || len(results) == 0 {
+		return truncateList(results, wanted), nil
+	}
+	// Netbox may return fewer objects than requested per page
+	pageSize = int64(len(results))
+
+	offsets := []int64{}
+	for offset := pageSize; offset < wanted; offset += pageSize {
+		offsets = append(offsets, offset)
+	}
+	pages := make([][]T, len(offsets))
+	errs := make([]error, len(offsets))
+
+	var wg sync.WaitGroup
+	var failed sync.Once
+	done := make(chan struct{})
+	work := make(chan int)
+	for i := 0; i < listWorkers && i < len(offsets); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range work {
+				pageLimit := pageSize
+				if remaining := wanted - offsets[page]; remaining < pageLimit {
+					pageLimit = remaining
+				}
+				_, pages[page], errs[page] = fetch(pageLimit, offsets[page])
+				if errs[page] != nil {
+					failed.Do(func() { close(done) })
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for page := range offsets {
+		select {
+		case work <- page:
+		case <-done:
+			break dispatch
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	for page := range offsets {
+		if errs[page] != nil {
+			return nil, errs[page]
+		}
+		results = append(results, pages[page]...)
+	}
+	return truncateList(results, wanted), nil
+}
+
+func truncateList[T any](results []T, n int64) []T {
+	if int64(len(results)) > n {
+		return results[:n]
+	}
+	return results
+}
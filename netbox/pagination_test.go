@@ -0,0 +1,129 @@
+package netbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testPaginatedServer serves count numbered objects in pages of at most
+// maxPageSize objects, like a Netbox list endpoint.
+func testPaginatedServer(t *testing.T, count int, maxPageSize int, inFlight *int32, maxInFlight *int32) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(inFlight, 1)
+		defer atomic.AddInt32(inFlight, -1)
+		for {
+			max := atomic.LoadInt32(maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if limit > maxPageSize {
+			limit = maxPageSize
+		}
+		results := []int{}
+		for i := offset; i < count && i < offset+limit; i++ {
+			results = append(results, i)
+		}
+		var next interface{}
+		if offset+limit < count {
+			next = fmt.Sprintf("http://%s%s?limit=%d&offset=%d", r.Host, r.URL.Path, limit, offset+limit)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"count":   count,
+			"next":    next,
+			"results": results,
+		})
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func testPaginatedFetch(ts *httptest.Server, requests *int32) listPageFunc[int] {
+	return func(limit int64, offset int64) (int64, []int, error) {
+		atomic.AddInt32(requests, 1)
+		res, err := http.Get(fmt.Sprintf("%s/api/objects/?limit=%d&offset=%d", ts.URL, limit, offset))
+		if err != nil {
+			return 0, nil, err
+		}
+		defer res.Body.Close()
+		var page struct {
+			Count   int64 `json:"count"`
+			Results []int `json:"results"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&page)
+		return page.Count, page.Results, err
+	}
+}
+
+func TestListAll(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		count       int
+		maxPageSize int
+		limit       int64
+		expected    int
+		requests    int32
+	}{
+		{"single page", 30, 1000, 0, 30, 1},
+		{"empty", 0, 1000, 0, 0, 1},
+		{"many pages", 2345, 100, 0, 2345, 24},
+		{"limit within first page", 2345, 100, 10, 10, 1},
+		{"limit across pages", 2345, 100, 250, 250, 3},
+		{"limit above count", 150, 100, 1000, 150, 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var inFlight, maxInFlight, requests int32
+			ts := testPaginatedServer(t, tt.count, tt.maxPageSize, &inFlight, &maxInFlight)
+
+			results, err := listAll(tt.limit, testPaginatedFetch(ts, &requests))
+			assert.NoError(t, err)
+			assert.Len(t, results, tt.expected)
+			for i, result := range results {
+				if !assert.Equal(t, i, result) {
+					break
+				}
+			}
+			assert.Equal(t, tt.requests, requests)
+			assert.LessOrEqual(t, maxInFlight, int32(listWorkers))
+		})
+	}
+}
+
+func TestListAll_concurrent(t *testing.T) {
+	var inFlight, maxInFlight, requests int32
+	ts := testPaginatedServer(t, 5000, 100, &inFlight, &maxInFlight)
+
+	_, err := listAll(0, testPaginatedFetch(ts, &requests))
+	assert.NoError(t, err)
+	assert.Greater(t, maxInFlight, int32(1))
+	assert.LessOrEqual(t, maxInFlight, int32(listWorkers))
+}
+
+func TestListAll_error(t *testing.T) {
+	var requests int32
+	fetchErr := errors.New("page failed")
+
+	_, err := listAll(0, func(limit int64, offset int64) (int64, []int, error) {
+		atomic.AddInt32(&requests, 1)
+		if offset == 300 {
+			return 0, nil, fetchErr
+		}
+		return 10000, make([]int, 100), nil
+	})
+	assert.ErrorIs(t, err, fetchErr)
+	assert.Less(t, atomic.LoadInt32(&requests), int32(100))
+}
@@ -2,8 +2,17 @@ package netbox
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/netbox-community/go-netbox/netbox/client"
 )
 
 // Provider returns a schema.Provider for Netbox.
@@ -20,12 +29,15 @@ func Provider() *schema.Provider {
 			"netbox_tenant":               resourceNetboxTenant(),
 			"netbox_tenant_group":         resourceNetboxTenantGroup(),
 			"netbox_vrf":                  resourceNetboxVrf(),
+			"netbox_route_target":         resourceNetboxRouteTarget(),
 			"netbox_ip_address":           resourceNetboxIPAddress(),
 			"netbox_interface":            resourceNetboxInterface(),
 			"netbox_service":              resourceNetboxService(),
 			"netbox_platform":             resourceNetboxPlatform(),
 			"netbox_prefix":               resourceNetboxPrefix(),
 			"netbox_available_prefix":     resourceNetboxAvailablePrefix(),
+			"netbox_vlan_group":           resourceNetboxVlanGroup(),
+			"netbox_available_vlan":       resourceNetboxAvailableVlan(),
 			"netbox_primary_ip":           resourceNetboxPrimaryIP(),
 			"netbox_device_role":          resourceNetboxDeviceRole(),
 			"netbox_tag":                  resourceNetboxTag(),
@@ -46,24 +58,29 @@ func Provider() *schema.Provider {
 			"netbox_custom_field":         resourceCustomField(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"netbox_cluster":          dataSourceNetboxCluster(),
-			"netbox_cluster_group":    dataSourceNetboxClusterGroup(),
-			"netbox_cluster_type":     dataSourceNetboxClusterType(),
-			"netbox_tenant":           dataSourceNetboxTenant(),
-			"netbox_tenants":          dataSourceNetboxTenants(),
-			"netbox_tenant_group":     dataSourceNetboxTenantGroup(),
-			"netbox_vrf":              dataSourceNetboxVrf(),
-			"netbox_platform":         dataSourceNetboxPlatform(),
-			"netbox_prefix":           dataSourceNetboxPrefix(),
-			"netbox_device":           dataSourceNetboxDevice(),
-			"netbox_device_role":      dataSourceNetboxDeviceRole(),
-			"netbox_site":             dataSourceNetboxSite(),
-			"netbox_tag":              dataSourceNetboxTag(),
-			"netbox_virtual_machines": dataSourceNetboxVirtualMachine(),
-			"netbox_interfaces":       dataSourceNetboxInterfaces(),
-			"netbox_ip_addresses":     dataSourceNetboxIpAddresses(),
-			"netbox_ip_range":         dataSourceNetboxIpRange(),
-			"netbox_region":           dataSourceNetboxRegion(),
+			"netbox_cluster":            dataSourceNetboxCluster(),
+			"netbox_cluster_group":      dataSourceNetboxClusterGroup(),
+			"netbox_cluster_type":       dataSourceNetboxClusterType(),
+			"netbox_tenant":             dataSourceNetboxTenant(),
+			"netbox_tenants":            dataSourceNetboxTenants(),
+			"netbox_tenant_group":       dataSourceNetboxTenantGroup(),
+			"netbox_vrf":                dataSourceNetboxVrf(),
+			"netbox_route_target":       dataSourceNetboxRouteTarget(),
+			"netbox_platform":           dataSourceNetboxPlatform(),
+			"netbox_prefix":             dataSourceNetboxPrefix(),
+			"netbox_prefixes":           dataSourceNetboxPrefixes(),
+			"netbox_available_prefixes": dataSourceNetboxAvailablePrefixes(),
+			"netbox_available_ips":      dataSourceNetboxAvailableIPs(),
+			"netbox_device":             dataSourceNetboxDevice(),
+			"netbox_device_role":        dataSourceNetboxDeviceRole(),
+			"netbox_site":               dataSourceNetboxSite(),
+			"netbox_tag":                dataSourceNetboxTag(),
+			"netbox_run":                dataSourceNetboxRun(),
+			"netbox_virtual_machines":   dataSourceNetboxVirtualMachine(),
+			"netbox_interfaces":         dataSourceNetboxInterfaces(),
+			"netbox_ip_addresses":       dataSourceNetboxIpAddresses(),
+			"netbox_ip_range":           dataSourceNetboxIpRange(),
+			"netbox_region":             dataSourceNetboxRegion(),
 		},
 		Schema: map[string]*schema.Schema{
 			"server_url": {
@@ -74,9 +91,34 @@ func Provider() *schema.Provider {
 			},
 			"api_token": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("NETBOX_API_TOKEN", nil),
-				Description: "Netbox API authentication token",
+				Description: "Netbox API authentication token. Exactly one of `api_token`, `api_token_file`, `api_token_command` or `username` and `password` must be set.",
+			},
+			"api_token_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("NETBOX_API_TOKEN_FILE", nil),
+				Description: "Path to a file containing the Netbox API authentication token.",
+			},
+			"api_token_command": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("NETBOX_API_TOKEN_COMMAND", nil),
+				Description: "Command that prints the Netbox API authentication token. It is run with `sh -c`, or `cmd /C` on Windows, when the provider is configured.",
+			},
+			"username": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("NETBOX_USERNAME", nil),
+				Description: "Username to provision a short-lived Netbox API token with when the provider is configured. The provider tries to delete the token when it exits. This is best effort, a token that is not deleted stays valid for 12 hours, or indefinitely if the user is not allowed to change its tokens.",
+			},
+			"password": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("NETBOX_PASSWORD", nil),
+				Description: "Password of `username`.",
 			},
 			"allow_insecure_https": {
 				Type:        schema.TypeBool,
@@ -84,6 +126,42 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("NETBOX_ALLOW_INSECURE_HTTPS", false),
 				Description: "Flag to set whether to allow https with invalid certificates",
 			},
+			"ca_cert_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("NETBOX_CA_CERT_FILE", nil),
+				ConflictsWith: []string{"ca_cert_pem"},
+				Description:   "Path to a file with PEM-encoded CA certificates that are trusted in addition to the system certificates when connecting to Netbox.",
+			},
+			"ca_cert_pem": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("NETBOX_CA_CERT_PEM", nil),
+				ConflictsWith: []string{"ca_cert_file"},
+				Description:   "PEM-encoded CA certificates that are trusted in addition to the system certificates when connecting to Netbox.",
+			},
+			"client_cert": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("NETBOX_CLIENT_CERT", nil),
+				RequiredWith: []string{"client_key"},
+				Description:  "PEM-encoded client certificate, or path to a file containing it, to authenticate to Netbox with mutual TLS.",
+			},
+			"client_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				DefaultFunc:  schema.EnvDefaultFunc("NETBOX_CLIENT_KEY", nil),
+				RequiredWith: []string{"client_cert"},
+				Description:  "PEM-encoded private key of the client certificate, or path to a file containing it.",
+			},
+			"proxy_url": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("NETBOX_PROXY_URL", nil),
+				ValidateFunc: validation.IsURLWithScheme([]string{"http", "https", "socks5"}),
+				Description:  "URL of a proxy to connect to Netbox through. Defaults to the proxy configured in the `HTTPS_PROXY`, `HTTP_PROXY` and `NO_PROXY` environment variables.",
+			},
 			"headers": {
 				Type:        schema.TypeMap,
 				Optional:    true,
@@ -96,27 +174,276 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("NETBOX_SKIP_VERSION_CHECK", false),
 				Description: "If true, do not try to determine the running Netbox version at provider startup. Disables warnings about possibly unsupported Netbox version. Also useful for local testing on terraform plans.",
 			},
+			"read_only": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("NETBOX_READ_ONLY", false),
+				Description: "If true, the provider refuses to create, update or delete objects and only sends GET requests to Netbox. Use it to plan with a token that cannot write.",
+			},
+			"run_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("NETBOX_RUN_ID", nil),
+				Description: "Identifier of the Terraform run, like the ID of a CI job. It is sent as `X-Request-ID` header with every request to Netbox, unless `headers` sets that header, which then takes precedence. Netbox does not store the header, set `changelog_comment` to record the run ID with the objects the provider changes. Defaults to a random UUID generated when the provider is configured.",
+			},
+			"changelog_comment": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("NETBOX_CHANGELOG_COMMENT", nil),
+				Description: "Comment to record, followed by the `run_id`, in a journal entry of every object the provider creates or updates. The change log of Netbox has no comment field, but Netbox keeps journal entries with their object. Only objects that support journals get an entry, which excludes organizational objects like roles, groups and tags.",
+			},
+			"default_tags": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"tags": {
+							Type: schema.TypeSet,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Optional:    true,
+							Set:         schema.HashString,
+							Description: "Names of the tags to add to every resource.",
+						},
+					},
+				},
+				Description: "Configuration block with tags that are added to every resource that supports tags. Default tags are not shown in the `tags` attribute of a resource, but in its `tags_all` attribute.",
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("NETBOX_MAX_RETRIES", 3),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of times a request is retried after a connection error, a rate limit (429) or a server error (5xx) response. Requests with non-idempotent methods are only retried after a rate limit. Set to 0 to disable retries.",
+			},
+			"retry_wait_min": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("NETBOX_RETRY_WAIT_MIN", 1),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Minimum time in seconds to wait before retrying a request. The wait time grows exponentially with every attempt. Must not be greater than `retry_wait_max`.",
+			},
+			"retry_wait_max": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("NETBOX_RETRY_WAIT_MAX", 30),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum time in seconds to wait before retrying a request. Also caps the wait time requested by Netbox via the `Retry-After` header.",
+			},
+			"request_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("NETBOX_REQUEST_TIMEOUT", 60),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Time in seconds after which a single request to Netbox is aborted. Aborted requests are retried like failed requests. Set to 0 to disable the timeout.",
+			},
+			"wait_for_ready": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("NETBOX_WAIT_FOR_READY", false),
+				Description: "If true, wait at provider startup until Netbox answers requests to its status endpoint, for example while it starts next to Terraform. Connection errors, rate limits and server errors are retried until `startup_timeout` expires.",
+			},
+			"startup_timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("NETBOX_STARTUP_TIMEOUT", 300),
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Time in seconds to wait for Netbox to become ready if `wait_for_ready` is set.",
+			},
+			"max_requests_per_second": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("NETBOX_MAX_REQUESTS_PER_SECOND", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of requests per second sent to Netbox, including retries. Set to 0 to disable the limit.",
+			},
+			"max_concurrent_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("NETBOX_MAX_CONCURRENT_REQUESTS", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of requests sent to Netbox at the same time, regardless of the parallelism of Terraform. Set to 0 to disable the limit.",
+			},
+			"max_idle_conns_per_host": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("NETBOX_MAX_IDLE_CONNS_PER_HOST", 10),
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Maximum number of idle connections to Netbox that are kept open for reuse. Should be at least the parallelism of Terraform.",
+			},
+			"keepalive": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("NETBOX_KEEPALIVE", 30),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Interval in seconds between TCP keep-alive probes on connections to Netbox. Set to 0 to disable keep-alive probes. Idle connections are reused either way, up to `max_idle_conns_per_host`.",
+			},
 		},
 		ConfigureContextFunc: providerConfigure,
 	}
+
+	for name, r := range provider.ResourcesMap {
+		withReadOnlyGuard(name, withRunJournal(name, withErrorDiagnostics(r)))
+	}
+	for _, r := range provider.DataSourcesMap {
+		withErrorDiagnostics(r)
+	}
 	return provider
 }
 
+// providerState is passed as meta to all resources and data sources. It embeds
+// the Netbox API client and holds state that is shared across the provider.
+type providerState struct {
+	*client.NetBoxAPI
+
+	// defaultTags are added to every resource that supports tags. It is nil
+	// if no default tags are configured.
+	defaultTags *schema.Set
+
+	// tagCache resolves tag names to tags.
+	tagCache tagCache
+
+	// allocationLocks serializes allocations of available objects per
+	// parent, because Netbox may hand out the same object to parallel
+	// requests.
+	allocationLocks mutexKV
+
+	// netboxVersion is the version of the Netbox server. It is nil if the
+	// version check was skipped or failed.
+	netboxVersion *version.Version
+
+	// readOnly makes Create, Update and Delete of all resources fail.
+	readOnly bool
+
+	// runID identifies the requests of this Terraform run.
+	runID string
+
+	// changelogComment is recorded with the run ID in the journal of every
+	// object the provider creates or updates. It is empty if no journal
+	// entries are added.
+	changelogComment string
+}
+
+// supportedNetboxVersions is the range of Netbox versions this provider is
+// tested against.
+var supportedNetboxVersions = version.MustConstraints(version.NewConstraint(">= 3.1.0, < 3.2.0"))
+
 func providerConfigure(ctx context.Context, data *schema.ResourceData) (interface{}, diag.Diagnostics) {
 
 	var diags diag.Diagnostics
 
 	config := Config{
-		ServerURL:          data.Get("server_url").(string),
-		APIToken:           data.Get("api_token").(string),
-		AllowInsecureHttps: data.Get("allow_insecure_https").(bool),
-		Headers:            data.Get("headers").(map[string]interface{}),
+		ServerURL:           data.Get("server_url").(string),
+		APIToken:            data.Get("api_token").(string),
+		APITokenFile:        data.Get("api_token_file").(string),
+		APITokenCommand:     data.Get("api_token_command").(string),
+		Username:            data.Get("username").(string),
+		Password:            data.Get("password").(string),
+		AllowInsecureHttps:  data.Get("allow_insecure_https").(bool),
+		Headers:             data.Get("headers").(map[string]interface{}),
+		MaxRetries:          data.Get("max_retries").(int),
+		RetryWaitMin:        time.Duration(data.Get("retry_wait_min").(int)) * time.Second,
+		RetryWaitMax:        time.Duration(data.Get("retry_wait_max").(int)) * time.Second,
+		CACertFile:          data.Get("ca_cert_file").(string),
+		CACertPEM:           data.Get("ca_cert_pem").(string),
+		ClientCert:          data.Get("client_cert").(string),
+		ClientKey:           data.Get("client_key").(string),
+		ProxyURL:            data.Get("proxy_url").(string),
+		RequestTimeout:      time.Duration(data.Get("request_timeout").(int)) * time.Second,
+		MaxIdleConnsPerHost: data.Get("max_idle_conns_per_host").(int),
+		KeepAlive:           time.Duration(data.Get("keepalive").(int)) * time.Second,
+		ReadOnly:            data.Get("read_only").(bool),
+		RunID:               data.Get("run_id").(string),
+		MaxRequestsPerSec:   data.Get("max_requests_per_second").(int),
+		MaxConcurrent:       data.Get("max_concurrent_requests").(int),
+		WaitForReady:        data.Get("wait_for_ready").(bool),
+		StartupTimeout:      time.Duration(data.Get("startup_timeout").(int)) * time.Second,
+	}
+	if config.RetryWaitMin > config.RetryWaitMax {
+		return nil, diag.Errorf("retry_wait_min (%d) must not be greater than retry_wait_max (%d)", data.Get("retry_wait_min").(int), data.Get("retry_wait_max").(int))
+	}
+	if config.KeepAlive == 0 {
+		config.KeepAlive = -1
+	}
+	if config.RunID == "" {
+		runID, err := uuid.GenerateUUID()
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		config.RunID = runID
 	}
 
-	netboxClient, clientError := config.Client()
+	netboxClient, clientError := config.Client(ctx)
 	if clientError != nil {
+		var notReady *notReadyError
+		if errors.As(clientError, &notReady) {
+			return nil, diag.Diagnostics{
+				diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  "Netbox is not ready",
+					Detail:   fmt.Sprintf("Netbox at %s did not answer requests to its status endpoint within %s, the last attempt failed with: %s. Make sure Netbox is running and reachable, or increase startup_timeout if it needs more time to start.", config.ServerURL, notReady.timeout, notReady.err),
+				},
+			}
+		}
 		return nil, diag.FromErr(clientError)
 	}
 
-	return netboxClient, diags
+	state := &providerState{
+		NetBoxAPI:        netboxClient.(*client.NetBoxAPI),
+		readOnly:         config.ReadOnly,
+		runID:            config.RunID,
+		changelogComment: data.Get("changelog_comment").(string),
+	}
+
+	if defaultTags, ok := data.GetOk("default_tags.0.tags"); ok && defaultTags.(*schema.Set).Len() > 0 {
+		state.defaultTags = defaultTags.(*schema.Set)
+	}
+
+	skipVersionCheck := data.Get("skip_version_check").(bool)
+	if !skipVersionCheck {
+		netboxVersion, err := getNetboxVersion(ctx, state.NetBoxAPI)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Unable to determine Netbox version",
+				Detail:   fmt.Sprintf("Could not read the Netbox version from the status endpoint: %s. Attributes requiring a specific Netbox version will not be checked.", err),
+			})
+		} else {
+			state.netboxVersion = netboxVersion
+			if !supportedNetboxVersions.Check(netboxVersion.Core()) {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Warning,
+					Summary:  "Possibly unsupported Netbox version",
+					Detail:   fmt.Sprintf("Your Netbox version is v%s. The provider was successfully tested against Netbox versions %s. Unexpected errors may occur.", netboxVersion, supportedNetboxVersions),
+				})
+			}
+		}
+	}
+
+	return state, diags
+}
+
+// requireNetboxVersion returns an error diagnostic if the Netbox server is
+// older than minVersion. If the version is unknown, the check passes and
+// Netbox itself has the final say.
+func (s *providerState) requireNetboxVersion(attribute string, minVersion string) diag.Diagnostics {
+	if s.netboxVersionBefore(minVersion) {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       fmt.Sprintf("Attribute %s requires Netbox >= %s", attribute, minVersion),
+				Detail:        fmt.Sprintf("The Netbox server runs version %s, which does not support the %s attribute.", s.netboxVersion, attribute),
+				AttributePath: cty.GetAttrPath(attribute),
+			},
+		}
+	}
+	return nil
+}
+
+// netboxVersionBefore reports whether the Netbox server is known to be older
+// than minVersion.
+func (s *providerState) netboxVersionBefore(minVersion string) bool {
+	return s.netboxVersion != nil && s.netboxVersion.Core().LessThan(version.Must(version.NewVersion(minVersion)))
 }
@@ -3,15 +3,23 @@ package netbox
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/netbox-community/go-netbox/netbox/client"
+	"github.com/stretchr/testify/assert"
 )
 
 var testAccProviders map[string]*schema.Provider
@@ -44,6 +52,22 @@ func testAccPreCheck(t *testing.T) {
 	}
 }
 
+// testAccPreCheckNetboxVersion skips an acceptance test if the Netbox server
+// is older than minVersion.
+func testAccPreCheckNetboxVersion(t *testing.T, minVersion string) {
+	m, err := sharedClientForRegion("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	netboxVersion, err := getNetboxVersion(context.Background(), m.(*client.NetBoxAPI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if netboxVersion.Core().LessThan(version.Must(version.NewVersion(minVersion))) {
+		t.Skipf("Netbox %s is older than %s", netboxVersion, minVersion)
+	}
+}
+
 func testProviderConfig(plattform string) string {
 	return fmt.Sprintf(`
 	resource "netbox_platform" "testplatform" {
@@ -51,6 +75,24 @@ func testProviderConfig(plattform string) string {
 	}`, plattform)
 }
 
+// testAccDeleteOutOfBand returns a check that deletes the object behind the
+// given resource through the API, as if it was deleted in the Netbox UI.
+// Steps using it must set ExpectNonEmptyPlan, because the following refresh
+// plans to recreate the object.
+func testAccDeleteOutOfBand(resourceName string, deleteFunc func(api *providerState, id int64) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource %s not found in state", resourceName)
+		}
+		id, err := strconv.ParseInt(rs.Primary.ID, 10, 64)
+		if err != nil {
+			return err
+		}
+		return deleteFunc(testAccProvider.Meta().(*providerState), id)
+	}
+}
+
 func providerInvalidConfigure() schema.ConfigureContextFunc {
 	return func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
 		var diags diag.Diagnostics
@@ -59,12 +101,12 @@ func providerInvalidConfigure() schema.ConfigureContextFunc {
 		config.ServerURL = "https://fake.netbox.server"
 		config.APIToken = "1234567890"
 
-		netboxClient, clientError := config.Client()
+		netboxClient, clientError := config.Client(context.Background())
 		if clientError != nil {
 			return nil, diag.FromErr(clientError)
 		}
 
-		return netboxClient, diags
+		return &providerState{NetBoxAPI: netboxClient.(*client.NetBoxAPI)}, diags
 	}
 }
 
@@ -88,3 +130,206 @@ func TestAccNetboxProviderConfigure_failure(t *testing.T) {
 		},
 	})
 }
+
+func testStatusServer(t *testing.T, netboxVersion string, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		assert.Equal(t, "/api/status/", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"django-version": "3.2.12", "netbox-version": "%s"}`, netboxVersion)
+	}))
+}
+
+func testProviderConfigure(t *testing.T, raw map[string]interface{}) (*providerState, diag.Diagnostics) {
+	d := schema.TestResourceDataRaw(t, Provider().Schema, raw)
+	meta, diags := providerConfigure(context.Background(), d)
+	if meta == nil {
+		return nil, diags
+	}
+	return meta.(*providerState), diags
+}
+
+func TestProviderConfigureDetectsVersion(t *testing.T) {
+
+	requests := 0
+	ts := testStatusServer(t, "3.1.9", &requests)
+	defer ts.Close()
+
+	state, diags := testProviderConfigure(t, map[string]interface{}{
+		"server_url": ts.URL,
+		"api_token":  "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+	})
+	assert.Empty(t, diags)
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, "3.1.9", state.netboxVersion.String())
+}
+
+func TestProviderConfigureWarnsOnUnsupportedVersion(t *testing.T) {
+
+	requests := 0
+	ts := testStatusServer(t, "2.11.12", &requests)
+	defer ts.Close()
+
+	state, diags := testProviderConfigure(t, map[string]interface{}{
+		"server_url": ts.URL,
+		"api_token":  "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+	})
+	assert.NotNil(t, state)
+	assert.Len(t, diags, 1)
+	assert.Equal(t, diag.Warning, diags[0].Severity)
+	assert.Equal(t, "Possibly unsupported Netbox version", diags[0].Summary)
+}
+
+func TestProviderConfigureSkipVersionCheck(t *testing.T) {
+
+	requests := 0
+	ts := testStatusServer(t, "2.11.12", &requests)
+	defer ts.Close()
+
+	state, diags := testProviderConfigure(t, map[string]interface{}{
+		"server_url":         ts.URL,
+		"api_token":          "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		"skip_version_check": true,
+	})
+	assert.Empty(t, diags)
+	assert.Equal(t, 0, requests)
+	assert.Nil(t, state.netboxVersion)
+}
+
+func TestProviderConfigureRunID(t *testing.T) {
+	t.Setenv("NETBOX_RUN_ID", "")
+
+	requests := 0
+	ts := testStatusServer(t, "3.1.9", &requests)
+	defer ts.Close()
+
+	config := map[string]interface{}{
+		"server_url": ts.URL,
+		"api_token":  "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+	}
+	first, diags := testProviderConfigure(t, config)
+	assert.Empty(t, diags)
+	second, diags := testProviderConfigure(t, config)
+	assert.Empty(t, diags)
+	assert.Regexp(t, "^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$", first.runID)
+	assert.NotEqual(t, first.runID, second.runID)
+
+	t.Setenv("NETBOX_RUN_ID", "ci-job-1234")
+	state, diags := testProviderConfigure(t, config)
+	assert.Empty(t, diags)
+	assert.Equal(t, "ci-job-1234", state.runID)
+
+	config["run_id"] = "ci-job-5678"
+	state, diags = testProviderConfigure(t, config)
+	assert.Empty(t, diags)
+	assert.Equal(t, "ci-job-5678", state.runID)
+}
+
+func TestProviderConfigureRetryWait(t *testing.T) {
+
+	requests := 0
+	ts := testStatusServer(t, "3.1.9", &requests)
+	defer ts.Close()
+
+	config := map[string]interface{}{
+		"server_url":     ts.URL,
+		"api_token":      "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		"retry_wait_min": 10,
+		"retry_wait_max": 5,
+	}
+	_, diags := testProviderConfigure(t, config)
+	assert.True(t, diags.HasError())
+	assert.Equal(t, "retry_wait_min (10) must not be greater than retry_wait_max (5)", diags[0].Summary)
+
+	config["retry_wait_min"] = 0
+	_, diags = testProviderConfigure(t, config)
+	assert.Empty(t, diags)
+}
+
+func TestProviderConfigureMutualTLS(t *testing.T) {
+
+	clientCert, clientKey := testClientCertificate(t)
+	ts, serverCert := testMutualTLSServer(t, clientCert)
+
+	state, diags := testProviderConfigure(t, map[string]interface{}{
+		"server_url":  ts.URL,
+		"api_token":   "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		"ca_cert_pem": serverCert,
+		"client_cert": clientCert,
+		"client_key":  clientKey,
+	})
+	assert.Empty(t, diags)
+	assert.Equal(t, "3.1.9", state.netboxVersion.String())
+}
+
+func TestProviderValidateConnectionSettings(t *testing.T) {
+
+	for _, tt := range []struct {
+		raw   map[string]interface{}
+		valid bool
+	}{
+		{map[string]interface{}{"proxy_url": "http://proxy.example.com:3128"}, true},
+		{map[string]interface{}{"proxy_url": "proxy.example.com:3128"}, false},
+		{map[string]interface{}{"ca_cert_file": "ca.pem", "ca_cert_pem": "pem"}, false},
+		{map[string]interface{}{"client_cert": "client.pem"}, false},
+		{map[string]interface{}{"client_cert": "client.pem", "client_key": "client-key.pem"}, true},
+		{map[string]interface{}{"max_requests_per_second": 20, "max_concurrent_requests": 4}, true},
+		{map[string]interface{}{"max_requests_per_second": -1}, false},
+		{map[string]interface{}{"max_concurrent_requests": -1}, false},
+	} {
+		tt.raw["server_url"] = "https://netbox.example.com"
+		tt.raw["api_token"] = "07b12b765127747e4afd56cb531b7bf9c61f3c30"
+		diags := Provider().Validate(terraform.NewResourceConfigRaw(tt.raw))
+		assert.Equal(t, tt.valid, !diags.HasError(), "%v: %v", tt.raw, diags)
+	}
+}
+
+func TestRequireNetboxVersion(t *testing.T) {
+
+	state := &providerState{}
+	assert.Empty(t, state.requireNetboxVersion("foo", "3.2.0"))
+
+	state.netboxVersion = version.Must(version.NewVersion("3.2.0-beta2"))
+	assert.Empty(t, state.requireNetboxVersion("foo", "3.2.0"))
+
+	state.netboxVersion = version.Must(version.NewVersion("3.1.9"))
+	diags := state.requireNetboxVersion("foo", "3.2.0")
+	assert.True(t, diags.HasError())
+	assert.Equal(t, "Attribute foo requires Netbox >= 3.2.0", diags[0].Summary)
+}
+
+func TestProviderResourceTimeouts(t *testing.T) {
+	for name, r := range Provider().ResourcesMap {
+		if assert.NotNil(t, r.Timeouts, name) {
+			assert.NotNil(t, r.Timeouts.Create, name)
+			assert.NotNil(t, r.Timeouts.Read, name)
+			assert.NotNil(t, r.Timeouts.Update, name)
+			assert.NotNil(t, r.Timeouts.Delete, name)
+		}
+	}
+}
+
+func TestResourceRequestsAreCanceled(t *testing.T) {
+	var requests int32
+	ts := testSlowServer(t, 10*time.Second, &requests)
+
+	config := Config{
+		APIToken:  "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL: ts.URL,
+	}
+	netboxClient, err := config.Client(context.Background())
+	assert.NoError(t, err)
+	state := &providerState{NetBoxAPI: netboxClient.(*client.NetBoxAPI)}
+
+	r := resourceNetboxInterface()
+	d := r.TestResourceData()
+	d.SetId("1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	diags := r.ReadContext(ctx, d, state)
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, context.DeadlineExceeded.Error())
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,155 @@
+package netbox
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimitTransport is a transport that limits the rate and the number of
+// concurrent requests to Netbox, so that large applies leave capacity for
+// other users of Netbox. A request holds its slot until its response body has
+// been closed, as the connection is busy until then.
+type rateLimitTransport struct {
+	original http.RoundTripper
+
+	// bucket limits the rate of requests. It is nil if the rate is unlimited.
+	bucket *tokenBucket
+
+	// slots limits the number of concurrent requests. It is nil if the
+	// concurrency is unlimited.
+	slots chan struct{}
+}
+
+// newRateLimitTransport returns a transport that sends at most
+// requestsPerSecond requests per second and at most maxConcurrent requests at
+// a time. A limit of 0 disables the respective limit.
+func newRateLimitTransport(original http.RoundTripper, requestsPerSecond int, maxConcurrent int) rateLimitTransport {
+	t := rateLimitTransport{original: original}
+	if requestsPerSecond > 0 {
+		t.bucket = newTokenBucket(float64(requestsPerSecond), 1)
+	}
+	if maxConcurrent > 0 {
+		t.slots = make(chan struct{}, maxConcurrent)
+	}
+	return t
+}
+
+// RoundTrip waits for a free slot and a token before sending the request.
+func (t rateLimitTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	release := func() {}
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+		case <-r.Context().Done():
+			return nil, r.Context().Err()
+		}
+		var once sync.Once
+		release = func() { once.Do(func() { <-t.slots }) }
+	}
+
+	if t.bucket != nil {
+		if err := t.bucket.Wait(r.Context()); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	resp, err := t.original.RoundTrip(r)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releasingBody releases the slot of its request once it has been closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close closes the body and releases the slot of its request.
+func (b releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// tokenBucket is a token bucket rate limiter. Waiters reserve tokens in the
+// order they arrive, so the bucket may go into debt, which later waiters pay
+// off by waiting longer.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	// now returns the current time. It is replaced in tests.
+	now func() time.Time
+}
+
+// newTokenBucket returns a full bucket that refills rate tokens per second
+// and holds at most burst tokens.
+func newTokenBucket(rate float64, burst float64) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		now:    time.Now,
+	}
+}
+
+// reserve takes a token from the bucket and returns how long the caller has
+// to wait until the token is actually available.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token that was not used.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	delay := b.reserve()
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
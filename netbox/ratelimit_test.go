@@ -0,0 +1,151 @@
+package netbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	netboxClient "github.com/netbox-community/go-netbox/netbox/client"
+	"github.com/netbox-community/go-netbox/netbox/client/status"
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(0, 0)
+	bucket := newTokenBucket(10, 1)
+	bucket.now = func() time.Time { return now }
+
+	assert.Equal(t, time.Duration(0), bucket.reserve())
+	assert.Equal(t, 100*time.Millisecond, bucket.reserve())
+	assert.Equal(t, 200*time.Millisecond, bucket.reserve())
+
+	now = now.Add(250 * time.Millisecond)
+	assert.Equal(t, 50*time.Millisecond, bucket.reserve())
+
+	// Idle time does not accumulate more tokens than the burst
+	now = now.Add(time.Hour)
+	assert.Equal(t, time.Duration(0), bucket.reserve())
+	assert.Equal(t, 100*time.Millisecond, bucket.reserve())
+}
+
+func TestTokenBucketWaitCanceled(t *testing.T) {
+	now := time.Unix(0, 0)
+	bucket := newTokenBucket(1, 1)
+	bucket.now = func() time.Time { return now }
+
+	assert.NoError(t, bucket.Wait(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, bucket.Wait(ctx), context.Canceled)
+
+	// The canceled waiter returned its token
+	assert.Equal(t, time.Second, bucket.reserve())
+}
+
+func TestRateLimitTransportRate(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer ts.Close()
+
+	config := Config{
+		APIToken:          "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL:         ts.URL,
+		MaxRequestsPerSec: 50,
+	}
+	client, err := config.Client(context.Background())
+	assert.NoError(t, err)
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		client.(*netboxClient.NetBoxAPI).Status.StatusList(status.NewStatusListParams(), nil)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assert.Equal(t, int32(6), atomic.LoadInt32(&requests))
+}
+
+func TestRateLimitTransportConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	config := Config{
+		APIToken:      "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL:     ts.URL,
+		MaxConcurrent: 2,
+	}
+	client, err := config.Client(context.Background())
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.(*netboxClient.NetBoxAPI).Status.StatusList(status.NewStatusListParams(), nil)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxInFlight))
+}
+
+func TestRateLimitTransportReleasesSlots(t *testing.T) {
+	fail := true
+	transport := newRateLimitTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if fail {
+			return nil, errors.New("connection refused")
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+	}), 0, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://netbox.example.com/api/status/", nil)
+
+	_, err := transport.RoundTrip(req)
+	assert.Error(t, err)
+
+	fail = false
+	for i := 0; i < 3; i++ {
+		resp, err := transport.RoundTrip(req)
+		if assert.NoError(t, err) {
+			resp.Body.Close()
+			// Closing twice must not release a slot of another request
+			resp.Body.Close()
+		}
+	}
+	assert.Len(t, transport.slots, 0)
+
+	// A request waiting for a slot gives up with its context
+	transport.slots <- struct{}{}
+	cancel()
+	_, err = transport.RoundTrip(req)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,56 @@
+package netbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// readOnlyTransport is a transport that refuses to send requests that could
+// modify Netbox. It backs up the checks of the read-only provider in case a
+// code path writes to Netbox outside of Create, Update and Delete.
+type readOnlyTransport struct {
+	original http.RoundTripper
+}
+
+// RoundTrip sends GET requests and fails any other request without sending
+// it.
+func (t readOnlyTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.Method != http.MethodGet {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		return nil, fmt.Errorf("refusing to send %s request to %s: the provider is configured with read_only, which only allows GET requests", r.Method, r.URL.Path)
+	}
+	return t.original.RoundTrip(r)
+}
+
+// withReadOnlyGuard makes the Create, Update and Delete functions of a
+// resource fail without contacting Netbox if the provider is read-only.
+func withReadOnlyGuard(name string, r *schema.Resource) *schema.Resource {
+	guard := func(action string, f crudContextFunc) crudContextFunc {
+		if f == nil {
+			return nil
+		}
+		return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			if state, ok := m.(*providerState); ok && state.readOnly {
+				return diag.Diagnostics{
+					diag.Diagnostic{
+						Severity: diag.Error,
+						Summary:  fmt.Sprintf("Cannot %s %s: the provider is read-only", action, name),
+						Detail:   "The provider is configured with read_only, so it does not create, update or delete objects in Netbox. Unset read_only to apply changes.",
+					},
+				}
+			}
+			return f(ctx, d, m)
+		}
+	}
+
+	r.CreateContext = guard("create", r.CreateContext)
+	r.UpdateContext = guard("update", r.UpdateContext)
+	r.DeleteContext = guard("delete", r.DeleteContext)
+	return r
+}
@@ -0,0 +1,133 @@
+package netbox
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/netbox-community/go-netbox/netbox/client"
+	"github.com/netbox-community/go-netbox/netbox/client/tenancy"
+	"github.com/netbox-community/go-netbox/netbox/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// readOnlyProviderState returns the state of a read-only provider that
+// points to the fake.
+func (f *fakeNetbox) readOnlyProviderState(t *testing.T) *providerState {
+	config := Config{
+		APIToken:   "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL:  f.URL,
+		MaxRetries: 3,
+		ReadOnly:   true,
+	}
+	netboxClient, err := config.Client(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &providerState{NetBoxAPI: netboxClient.(*client.NetBoxAPI), readOnly: true}
+}
+
+// assertNoWrites fails if the fake received any request other than GET.
+func assertNoWrites(t *testing.T, fake *fakeNetbox) {
+	for _, request := range fake.Requests() {
+		assert.True(t, strings.HasPrefix(request, "GET "), "unexpected request %s", request)
+	}
+}
+
+func TestReadOnlyTransport(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.readOnlyProviderState(t)
+
+	_, err := api.Tenancy.TenancyTenantsList(tenancy.NewTenancyTenantsListParams(), nil)
+	assert.NoError(t, err)
+
+	name := "tenant"
+	_, err = api.Tenancy.TenancyTenantsCreate(tenancy.NewTenancyTenantsCreateParams().WithData(&models.WritableTenant{
+		Name: &name,
+		Slug: &name,
+	}), nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "refusing to send POST request to /api/tenancy/tenants/")
+	}
+
+	_, err = api.Tenancy.TenancyTenantsDelete(tenancy.NewTenancyTenantsDeleteParams().WithID(1), nil)
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"GET /api/tenancy/tenants/"}, fake.Requests())
+	assert.Equal(t, 0, fake.Count("tenancy/tenants"))
+}
+
+func TestReadOnlyProvisioning(t *testing.T) {
+	fake := newFakeNetbox(t)
+	config := Config{
+		Username:  "admin",
+		Password:  "secret",
+		ServerURL: fake.URL,
+		ReadOnly:  true,
+	}
+
+	_, err := config.Client(context.Background())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cannot be used with read_only")
+	}
+	assert.Empty(t, fake.Requests())
+}
+
+func TestUnitReadOnlyResourceLifecycle(t *testing.T) {
+	fake := newFakeNetbox(t)
+	meta := fake.readOnlyProviderState(t)
+	r := Provider().ResourcesMap["netbox_tenant"]
+
+	_, diags := testUnitApply(t, r, nil, map[string]interface{}{"name": "tenant"}, meta)
+	if assert.True(t, diags.HasError()) {
+		assert.Equal(t, "Cannot create netbox_tenant: the provider is read-only", diags[0].Summary)
+	}
+
+	id := fake.Seed(t, "tenancy/tenants", map[string]interface{}{"name": "tenant", "slug": "tenant"})
+	state := &terraform.InstanceState{ID: strconv.FormatInt(id, 10), Attributes: map[string]string{}}
+	state, diags = testUnitRefresh(t, r, state, meta)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "tenant", state.Attributes["name"])
+
+	_, diags = testUnitApply(t, r, state, map[string]interface{}{"name": "renamed"}, meta)
+	if assert.True(t, diags.HasError()) {
+		assert.Equal(t, "Cannot update netbox_tenant: the provider is read-only", diags[0].Summary)
+	}
+
+	_, diags = testUnitApply(t, r, state, nil, meta)
+	if assert.True(t, diags.HasError()) {
+		assert.Equal(t, "Cannot delete netbox_tenant: the provider is read-only", diags[0].Summary)
+	}
+
+	assertNoWrites(t, fake)
+	assert.Equal(t, "tenant", fake.Get("tenancy/tenants", id)["name"])
+}
+
+func TestUnitReadOnlyResources(t *testing.T) {
+	fake := newFakeNetbox(t)
+	meta := fake.readOnlyProviderState(t)
+
+	for name, r := range Provider().ResourcesMap {
+		t.Run(name, func(t *testing.T) {
+			for action, f := range map[string]crudContextFunc{
+				"create": r.CreateContext,
+				"update": r.UpdateContext,
+				"delete": r.DeleteContext,
+			} {
+				if f == nil {
+					continue
+				}
+				d := r.TestResourceData()
+				d.SetId("1")
+				diags := f(context.Background(), d, meta)
+				if assert.True(t, diags.HasError(), action) {
+					assert.Contains(t, diags[0].Summary, "Cannot "+action+" "+name)
+				}
+			}
+		})
+	}
+
+	assert.Empty(t, fake.Requests())
+}
@@ -0,0 +1,76 @@
+package netbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	netboxclient "github.com/netbox-community/go-netbox/netbox/client"
+)
+
+// readyPollInterval is the time between two checks whether Netbox is ready.
+var readyPollInterval = 2 * time.Second
+
+// notReadyError is returned if Netbox did not become ready in time.
+type notReadyError struct {
+	timeout time.Duration
+	err     error
+}
+
+func (e *notReadyError) Error() string {
+	return fmt.Sprintf("Netbox did not become ready within %s: %s", e.timeout, e.err)
+}
+
+func (e *notReadyError) Unwrap() error {
+	return e.err
+}
+
+// waitForNetbox polls the status endpoint of Netbox until it answers or the
+// timeout expires. Connection errors, rate limits and server errors, which
+// proxies in front of Netbox return while it starts, mean Netbox is not ready
+// yet. Authentication errors mean it is ready, as the status endpoint may
+// require a token that is only provisioned afterwards. Any other error is
+// returned right away, because waiting will not fix it.
+func waitForNetbox(ctx context.Context, api *netboxclient.NetBoxAPI, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		_, err := getNetboxStatus(ctx, api)
+		if err == nil {
+			return nil
+		}
+
+		var apiError *runtime.APIError
+		if errors.As(err, &apiError) {
+			switch code := apiError.Code; {
+			case code == http.StatusUnauthorized || code == http.StatusForbidden:
+				return nil
+			case code != http.StatusTooManyRequests && code < http.StatusInternalServerError:
+				return err
+			}
+		}
+		// Requests aborted by the timeout tell nothing about Netbox
+		if ctx.Err() == nil || lastErr == nil {
+			lastErr = err
+		}
+
+		tflog.Info(ctx, "Waiting for Netbox to become ready", map[string]interface{}{
+			"attempt": attempt,
+			"error":   err.Error(),
+		})
+
+		timer := time.NewTimer(readyPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &notReadyError{timeout: timeout, err: lastErr}
+		case <-timer.C:
+		}
+	}
+}
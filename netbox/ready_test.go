@@ -0,0 +1,139 @@
+package netbox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/stretchr/testify/assert"
+)
+
+// testStartingServer answers the status endpoint with the given status codes
+// in turn, repeating the last one.
+func testStartingServer(t *testing.T, requests *int32, codes ...int) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := int(atomic.AddInt32(requests, 1))
+		code := codes[len(codes)-1]
+		if request <= len(codes) {
+			code = codes[request-1]
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		w.Write([]byte(`{"netbox-version": "3.1.9"}`))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func testReadyPollInterval(t *testing.T) {
+	interval := readyPollInterval
+	readyPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { readyPollInterval = interval })
+}
+
+func TestClientWaitForReady(t *testing.T) {
+	testReadyPollInterval(t)
+
+	testCases := []struct {
+		name     string
+		codes    []int
+		requests int32
+		err      string
+	}{
+		{
+			name:     "Ready",
+			codes:    []int{http.StatusOK},
+			requests: 1,
+		},
+		{
+			name:     "Starting",
+			codes:    []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			requests: 4,
+		},
+		{
+			name:     "LoginRequired",
+			codes:    []int{http.StatusBadGateway, http.StatusForbidden},
+			requests: 2,
+		},
+		{
+			name:     "NotFound",
+			codes:    []int{http.StatusNotFound},
+			requests: 1,
+			err:      "GET /status/ returned 404 Not Found",
+		},
+		{
+			name:  "Timeout",
+			codes: []int{http.StatusServiceUnavailable},
+			err:   "Netbox did not become ready within 100ms: GET /status/ returned 503 Service Unavailable",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			ts := testStartingServer(t, &requests, tt.codes...)
+
+			config := Config{
+				APIToken:       "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+				ServerURL:      ts.URL,
+				WaitForReady:   true,
+				StartupTimeout: 100 * time.Millisecond,
+			}
+			_, err := config.Client(context.Background())
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.err)
+			}
+			if tt.requests > 0 {
+				assert.Equal(t, tt.requests, atomic.LoadInt32(&requests))
+			}
+		})
+	}
+}
+
+func TestClientWaitForReadyConnectionRefused(t *testing.T) {
+	testReadyPollInterval(t)
+
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close()
+
+	config := Config{
+		APIToken:       "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL:      ts.URL,
+		WaitForReady:   true,
+		StartupTimeout: 50 * time.Millisecond,
+	}
+	_, err := config.Client(context.Background())
+	var notReady *notReadyError
+	if assert.True(t, errors.As(err, &notReady), "%v", err) {
+		assert.Contains(t, notReady.err.Error(), "connection refused")
+	}
+}
+
+func TestProviderConfigureWaitForReady(t *testing.T) {
+	testReadyPollInterval(t)
+
+	var requests int32
+	ts := testStartingServer(t, &requests, http.StatusServiceUnavailable)
+
+	_, diags := testProviderConfigure(t, map[string]interface{}{
+		"server_url":      ts.URL,
+		"api_token":       "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		"max_retries":     0,
+		"wait_for_ready":  true,
+		"startup_timeout": 1,
+	})
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, diag.Error, diags[0].Severity)
+		assert.Equal(t, "Netbox is not ready", diags[0].Summary)
+		assert.Contains(t, diags[0].Detail, ts.URL)
+		assert.Contains(t, diags[0].Detail, "503 Service Unavailable")
+	}
+	assert.Greater(t, atomic.LoadInt32(&requests), int32(1))
+}
@@ -1,26 +1,30 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxAggregate() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxAggregateCreate,
-		Read:   resourceNetboxAggregateRead,
-		Update: resourceNetboxAggregateUpdate,
-		Delete: resourceNetboxAggregateDelete,
+		CreateContext: resourceNetboxAggregateCreate,
+		ReadContext:   resourceNetboxAggregateRead,
+		UpdateContext: resourceNetboxAggregateUpdate,
+		DeleteContext: resourceNetboxAggregateDelete,
+		CustomizeDiff: customizeDiffTagsAll,
 
 		Description: `From the [official documentation](https://docs.netbox.dev/en/stable/core-functionality/ipam/#aggregates):
 
 > NetBox allows us to specify the portions of IP space that are interesting to us by defining aggregates. Typically, an aggregate will correspond to either an allocation of public (globally routable) IP space granted by a regional authority, or a private (internally-routable) designation.`,
 
+		Timeouts: defaultTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"prefix": {
 				Type:         schema.TypeString,
@@ -47,14 +51,15 @@ func resourceNetboxAggregate() *schema.Resource {
 				Optional: true,
 				Set:      schema.HashString,
 			},
+			"tags_all": tagsAllSchema,
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
-func resourceNetboxAggregateCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxAggregateCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	data := models.WritableAggregate{}
 
 	prefix := d.Get("prefix").(string)
@@ -71,27 +76,34 @@ func resourceNetboxAggregateCreate(d *schema.ResourceData, m interface{}) error
 		data.Rir = int64ToPtr(int64(rirID.(int)))
 	}
 
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	data.Tags = tags
 
-	params := ipam.NewIpamAggregatesCreateParams().WithData(&data)
+	params := ipam.NewIpamAggregatesCreateParams().WithContext(ctx).WithData(&data)
 	res, err := api.Ipam.IpamAggregatesCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxAggregateRead(d, m)
+	return resourceNetboxAggregateRead(ctx, d, m)
 }
 
-func resourceNetboxAggregateRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxAggregateRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamAggregatesReadParams().WithID(id)
+	params := ipam.NewIpamAggregatesReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Ipam.IpamAggregatesRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("description", res.GetPayload().Description)
@@ -111,13 +123,13 @@ func resourceNetboxAggregateRead(d *schema.ResourceData, m interface{}) error {
 		d.Set("rir_id", nil)
 	}
 
-	d.Set("tags", getTagListFromNestedTagList(res.GetPayload().Tags))
+	setTagsFromNestedTagList(api, d, res.GetPayload().Tags)
 
 	return nil
 }
 
-func resourceNetboxAggregateUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxAggregateUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritableAggregate{}
 	prefix := d.Get("prefix").(string)
@@ -134,23 +146,27 @@ func resourceNetboxAggregateUpdate(d *schema.ResourceData, m interface{}) error
 		data.Rir = int64ToPtr(int64(rirID.(int)))
 	}
 
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	data.Tags = tags
 
-	params := ipam.NewIpamAggregatesUpdateParams().WithID(id).WithData(&data)
-	_, err := api.Ipam.IpamAggregatesUpdate(params, nil)
+	params := ipam.NewIpamAggregatesUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
+	_, err = api.Ipam.IpamAggregatesUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
-	return resourceNetboxAggregateRead(d, m)
+	return resourceNetboxAggregateRead(ctx, d, m)
 }
 
-func resourceNetboxAggregateDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxAggregateDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamAggregatesDeleteParams().WithID(id)
+	params := ipam.NewIpamAggregatesDeleteParams().WithContext(ctx).WithID(id)
 	_, err := api.Ipam.IpamAggregatesDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	d.SetId("")
 	return nil
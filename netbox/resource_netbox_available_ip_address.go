@@ -1,30 +1,42 @@
 package netbox
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-openapi/runtime"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 	"github.com/netbox-community/go-netbox/netbox/models"
-	"strconv"
 )
 
 func resourceNetboxAvailableIPAddress() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxAvailableIPAddressCreate,
-		Read:   resourceNetboxAvailableIPAddressRead,
-		Update: resourceNetboxAvailableIPAddressUpdate,
-		Delete: resourceNetboxAvailableIPAddressDelete,
+		CreateContext: resourceNetboxAvailableIPAddressCreate,
+		ReadContext:   resourceNetboxAvailableIPAddressRead,
+		UpdateContext: resourceNetboxAvailableIPAddressUpdate,
+		DeleteContext: resourceNetboxAvailableIPAddressDelete,
+		CustomizeDiff: customizeDiffTagsAll,
+
+		Timeouts: allocationTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"prefix_id": &schema.Schema{
 				Type:         schema.TypeInt,
 				Optional:     true,
+				ForceNew:     true,
 				ExactlyOneOf: []string{"prefix_id", "ip_range_id"},
 			},
 			"ip_range_id": &schema.Schema{
-				Type:     schema.TypeInt,
-				Optional: true,
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"prefix_id", "ip_range_id"},
 			},
 			"ip_address": &schema.Schema{
 				Type:     schema.TypeString,
@@ -34,6 +46,13 @@ func resourceNetboxAvailableIPAddress() *schema.Resource {
 				Type:     schema.TypeInt,
 				Optional: true,
 			},
+			"object_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "virtualization.vminterface",
+				ValidateFunc: validation.StringInSlice([]string{"dcim.interface", "virtualization.vminterface"}, false),
+				Description:  "The type of the interface given in `interface_id`. Either `dcim.interface` for device interfaces or `virtualization.vminterface` for virtual machine interfaces. Defaults to `virtualization.vminterface`.",
+			},
 			"vrf_id": &schema.Schema{
 				Type:     schema.TypeInt,
 				Optional: true,
@@ -64,50 +83,130 @@ func resourceNetboxAvailableIPAddress() *schema.Resource {
 				Optional: true,
 				Set:      schema.HashString,
 			},
+			"tags_all": tagsAllSchema,
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxAvailableIPAddressCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
-	prefixId := int64(d.Get("family").(int))
-	address := d.Get("address").(string)
-
-	data := models.WritableAvailableIP{
-		Address: address,
-		Family:  prefixId,
-	}
-	if prefixId != 0 {
-		params := ipam.NewIpamPrefixesAvailableIpsCreateParams().WithID(prefixId).WithData(&data)
-		res, _ := api.Ipam.IpamPrefixesAvailableIpsCreate(params, nil)
-		// Since we generated the ip_address set that now
-		d.SetId(strconv.FormatInt(res.Payload[0].ID, 10))
-		err := d.Set("ip_address", *res.Payload[0].Address)
+func resourceNetboxAvailableIPAddressCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+
+	if _, ok := d.GetOk("ip_range_id"); ok {
+		if diags := api.requireNetboxVersion("ip_range_id", "3.0.0"); diags.HasError() {
+			return diags
+		}
+	}
+
+	data, err := getWritableIPAddressFromAvailableIPAddressResource(ctx, api, d)
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	// Allocating a list makes Netbox respond with a list, which is what the
+	// generated client expects
+	body := []*models.WritableIPAddress{data}
+
+	var payload []*models.IPAddress
+	if prefixID, ok := d.GetOk("prefix_id"); ok {
+		lockKey := allocationLockKey("prefix", int64(prefixID.(int)))
+		api.allocationLocks.Lock(lockKey)
+		params := ipam.NewIpamPrefixesAvailableIpsCreateParams().WithContext(ctx).WithID(int64(prefixID.(int)))
+		res, err := api.Ipam.IpamPrefixesAvailableIpsCreate(params, nil, withRequestBody(params, body))
+		api.allocationLocks.Unlock(lockKey)
+		if err != nil {
+			return errorDiagnostics(availableIPAddressError(err, "prefix", prefixID.(int)))
+		}
+		payload = res.GetPayload()
+	} else {
+		rangeID := d.Get("ip_range_id").(int)
+		lockKey := allocationLockKey("ip-range", int64(rangeID))
+		api.allocationLocks.Lock(lockKey)
+		params := ipam.NewIpamIPRangesAvailableIpsCreateParams().WithContext(ctx).WithID(int64(rangeID))
+		res, err := api.Ipam.IpamIPRangesAvailableIpsCreate(params, nil, withRequestBody(params, body))
+		api.allocationLocks.Unlock(lockKey)
 		if err != nil {
-			return err
+			return errorDiagnostics(availableIPAddressError(err, "IP range", rangeID))
 		}
+		payload = res.GetPayload()
+	}
+
+	if len(payload) == 0 {
+		return diag.Errorf("netbox did not return the allocated IP address")
 	}
+	d.SetId(strconv.FormatInt(payload[0].ID, 10))
 
-	return resourceNetboxAvailableIPAddressUpdate(d, m)
+	return resourceNetboxAvailableIPAddressRead(ctx, d, m)
 }
 
-func resourceNetboxAvailableIPAddressRead(d *schema.ResourceData, m interface{}) error {
+// availableIPAddressError translates the response Netbox sends when the
+// parent has no free IP address left into a readable error.
+func availableIPAddressError(err error, parentType string, parentID int) error {
+	var apiError *runtime.APIError
+	if errors.As(err, &apiError) && apiError.IsCode(http.StatusNoContent) {
+		return fmt.Errorf("no IP address available in %s %d", parentType, parentID)
+	}
+	return err
+}
 
-	api := m.(*client.NetBoxAPI)
-	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamIPAddressesReadParams().WithID(id)
+func getWritableIPAddressFromAvailableIPAddressResource(ctx context.Context, api *providerState, d *schema.ResourceData) (*models.WritableIPAddress, error) {
+	data := models.WritableIPAddress{}
+
+	data.Status = d.Get("status").(string)
+	data.Description = d.Get("description").(string)
+
+	if d.HasChange("dns_name") {
+		// WritableIPAddress omits empty values so set to ' '
+		if dnsName := d.Get("dns_name"); dnsName.(string) == "" {
+			data.DNSName = " "
+		} else {
+			data.DNSName = dnsName.(string)
+		}
+	}
+
+	if interfaceID, ok := d.GetOk("interface_id"); ok {
+		data.AssignedObjectType = strToPtr(d.Get("object_type").(string))
+		data.AssignedObjectID = int64ToPtr(int64(interfaceID.(int)))
+	}
 
-	res, err := api.Ipam.IpamIPAddressesRead(params, nil)
+	if vrfID, ok := d.GetOk("vrf_id"); ok {
+		data.Vrf = int64ToPtr(int64(vrfID.(int)))
+	}
+
+	if tenantID, ok := d.GetOk("tenant_id"); ok {
+		data.Tenant = int64ToPtr(int64(tenantID.(int)))
+	}
+
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
 	if err != nil {
+		return nil, err
+	}
+	data.Tags = tags
+
+	return &data, nil
+}
 
-		return err
+func resourceNetboxAvailableIPAddressRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+
+	api := m.(*providerState)
+	id, _ := strconv.ParseInt(d.Id(), 10, 64)
+	params := ipam.NewIpamIPAddressesReadParams().WithContext(ctx).WithID(id)
+
+	result := ipam.NewIpamIPAddressesReadOK()
+	result.Payload = &models.IPAddress{}
+	res, err := api.Ipam.IpamIPAddressesRead(params, nil, readIPAddress(result.Payload, result))
+	if err != nil {
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	if res.GetPayload().AssignedObjectID != nil {
 		d.Set("interface_id", res.GetPayload().AssignedObjectID)
+		d.Set("object_type", res.GetPayload().AssignedObjectType)
 	} else {
 		d.Set("interface_id", nil)
 	}
@@ -131,68 +230,43 @@ func resourceNetboxAvailableIPAddressRead(d *schema.ResourceData, m interface{})
 	d.Set("ip_address", res.GetPayload().Address)
 	d.Set("description", res.GetPayload().Description)
 	d.Set("status", res.GetPayload().Status.Value)
-	d.Set("tags", getTagListFromNestedTagList(res.GetPayload().Tags))
+	setTagsFromNestedTagList(api, d, res.GetPayload().Tags)
 	return nil
 }
 
-func resourceNetboxAvailableIPAddressUpdate(d *schema.ResourceData, m interface{}) error {
+func resourceNetboxAvailableIPAddressUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 
-	api := m.(*client.NetBoxAPI)
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	data := models.WritableIPAddress{}
+	data, err := getWritableIPAddressFromAvailableIPAddressResource(ctx, api, d)
+	if err != nil {
+		return errorDiagnostics(err)
+	}
 
 	ipAddress := d.Get("ip_address").(string)
-	status := d.Get("status").(string)
-	description := d.Get("description").(string)
-
-	data.Status = status
-	data.Description = description
 	data.Address = &ipAddress
 
-	if d.HasChange("dns_name") {
-		// WritableIPAddress omits empty values so set to ' '
-		if dnsName := d.Get("dns_name"); dnsName.(string) == "" {
-			data.DNSName = " "
-		} else {
-			data.DNSName = dnsName.(string)
-		}
-	}
-
-	if interfaceID, ok := d.GetOk("interface_id"); ok {
-		// The other possible type is dcim.interface for devices
-		data.AssignedObjectType = strToPtr("virtualization.vminterface")
-		data.AssignedObjectID = int64ToPtr(int64(interfaceID.(int)))
-	}
-
-	if vrfID, ok := d.GetOk("vrf_id"); ok {
-		data.Vrf = int64ToPtr(int64(vrfID.(int)))
-	}
-
-	if tenantID, ok := d.GetOk("tenant_id"); ok {
-		data.Tenant = int64ToPtr(int64(tenantID.(int)))
-	}
-
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
-
-	params := ipam.NewIpamIPAddressesUpdateParams().WithID(id).WithData(&data)
+	params := ipam.NewIpamIPAddressesUpdateParams().WithContext(ctx).WithID(id).WithData(data)
 
-	_, err := api.Ipam.IpamIPAddressesUpdate(params, nil)
+	result := ipam.NewIpamIPAddressesUpdateOK()
+	result.Payload = &models.IPAddress{}
+	_, err = api.Ipam.IpamIPAddressesUpdate(params, nil, readIPAddress(result.Payload, result))
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
-	return resourceNetboxAvailableIPAddressRead(d, m)
+	return resourceNetboxAvailableIPAddressRead(ctx, d, m)
 }
 
-func resourceNetboxAvailableIPAddressDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxAvailableIPAddressDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamIPAddressesDeleteParams().WithID(id)
+	params := ipam.NewIpamIPAddressesDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Ipam.IpamIPAddressesDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
@@ -1,15 +1,28 @@
 package netbox
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 	"github.com/netbox-community/go-netbox/netbox/models"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestAccNetboxAvailableIPAddress_basic(t *testing.T) {
@@ -39,6 +52,177 @@ resource "netbox_available_ip_address" "test" {
 		},
 	})
 }
+func TestAccNetboxAvailableIPAddress_ipv6(t *testing.T) {
+	testPrefix := "2001:db8:1::/64"
+	testIP := "2001:db8:1::/64"
+	resource.ParallelTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "netbox_prefix" "test" {
+	prefix = "%s"
+	status = "active"
+	is_pool = false
+}
+resource "netbox_available_ip_address" "test" {
+  prefix_id = netbox_prefix.test.id
+  status = "active"
+}`, testPrefix),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("netbox_available_ip_address.test", "ip_address", testIP),
+				),
+			},
+		},
+	})
+}
+
+func TestAccNetboxAvailableIPAddress_ipv6Range(t *testing.T) {
+	startAddress := "2001:db8:2::10/64"
+	endAddress := "2001:db8:2::20/64"
+	testIP := "2001:db8:2::10/64"
+	resource.ParallelTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "netbox_ip_range" "test" {
+    start_address = "%s"
+    end_address = "%s"
+}
+resource "netbox_available_ip_address" "test" {
+  ip_range_id = netbox_ip_range.test.id
+  status = "active"
+}`, startAddress, endAddress),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("netbox_available_ip_address.test", "ip_address", testIP),
+				),
+			},
+		},
+	})
+}
+
+func TestAccNetboxAvailableIPAddress_vmInterface(t *testing.T) {
+	testSlug := "avail_ip_vmif"
+	testName := testAccGetTestName(testSlug)
+	testPrefix := "1.1.10.0/24"
+	resource.ParallelTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		PreCheck:  func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNetboxIPAddressFullDependencies(testName) + fmt.Sprintf(`
+resource "netbox_prefix" "test" {
+	prefix = "%s"
+	status = "active"
+	is_pool = false
+}
+resource "netbox_available_ip_address" "test" {
+  prefix_id = netbox_prefix.test.id
+  status = "active"
+  object_type = "virtualization.vminterface"
+  interface_id = netbox_interface.test.id
+  tenant_id = netbox_tenant.test.id
+  vrf_id = netbox_vrf.test.id
+  tags = [netbox_tag.test.name]
+}`, testPrefix),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("netbox_available_ip_address.test", "object_type", "virtualization.vminterface"),
+					resource.TestCheckResourceAttrPair("netbox_available_ip_address.test", "interface_id", "netbox_interface.test", "id"),
+					resource.TestCheckResourceAttrPair("netbox_available_ip_address.test", "tenant_id", "netbox_tenant.test", "id"),
+					resource.TestCheckResourceAttrPair("netbox_available_ip_address.test", "vrf_id", "netbox_vrf.test", "id"),
+					resource.TestCheckResourceAttr("netbox_available_ip_address.test", "tags.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccNetboxAvailableIPAddress_exhaustedPrefix(t *testing.T) {
+	testPrefix := "1.1.11.0/30"
+	resource.ParallelTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "netbox_prefix" "test" {
+	prefix = "%s"
+	status = "active"
+	is_pool = false
+}
+resource "netbox_available_ip_address" "test1" {
+  prefix_id = netbox_prefix.test.id
+  status = "active"
+}
+resource "netbox_available_ip_address" "test2" {
+  depends_on = [netbox_available_ip_address.test1]
+  prefix_id = netbox_prefix.test.id
+  status = "active"
+}
+resource "netbox_available_ip_address" "test3" {
+  depends_on = [netbox_available_ip_address.test2]
+  prefix_id = netbox_prefix.test.id
+  status = "active"
+}`, testPrefix),
+				ExpectError: regexp.MustCompile("no IP address available in prefix"),
+			},
+		},
+	})
+}
+
+func TestAccNetboxAvailableIPAddress_exhaustedRange(t *testing.T) {
+	startAddress := "1.1.12.1/24"
+	endAddress := "1.1.12.1/24"
+	resource.ParallelTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "netbox_ip_range" "test" {
+    start_address = "%s"
+    end_address = "%s"
+}
+resource "netbox_available_ip_address" "test1" {
+  ip_range_id = netbox_ip_range.test.id
+  status = "active"
+}
+resource "netbox_available_ip_address" "test2" {
+  depends_on = [netbox_available_ip_address.test1]
+  ip_range_id = netbox_ip_range.test.id
+  status = "active"
+}`, startAddress, endAddress),
+				ExpectError: regexp.MustCompile("no IP address available in IP range"),
+			},
+		},
+	})
+}
+
+func TestAccNetboxAvailableIPAddress_deletedOutOfBand(t *testing.T) {
+	testPrefix := "1.1.8.0/24"
+	resource.ParallelTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "netbox_prefix" "test" {
+	prefix = "%s"
+	status = "active"
+	is_pool = false
+}
+resource "netbox_available_ip_address" "test" {
+  prefix_id = netbox_prefix.test.id
+  status = "active"
+}`, testPrefix),
+				Check: testAccDeleteOutOfBand("netbox_available_ip_address.test", func(api *providerState, id int64) error {
+					_, err := api.Ipam.IpamIPAddressesDelete(ipam.NewIpamIPAddressesDeleteParams().WithID(id), nil)
+					return err
+				}),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
 func TestAccNetboxAvailableIPAddress_basic_range(t *testing.T) {
 	startAddress := "1.1.5.1/24"
 	endAddress := "1.1.5.50/24"
@@ -223,6 +407,147 @@ resource "netbox_available_ip_address" "test_range3" {
 	})
 }
 
+// TestAvailableIPAddressCreateSerializesAllocations runs many allocations
+// from the same prefix concurrently against a stand-in server, which hands
+// out duplicate addresses if two allocations overlap.
+func TestUnitNetboxAvailableIPAddress_lifecycle(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	prefixID := fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.0.0/30", "status": "active"})
+	rangeID := fake.Seed(t, "ipam/ip-ranges", map[string]interface{}{"start_address": "10.1.0.10/24", "end_address": "10.1.0.11/24", "status": "active"})
+	r := resourceNetboxAvailableIPAddress()
+
+	first, diags := testUnitApply(t, r, nil, map[string]interface{}{"prefix_id": int(prefixID)}, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "10.0.0.1/30", first.Attributes["ip_address"])
+	second, diags := testUnitApply(t, r, nil, map[string]interface{}{"prefix_id": int(prefixID)}, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "10.0.0.2/30", second.Attributes["ip_address"])
+	_, diags = testUnitApply(t, r, nil, map[string]interface{}{"prefix_id": int(prefixID)}, api)
+	assert.True(t, diags.HasError())
+	assert.Regexp(t, "no IP address available in prefix", diags[0].Summary)
+
+	fromRange, diags := testUnitApply(t, r, nil, map[string]interface{}{"ip_range_id": int(rangeID), "description": "from range"}, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "10.1.0.10/24", fromRange.Attributes["ip_address"])
+
+	fromRange, diags = testUnitApply(t, r, fromRange, map[string]interface{}{"ip_range_id": int(rangeID), "description": "updated"}, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	id, _ := strconv.ParseInt(fromRange.ID, 10, 64)
+	assert.Equal(t, "updated", fake.Get("ipam/ip-addresses", id)["description"])
+	assert.Equal(t, "10.1.0.10/24", fake.Get("ipam/ip-addresses", id)["address"])
+
+	_, diags = testUnitApply(t, r, first, nil, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, 2, fake.Count("ipam/ip-addresses"))
+	again, diags := testUnitApply(t, r, nil, map[string]interface{}{"prefix_id": int(prefixID)}, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "10.0.0.1/30", again.Attributes["ip_address"])
+}
+
+func TestUnitNetboxAvailableIPAddress_requiresNetboxVersion(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	api.netboxVersion = version.Must(version.NewVersion("2.11.12"))
+	rangeID := fake.Seed(t, "ipam/ip-ranges", map[string]interface{}{"start_address": "10.1.0.10/24", "end_address": "10.1.0.11/24", "status": "active"})
+	r := resourceNetboxAvailableIPAddress()
+
+	_, diags := testUnitApply(t, r, nil, map[string]interface{}{"ip_range_id": int(rangeID)}, api)
+	assert.True(t, diags.HasError())
+	assert.Equal(t, "Attribute ip_range_id requires Netbox >= 3.0.0", diags[0].Summary)
+	assert.Equal(t, 0, fake.Count("ipam/ip-addresses"))
+
+	api.netboxVersion = version.Must(version.NewVersion("3.1.9"))
+	_, diags = testUnitApply(t, r, nil, map[string]interface{}{"ip_range_id": int(rangeID)}, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, 1, fake.Count("ipam/ip-addresses"))
+}
+
+func TestAvailableIPAddressCreateSerializesAllocations(t *testing.T) {
+
+	var mutex sync.Mutex
+	addresses := map[int64]string{}
+	allocated := 0
+	var inFlight, overlaps int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/ipam/prefixes/1/available-ips/":
+			if atomic.AddInt32(&inFlight, 1) > 1 {
+				atomic.AddInt32(&overlaps, 1)
+			}
+			defer atomic.AddInt32(&inFlight, -1)
+
+			// Read the next free address, then give parallel requests a
+			// chance to read the same one before it is committed
+			mutex.Lock()
+			next := allocated + 1
+			mutex.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mutex.Lock()
+			allocated = next
+			id := int64(next)
+			addresses[id] = fmt.Sprintf("10.0.0.%d/24", next)
+			mutex.Unlock()
+
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"id": id, "address": addresses[id]},
+			})
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/ipam/ip-addresses/"):
+			id, _ := strconv.ParseInt(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/ipam/ip-addresses/"), "/"), 10, 64)
+			mutex.Lock()
+			address := addresses[id]
+			mutex.Unlock()
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":      id,
+				"address": address,
+				"status":  map[string]interface{}{"value": "active", "label": "Active"},
+				"tags":    []interface{}{},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := Config{
+		APIToken:  "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL: ts.URL,
+	}
+	netboxClient, err := config.Client(context.Background())
+	assert.NoError(t, err)
+	state := &providerState{NetBoxAPI: netboxClient.(*client.NetBoxAPI)}
+
+	const count = 20
+	results := make([]*schema.ResourceData, count)
+	errs := make([]diag.Diagnostics, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			d := schema.TestResourceDataRaw(t, resourceNetboxAvailableIPAddress().Schema, map[string]interface{}{
+				"prefix_id": 1,
+				"status":    "active",
+			})
+			errs[i] = resourceNetboxAvailableIPAddressCreate(context.Background(), d, state)
+			results[i] = d
+		}(i)
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for i := 0; i < count; i++ {
+		assert.False(t, errs[i].HasError(), "%v", errs[i])
+		ipAddress := results[i].Get("ip_address").(string)
+		assert.False(t, seen[ipAddress], "address %s was allocated twice", ipAddress)
+		seen[ipAddress] = true
+	}
+	assert.Equal(t, int32(0), atomic.LoadInt32(&overlaps))
+}
+
 func init() {
 	resource.AddTestSweepers("netbox_available_ip_address", &resource.Sweeper{
 		Name:         "netbox_available_ip_address",
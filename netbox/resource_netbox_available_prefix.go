@@ -2,23 +2,29 @@ package netbox
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/go-openapi/runtime"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxAvailablePrefix() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxAvailablePrefixCreate,
-		Read:   resourceNetboxPrefixRead,
-		Update: resourceNetboxPrefixUpdate,
-		Delete: resourceNetboxPrefixDelete,
+		CreateContext: resourceNetboxAvailablePrefixCreate,
+		ReadContext:   resourceNetboxPrefixRead,
+		UpdateContext: resourceNetboxPrefixUpdate,
+		DeleteContext: resourceNetboxPrefixDelete,
+		CustomizeDiff: customizeDiffTagsAll,
+
+		Timeouts: allocationTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"parent_prefix_id": {
@@ -81,6 +87,7 @@ func resourceNetboxAvailablePrefix() *schema.Resource {
 				Optional: true,
 				Set:      schema.HashString,
 			},
+			"tags_all": tagsAllSchema,
 		},
 		Importer: &schema.ResourceImporter{
 			StateContext: func(c context.Context, rd *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
@@ -118,27 +125,57 @@ func resourceNetboxAvailablePrefixParseImport(import_str string) (int, string, i
 	return parent_id, parts[1], prefix_length, nil
 }
 
-func resourceNetboxAvailablePrefixCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxAvailablePrefixCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+
+	// Check before allocating, the prefix is updated with the remaining
+	// attributes afterwards
+	if d.Get("mark_utilized").(bool) {
+		if diags := api.requireNetboxVersion("mark_utilized", "3.0.0"); diags.HasError() {
+			return diags
+		}
+	}
 
 	parent_prefix_id := int64(d.Get("parent_prefix_id").(int))
 	prefix_length := int64(d.Get("prefix_length").(int))
 	data := models.PrefixLength{
 		PrefixLength: &prefix_length,
 	}
-	params := ipam.NewIpamPrefixesAvailablePrefixesCreateParams().WithID(parent_prefix_id).WithData(&data)
+	params := ipam.NewIpamPrefixesAvailablePrefixesCreateParams().WithContext(ctx).WithID(parent_prefix_id)
+
+	// Allocating a list makes Netbox respond with a list, which is what the
+	// generated client expects
+	body := []*models.PrefixLength{&data}
 
-	res, err := api.Ipam.IpamPrefixesAvailablePrefixesCreate(params, nil)
+	lockKey := allocationLockKey("prefix", parent_prefix_id)
+	api.allocationLocks.Lock(lockKey)
+	res, err := api.Ipam.IpamPrefixesAvailablePrefixesCreate(params, nil, withRequestBody(params, body))
+	api.allocationLocks.Unlock(lockKey)
 	if err != nil {
-		return err
+		return errorDiagnostics(availablePrefixError(err, parent_prefix_id, prefix_length))
 	}
 
 	payload := res.GetPayload()
+	if len(payload) == 0 {
+		return diag.Errorf("netbox did not return the allocated prefix")
+	}
 	d.SetId(strconv.FormatInt(payload[0].ID, 10))
 	err = d.Set("prefix", payload[0].Prefix)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxPrefixUpdate(d, m)
+	return resourceNetboxPrefixUpdate(ctx, d, m)
+}
+
+// availablePrefixError translates the response Netbox sends when the parent
+// has no room left for a prefix of the requested length into a readable
+// error that starts like the message of Netbox. Netbox answers with 204 No
+// Content, which the generated client does not expect.
+func availablePrefixError(err error, parentID int64, prefixLength int64) error {
+	var apiError *runtime.APIError
+	if errors.As(err, &apiError) && apiError.IsCode(http.StatusNoContent) {
+		return fmt.Errorf("Insufficient space is available in prefix %d for a prefix of length /%d", parentID, prefixLength)
+	}
+	return err
 }
@@ -1,8 +1,13 @@
 package netbox
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -11,6 +16,7 @@ import (
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 	"github.com/netbox-community/go-netbox/netbox/models"
 	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
 )
 
 func testAccNetboxAvailablePrefixFullDependencies(testName string, parent_prefix string) string {
@@ -148,6 +154,114 @@ resource "netbox_available_prefix" "test3" {
 	})
 }
 
+func TestUnitNetboxAvailablePrefix_lifecycle(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	parentID := fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.0.0/24", "status": "container"})
+	fake.Seed(t, "ipam/prefixes", map[string]interface{}{"prefix": "10.0.0.0/26", "status": "active"})
+	r := resourceNetboxAvailablePrefix()
+
+	config := map[string]interface{}{
+		"parent_prefix_id": int(parentID),
+		"prefix_length":    25,
+		"status":           "active",
+	}
+	state, diags := testUnitApply(t, r, nil, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "10.0.0.128/25", state.Attributes["prefix"])
+
+	_, diags = testUnitApply(t, r, nil, config, api)
+	if assert.True(t, diags.HasError()) {
+		assert.Contains(t, diags[0].Summary, fmt.Sprintf("Insufficient space is available in prefix %d for a prefix of length /25", parentID))
+	}
+
+	config["prefix_length"] = 27
+	small, diags := testUnitApply(t, r, nil, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "10.0.0.64/27", small.Attributes["prefix"])
+
+	_, diags = testUnitApply(t, r, state, nil, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, 3, fake.Count("ipam/prefixes"))
+}
+
+// TestAvailablePrefixCreateRequestsList checks that prefixes are allocated
+// with a list body against a stand-in server, which answers like Netbox with
+// a single object for a single object and a list for a list.
+func TestAvailablePrefixCreateRequestsList(t *testing.T) {
+
+	prefix := map[string]interface{}{
+		"id":     2,
+		"prefix": "10.0.0.128/25",
+		"status": map[string]interface{}{"value": "active", "label": "Active"},
+		"tags":   []interface{}{},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/ipam/prefixes/1/available-prefixes/":
+			var body interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(http.StatusCreated)
+			if requests, ok := body.([]interface{}); ok {
+				assert.Equal(t, []interface{}{map[string]interface{}{"prefix_length": float64(25)}}, requests)
+				json.NewEncoder(w).Encode([]interface{}{prefix})
+			} else {
+				json.NewEncoder(w).Encode(prefix)
+			}
+		case r.URL.Path == "/api/ipam/prefixes/2/":
+			json.NewEncoder(w).Encode(prefix)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	netboxClient := client.NewHTTPClientWithConfig(nil, client.DefaultTransportConfig().WithHost(strings.TrimPrefix(ts.URL, "http://")).WithSchemes([]string{"http"}))
+	state := &providerState{NetBoxAPI: netboxClient}
+
+	r := resourceNetboxAvailablePrefix()
+	ctx := context.Background()
+	diff, err := r.Diff(ctx, nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"parent_prefix_id": 1,
+		"prefix_length":    25,
+		"status":           "active",
+	}), state)
+	assert.NoError(t, err)
+	result, diags := r.Apply(ctx, nil, diff, state)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "2", result.ID)
+	assert.Equal(t, "10.0.0.128/25", result.Attributes["prefix"])
+}
+
+// TestAvailablePrefixCreateEmptyResponse checks that allocations fail with an
+// error instead of panicking when Netbox returns no prefix.
+func TestAvailablePrefixCreateEmptyResponse(t *testing.T) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`[]`))
+	}))
+	defer ts.Close()
+
+	netboxClient := client.NewHTTPClientWithConfig(nil, client.DefaultTransportConfig().WithHost(strings.TrimPrefix(ts.URL, "http://")).WithSchemes([]string{"http"}))
+	state := &providerState{NetBoxAPI: netboxClient}
+
+	r := resourceNetboxAvailablePrefix()
+	ctx := context.Background()
+	diff, err := r.Diff(ctx, nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"parent_prefix_id": 1,
+		"prefix_length":    25,
+		"status":           "active",
+	}), state)
+	assert.NoError(t, err)
+	_, diags := r.Apply(ctx, nil, diff, state)
+	if assert.True(t, diags.HasError()) {
+		assert.Equal(t, "netbox did not return the allocated prefix", diags[0].Summary)
+	}
+}
+
 func init() {
 	resource.AddTestSweepers("netbox_available_prefix", &resource.Sweeper{
 		Name:         "netbox_available_prefix",
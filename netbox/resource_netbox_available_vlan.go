@@ -0,0 +1,147 @@
+package netbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-openapi/runtime"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/netbox-community/go-netbox/netbox/client/ipam"
+	"github.com/netbox-community/go-netbox/netbox/models"
+)
+
+func resourceNetboxAvailableVlan() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceNetboxAvailableVlanCreate,
+		ReadContext:   resourceNetboxVlanRead,
+		UpdateContext: resourceNetboxVlanUpdate,
+		DeleteContext: resourceNetboxVlanDelete,
+		CustomizeDiff: customizeDiffTagsAll,
+
+		Description: "Allocates the next free VLAN ID of a VLAN group as new VLAN. Requires Netbox >= 3.2.0.",
+
+		Timeouts: allocationTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"group_id": {
+				Type:        schema.TypeInt,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the VLAN group to allocate the VLAN ID from.",
+			},
+			"vid": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The allocated VLAN ID.",
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"status": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"active", "reserved", "deprecated"}, false),
+			},
+			"tenant_id": {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+			"role_id": {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+			"site_id": {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"tags": {
+				Type: schema.TypeSet,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+				Set:      schema.HashString,
+			},
+			"tags_all": tagsAllSchema,
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceNetboxAvailableVlanCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+
+	// Netbox allocates VLANs from VLAN groups since 3.2
+	if api.netboxVersionBefore("3.2.0") {
+		return diag.Errorf("netbox_available_vlan requires Netbox >= 3.2.0, the Netbox server runs version %s", api.netboxVersion)
+	}
+
+	groupID := int64(d.Get("group_id").(int))
+	name := d.Get("name").(string)
+	data := models.WritableCreateAvailableVLAN{
+		Name:        &name,
+		Status:      d.Get("status").(string),
+		Description: d.Get("description").(string),
+	}
+
+	if tenantID, ok := d.GetOk("tenant_id"); ok {
+		data.Tenant = int64ToPtr(int64(tenantID.(int)))
+	}
+
+	if roleID, ok := d.GetOk("role_id"); ok {
+		data.Role = int64ToPtr(int64(roleID.(int)))
+	}
+
+	if siteID, ok := d.GetOk("site_id"); ok {
+		data.Site = int64ToPtr(int64(siteID.(int)))
+	}
+
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	data.Tags = tags
+
+	// Allocating a list makes Netbox respond with a list, which is what the
+	// generated client expects
+	body := []*models.WritableCreateAvailableVLAN{&data}
+	params := ipam.NewIpamVlanGroupsAvailableVlansCreateParams().WithContext(ctx).WithID(groupID)
+
+	lockKey := allocationLockKey("vlan-group", groupID)
+	api.allocationLocks.Lock(lockKey)
+	res, err := api.Ipam.IpamVlanGroupsAvailableVlansCreate(params, nil, withRequestBody(params, body))
+	api.allocationLocks.Unlock(lockKey)
+	if err != nil {
+		return errorDiagnostics(availableVlanError(err, groupID))
+	}
+
+	payload := res.GetPayload()
+	if len(payload) == 0 {
+		return diag.Errorf("netbox did not return the allocated VLAN")
+	}
+	d.SetId(strconv.FormatInt(payload[0].ID, 10))
+
+	return resourceNetboxVlanRead(ctx, d, m)
+}
+
+// availableVlanError translates the response Netbox sends when the VLAN
+// group has no free VLAN ID left into a readable error.
+func availableVlanError(err error, groupID int64) error {
+	var apiError *runtime.APIError
+	if errors.As(err, &apiError) && apiError.IsCode(http.StatusNoContent) {
+		return fmt.Errorf("no VLAN ID available in VLAN group %d", groupID)
+	}
+	return err
+}
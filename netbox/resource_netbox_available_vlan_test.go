@@ -0,0 +1,121 @@
+package netbox
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccNetboxAvailableVlan_basic(t *testing.T) {
+
+	testSlug := "available_vlan"
+	testName := testAccGetTestName(testSlug)
+	resourceName := "netbox_available_vlan.test"
+	resource.ParallelTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPreCheckNetboxVersion(t, "3.2.0")
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "netbox_tag" "test" {
+  name = "%[1]s"
+}
+
+resource "netbox_vlan_group" "test" {
+  name = "%[1]s"
+  min_vid = 300
+  max_vid = 399
+}
+
+resource "netbox_vlan" "reserved" {
+  name = "%[1]s-reserved"
+  vid = 300
+  group_id = netbox_vlan_group.test.id
+  tags = []
+}
+
+resource "netbox_available_vlan" "test" {
+  depends_on = [netbox_vlan.reserved]
+  group_id = netbox_vlan_group.test.id
+  name = "%[1]s"
+  status = "active"
+  description = "%[1]s"
+  tags = [netbox_tag.test.name]
+}`, testName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "vid", "301"),
+					resource.TestCheckResourceAttr(resourceName, "name", testName),
+					resource.TestCheckResourceAttr(resourceName, "status", "active"),
+					resource.TestCheckResourceAttrPair(resourceName, "group_id", "netbox_vlan_group.test", "id"),
+					resource.TestCheckResourceAttr(resourceName, "tags.#", "1"),
+					resource.TestCheckResourceAttrPair("netbox_vlan.reserved", "group_id", "netbox_vlan_group.test", "id"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestUnitNetboxAvailableVlan_lifecycle(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	groupID := fake.Seed(t, "ipam/vlan-groups", map[string]interface{}{"name": "group", "slug": "group", "min_vid": 100, "max_vid": 102})
+	fake.Seed(t, "ipam/vlans", map[string]interface{}{"name": "reserved", "vid": 100, "group": groupID})
+	r := resourceNetboxAvailableVlan()
+
+	config := map[string]interface{}{
+		"group_id": int(groupID),
+		"name":     "vlan",
+		"status":   "reserved",
+	}
+	first, diags := testUnitApply(t, r, nil, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "101", first.Attributes["vid"])
+	assert.Equal(t, "reserved", first.Attributes["status"])
+	assert.Equal(t, fmt.Sprint(groupID), first.Attributes["group_id"])
+
+	second, diags := testUnitApply(t, r, nil, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "102", second.Attributes["vid"])
+
+	_, diags = testUnitApply(t, r, nil, config, api)
+	if assert.True(t, diags.HasError()) {
+		assert.Contains(t, diags[0].Summary, fmt.Sprintf("no VLAN ID available in VLAN group %d", groupID))
+	}
+
+	config["description"] = "renamed"
+	first, diags = testUnitApply(t, r, first, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "101", first.Attributes["vid"])
+	assert.Equal(t, "renamed", first.Attributes["description"])
+
+	_, diags = testUnitApply(t, r, first, nil, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, 2, fake.Count("ipam/vlans"))
+}
+
+func TestUnitNetboxAvailableVlan_requiresNetboxVersion(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	api.netboxVersion = version.Must(version.NewVersion("3.1.9"))
+	groupID := fake.Seed(t, "ipam/vlan-groups", map[string]interface{}{"name": "group", "slug": "group"})
+
+	_, diags := testUnitApply(t, resourceNetboxAvailableVlan(), nil, map[string]interface{}{
+		"group_id": int(groupID),
+		"name":     "vlan",
+	}, api)
+	if assert.True(t, diags.HasError()) {
+		assert.Equal(t, "netbox_available_vlan requires Netbox >= 3.2.0, the Netbox server runs version 3.1.9", diags[0].Summary)
+	}
+	assert.Equal(t, 0, fake.Count("ipam/vlans"))
+}
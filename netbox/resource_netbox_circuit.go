@@ -1,21 +1,22 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/circuits"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxCircuit() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxCircuitCreate,
-		Read:   resourceNetboxCircuitRead,
-		Update: resourceNetboxCircuitUpdate,
-		Delete: resourceNetboxCircuitDelete,
+		CreateContext: resourceNetboxCircuitCreate,
+		ReadContext:   resourceNetboxCircuitRead,
+		UpdateContext: resourceNetboxCircuitUpdate,
+		DeleteContext: resourceNetboxCircuitDelete,
 
 		Description: `From the [official documentation](https://docs.netbox.dev/en/stable/core-functionality/circuits/#circuits_1):
 
@@ -23,6 +24,8 @@ func resourceNetboxCircuit() *schema.Resource {
 > 
 > Each circuit is associated with a provider and a user-defined type. For example, you might have Internet access circuits delivered to each site by one provider, and private MPLS circuits delivered by another. Each circuit must be assigned a circuit ID, each of which must be unique per provider.`,
 
+		Timeouts: defaultTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"provider_id": &schema.Schema{
 				Type:     schema.TypeInt,
@@ -47,13 +50,13 @@ func resourceNetboxCircuit() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxCircuitCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCircuitCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	data := models.WritableCircuit{}
 
@@ -79,28 +82,31 @@ func resourceNetboxCircuitCreate(d *schema.ResourceData, m interface{}) error {
 
 	data.Tags = []*models.NestedTag{}
 
-	params := circuits.NewCircuitsCircuitsCreateParams().WithData(&data)
+	params := circuits.NewCircuitsCircuitsCreateParams().WithContext(ctx).WithData(&data)
 
 	res, err := api.Circuits.CircuitsCircuitsCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxCircuitRead(d, m)
+	return resourceNetboxCircuitRead(ctx, d, m)
 }
 
-func resourceNetboxCircuitRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCircuitRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := circuits.NewCircuitsCircuitsReadParams().WithID(id)
+	params := circuits.NewCircuitsCircuitsReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Circuits.CircuitsCircuitsRead(params, nil)
 
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("cid", res.GetPayload().Cid)
@@ -127,8 +133,8 @@ func resourceNetboxCircuitRead(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func resourceNetboxCircuitUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCircuitUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritableCircuit{}
@@ -155,25 +161,25 @@ func resourceNetboxCircuitUpdate(d *schema.ResourceData, m interface{}) error {
 
 	data.Tags = []*models.NestedTag{}
 
-	params := circuits.NewCircuitsCircuitsPartialUpdateParams().WithID(id).WithData(&data)
+	params := circuits.NewCircuitsCircuitsPartialUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
 	_, err := api.Circuits.CircuitsCircuitsPartialUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxCircuitRead(d, m)
+	return resourceNetboxCircuitRead(ctx, d, m)
 }
 
-func resourceNetboxCircuitDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCircuitDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := circuits.NewCircuitsCircuitsDeleteParams().WithID(id)
+	params := circuits.NewCircuitsCircuitsDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Circuits.CircuitsCircuitsDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
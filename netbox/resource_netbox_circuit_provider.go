@@ -1,21 +1,22 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/circuits"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxCircuitProvider() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxCircuitProviderCreate,
-		Read:   resourceNetboxCircuitProviderRead,
-		Update: resourceNetboxCircuitProviderUpdate,
-		Delete: resourceNetboxCircuitProviderDelete,
+		CreateContext: resourceNetboxCircuitProviderCreate,
+		ReadContext:   resourceNetboxCircuitProviderRead,
+		UpdateContext: resourceNetboxCircuitProviderUpdate,
+		DeleteContext: resourceNetboxCircuitProviderDelete,
 
 		Description: `From the [official documentation](https://docs.netbox.dev/en/stable/core-functionality/circuits/#providers):
 
@@ -23,6 +24,8 @@ func resourceNetboxCircuitProvider() *schema.Resource {
 >
 > Each provider may be assigned an autonomous system number (ASN), an account number, and contact information.`,
 
+		Timeouts: defaultTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
@@ -36,13 +39,13 @@ func resourceNetboxCircuitProvider() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxCircuitProviderCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCircuitProviderCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	data := models.WritableProvider{}
 
@@ -59,28 +62,31 @@ func resourceNetboxCircuitProviderCreate(d *schema.ResourceData, m interface{})
 
 	data.Tags = []*models.NestedTag{}
 
-	params := circuits.NewCircuitsProvidersCreateParams().WithData(&data)
+	params := circuits.NewCircuitsProvidersCreateParams().WithContext(ctx).WithData(&data)
 
 	res, err := api.Circuits.CircuitsProvidersCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxCircuitProviderRead(d, m)
+	return resourceNetboxCircuitProviderRead(ctx, d, m)
 }
 
-func resourceNetboxCircuitProviderRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCircuitProviderRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := circuits.NewCircuitsProvidersReadParams().WithID(id)
+	params := circuits.NewCircuitsProvidersReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Circuits.CircuitsProvidersRead(params, nil)
 
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("name", res.GetPayload().Name)
@@ -89,8 +95,8 @@ func resourceNetboxCircuitProviderRead(d *schema.ResourceData, m interface{}) er
 	return nil
 }
 
-func resourceNetboxCircuitProviderUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCircuitProviderUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritableProvider{}
@@ -108,25 +114,25 @@ func resourceNetboxCircuitProviderUpdate(d *schema.ResourceData, m interface{})
 
 	data.Tags = []*models.NestedTag{}
 
-	params := circuits.NewCircuitsProvidersPartialUpdateParams().WithID(id).WithData(&data)
+	params := circuits.NewCircuitsProvidersPartialUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
 	_, err := api.Circuits.CircuitsProvidersPartialUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxCircuitProviderRead(d, m)
+	return resourceNetboxCircuitProviderRead(ctx, d, m)
 }
 
-func resourceNetboxCircuitProviderDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCircuitProviderDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := circuits.NewCircuitsProvidersDeleteParams().WithID(id)
+	params := circuits.NewCircuitsProvidersDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Circuits.CircuitsProvidersDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
@@ -1,21 +1,22 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/circuits"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxCircuitTermination() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxCircuitTerminationCreate,
-		Read:   resourceNetboxCircuitTerminationRead,
-		Update: resourceNetboxCircuitTerminationUpdate,
-		Delete: resourceNetboxCircuitTerminationDelete,
+		CreateContext: resourceNetboxCircuitTerminationCreate,
+		ReadContext:   resourceNetboxCircuitTerminationRead,
+		UpdateContext: resourceNetboxCircuitTerminationUpdate,
+		DeleteContext: resourceNetboxCircuitTerminationDelete,
 
 		Description: `From the [official documentation](https://docs.netbox.dev/en/stable/core-functionality/circuits/#circuit-terminations):
 
@@ -23,6 +24,8 @@ func resourceNetboxCircuitTermination() *schema.Resource {
 >
 > Each circuit termination is attached to either a site or to a provider network. Site terminations may optionally be connected via a cable to a specific device interface or port within that site. Each termination must be assigned a port speed, and can optionally be assigned an upstream speed if it differs from the downstream speed (a common scenario with e.g. DOCSIS cable modems). Fields are also available to track cross-connect and patch panel details.`,
 
+		Timeouts: defaultTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"circuit_id": &schema.Schema{
 				Type:     schema.TypeInt,
@@ -47,13 +50,13 @@ func resourceNetboxCircuitTermination() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxCircuitTerminationCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCircuitTerminationCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	data := models.WritableCircuitTermination{}
 
@@ -80,28 +83,31 @@ func resourceNetboxCircuitTerminationCreate(d *schema.ResourceData, m interface{
 		data.UpstreamSpeed = int64ToPtr(int64(upstreamspeedValue.(int)))
 	}
 
-	params := circuits.NewCircuitsCircuitTerminationsCreateParams().WithData(&data)
+	params := circuits.NewCircuitsCircuitTerminationsCreateParams().WithContext(ctx).WithData(&data)
 
 	res, err := api.Circuits.CircuitsCircuitTerminationsCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxCircuitTerminationRead(d, m)
+	return resourceNetboxCircuitTerminationRead(ctx, d, m)
 }
 
-func resourceNetboxCircuitTerminationRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCircuitTerminationRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := circuits.NewCircuitsCircuitTerminationsReadParams().WithID(id)
+	params := circuits.NewCircuitsCircuitTerminationsReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Circuits.CircuitsCircuitTerminationsRead(params, nil)
 
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("term_side", res.GetPayload().TermSide)
@@ -133,8 +139,8 @@ func resourceNetboxCircuitTerminationRead(d *schema.ResourceData, m interface{})
 	return nil
 }
 
-func resourceNetboxCircuitTerminationUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCircuitTerminationUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritableCircuitTermination{}
@@ -161,25 +167,25 @@ func resourceNetboxCircuitTerminationUpdate(d *schema.ResourceData, m interface{
 	if ok {
 		data.UpstreamSpeed = int64ToPtr(int64(upstreamspeedValue.(int)))
 	}
-	params := circuits.NewCircuitsCircuitTerminationsPartialUpdateParams().WithID(id).WithData(&data)
+	params := circuits.NewCircuitsCircuitTerminationsPartialUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
 	_, err := api.Circuits.CircuitsCircuitTerminationsPartialUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxCircuitTerminationRead(d, m)
+	return resourceNetboxCircuitTerminationRead(ctx, d, m)
 }
 
-func resourceNetboxCircuitTerminationDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCircuitTerminationDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := circuits.NewCircuitsCircuitTerminationsDeleteParams().WithID(id)
+	params := circuits.NewCircuitsCircuitTerminationsDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Circuits.CircuitsCircuitTerminationsDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
@@ -63,6 +63,52 @@ resource "netbox_circuit_termination" "test" {
 	})
 }
 
+func TestAccNetboxCircuitTermination_deletedOutOfBand(t *testing.T) {
+	testSlug := "circuit_term_oob"
+	testName := testAccGetTestName(testSlug)
+	randomSlug := testAccGetTestName(testSlug)
+	resource.ParallelTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		PreCheck:  func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "netbox_site" "test" {
+	name = "%[1]s"
+	slug = "%[2]s"
+	status = "active"
+}
+resource "netbox_circuit_provider" "test" {
+	name = "%[1]s"
+	slug = "%[2]s"
+}
+resource "netbox_circuit_type" "test" {
+	name = "%[1]s"
+	slug = "%[2]s"
+}
+resource "netbox_circuit" "test" {
+  cid = "%[1]s"
+  status = "active"
+  provider_id = netbox_circuit_provider.test.id
+  type_id = netbox_circuit_type.test.id
+}
+resource "netbox_circuit_termination" "test" {
+	circuit_id = netbox_circuit.test.id
+	term_side = "A"
+	site_id = netbox_site.test.id
+	port_speed = 100000
+	upstream_speed = 50000
+  }`, testName, randomSlug),
+				Check: testAccDeleteOutOfBand("netbox_circuit_termination.test", func(api *providerState, id int64) error {
+					_, err := api.Circuits.CircuitsCircuitTerminationsDelete(circuits.NewCircuitsCircuitTerminationsDeleteParams().WithID(id), nil)
+					return err
+				}),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
 func init() {
 	resource.AddTestSweepers("netbox_circuit_termination", &resource.Sweeper{
 		Name:         "netbox_circuit_termination",
@@ -1,26 +1,29 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/circuits"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxCircuitType() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxCircuitTypeCreate,
-		Read:   resourceNetboxCircuitTypeRead,
-		Update: resourceNetboxCircuitTypeUpdate,
-		Delete: resourceNetboxCircuitTypeDelete,
+		CreateContext: resourceNetboxCircuitTypeCreate,
+		ReadContext:   resourceNetboxCircuitTypeRead,
+		UpdateContext: resourceNetboxCircuitTypeUpdate,
+		DeleteContext: resourceNetboxCircuitTypeDelete,
 
 		Description: `From the [official documentation](https://docs.netbox.dev/en/stable/core-functionality/circuits/#circuit-types):
 
 > Circuits are classified by functional type. These types are completely customizable, and are typically used to convey the type of service being delivered over a circuit.`,
 
+		Timeouts: defaultTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
@@ -34,13 +37,13 @@ func resourceNetboxCircuitType() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxCircuitTypeCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCircuitTypeCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	data := models.CircuitType{}
 
@@ -55,28 +58,31 @@ func resourceNetboxCircuitTypeCreate(d *schema.ResourceData, m interface{}) erro
 		data.Slug = strToPtr(slugValue.(string))
 	}
 
-	params := circuits.NewCircuitsCircuitTypesCreateParams().WithData(&data)
+	params := circuits.NewCircuitsCircuitTypesCreateParams().WithContext(ctx).WithData(&data)
 
 	res, err := api.Circuits.CircuitsCircuitTypesCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxCircuitTypeRead(d, m)
+	return resourceNetboxCircuitTypeRead(ctx, d, m)
 }
 
-func resourceNetboxCircuitTypeRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCircuitTypeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := circuits.NewCircuitsCircuitTypesReadParams().WithID(id)
+	params := circuits.NewCircuitsCircuitTypesReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Circuits.CircuitsCircuitTypesRead(params, nil)
 
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("name", res.GetPayload().Name)
@@ -85,8 +91,8 @@ func resourceNetboxCircuitTypeRead(d *schema.ResourceData, m interface{}) error
 	return nil
 }
 
-func resourceNetboxCircuitTypeUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCircuitTypeUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.CircuitType{}
@@ -102,25 +108,25 @@ func resourceNetboxCircuitTypeUpdate(d *schema.ResourceData, m interface{}) erro
 		data.Slug = strToPtr(slugValue.(string))
 	}
 
-	params := circuits.NewCircuitsCircuitTypesPartialUpdateParams().WithID(id).WithData(&data)
+	params := circuits.NewCircuitsCircuitTypesPartialUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
 	_, err := api.Circuits.CircuitsCircuitTypesPartialUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxCircuitTypeRead(d, m)
+	return resourceNetboxCircuitTypeRead(ctx, d, m)
 }
 
-func resourceNetboxCircuitTypeDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCircuitTypeDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := circuits.NewCircuitsCircuitTypesDeleteParams().WithID(id)
+	params := circuits.NewCircuitsCircuitTypesDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Circuits.CircuitsCircuitTypesDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
@@ -1,20 +1,22 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/virtualization"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxCluster() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxClusterCreate,
-		Read:   resourceNetboxClusterRead,
-		Update: resourceNetboxClusterUpdate,
-		Delete: resourceNetboxClusterDelete,
+		CreateContext: resourceNetboxClusterCreate,
+		ReadContext:   resourceNetboxClusterRead,
+		UpdateContext: resourceNetboxClusterUpdate,
+		DeleteContext: resourceNetboxClusterDelete,
+		CustomizeDiff: customizeDiffTagsAll,
 
 		Description: `From the [official documentation](https://docs.netbox.dev/en/stable/core-functionality/virtualization/#clusters):
 
@@ -22,6 +24,8 @@ func resourceNetboxCluster() *schema.Resource {
 >
 > Physical devices may be associated with clusters as hosts. This allows users to track on which host(s) a particular virtual machine may reside. However, NetBox does not support pinning a specific VM within a cluster to a particular host device.`,
 
+		Timeouts: defaultTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
@@ -47,15 +51,16 @@ func resourceNetboxCluster() *schema.Resource {
 				Optional: true,
 				Set:      schema.HashString,
 			},
+			"tags_all": tagsAllSchema,
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxClusterCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxClusterCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	data := models.WritableCluster{}
 
@@ -75,31 +80,37 @@ func resourceNetboxClusterCreate(d *schema.ResourceData, m interface{}) error {
 		data.Site = &siteID
 	}
 
-	tags, _ := getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
 	data.Tags = tags
 
-	params := virtualization.NewVirtualizationClustersCreateParams().WithData(&data)
+	params := virtualization.NewVirtualizationClustersCreateParams().WithContext(ctx).WithData(&data)
 
 	res, err := api.Virtualization.VirtualizationClustersCreate(params, nil)
 	if err != nil {
 		//return errors.New(getTextFromError(err))
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxClusterRead(d, m)
+	return resourceNetboxClusterRead(ctx, d, m)
 }
 
-func resourceNetboxClusterRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxClusterRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := virtualization.NewVirtualizationClustersReadParams().WithID(id)
+	params := virtualization.NewVirtualizationClustersReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Virtualization.VirtualizationClustersRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("name", res.GetPayload().Name)
@@ -117,12 +128,12 @@ func resourceNetboxClusterRead(d *schema.ResourceData, m interface{}) error {
 		d.Set("site_id", nil)
 	}
 
-	d.Set("tags", getTagListFromNestedTagList(res.GetPayload().Tags))
+	setTagsFromNestedTagList(api, d, res.GetPayload().Tags)
 	return nil
 }
 
-func resourceNetboxClusterUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxClusterUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritableCluster{}
@@ -143,28 +154,31 @@ func resourceNetboxClusterUpdate(d *schema.ResourceData, m interface{}) error {
 		data.Site = &siteID
 	}
 
-	tags, _ := getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
 	data.Tags = tags
 
-	params := virtualization.NewVirtualizationClustersPartialUpdateParams().WithID(id).WithData(&data)
+	params := virtualization.NewVirtualizationClustersPartialUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
-	_, err := api.Virtualization.VirtualizationClustersPartialUpdate(params, nil)
+	_, err = api.Virtualization.VirtualizationClustersPartialUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxClusterRead(d, m)
+	return resourceNetboxClusterRead(ctx, d, m)
 }
 
-func resourceNetboxClusterDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxClusterDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := virtualization.NewVirtualizationClustersDeleteParams().WithID(id)
+	params := virtualization.NewVirtualizationClustersDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Virtualization.VirtualizationClustersDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
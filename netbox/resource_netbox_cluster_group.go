@@ -1,26 +1,29 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/virtualization"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxClusterGroup() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxClusterGroupCreate,
-		Read:   resourceNetboxClusterGroupRead,
-		Update: resourceNetboxClusterGroupUpdate,
-		Delete: resourceNetboxClusterGroupDelete,
+		CreateContext: resourceNetboxClusterGroupCreate,
+		ReadContext:   resourceNetboxClusterGroupRead,
+		UpdateContext: resourceNetboxClusterGroupUpdate,
+		DeleteContext: resourceNetboxClusterGroupDelete,
 
 		Description: `From the [official documentation](https://docs.netbox.dev/en/stable/core-functionality/virtualization/#cluster-groups):
 
 > Cluster groups may be created for the purpose of organizing clusters. The arrangement of clusters into groups is optional.`,
 
+		Timeouts: defaultTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
@@ -38,13 +41,13 @@ func resourceNetboxClusterGroup() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxClusterGroupCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxClusterGroupCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	data := models.ClusterGroup{}
 
@@ -65,27 +68,30 @@ func resourceNetboxClusterGroupCreate(d *schema.ResourceData, m interface{}) err
 		data.Description = description.(string)
 	}
 
-	params := virtualization.NewVirtualizationClusterGroupsCreateParams().WithData(&data)
+	params := virtualization.NewVirtualizationClusterGroupsCreateParams().WithContext(ctx).WithData(&data)
 
 	res, err := api.Virtualization.VirtualizationClusterGroupsCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxClusterGroupRead(d, m)
+	return resourceNetboxClusterGroupRead(ctx, d, m)
 }
 
-func resourceNetboxClusterGroupRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxClusterGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := virtualization.NewVirtualizationClusterGroupsReadParams().WithID(id)
+	params := virtualization.NewVirtualizationClusterGroupsReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Virtualization.VirtualizationClusterGroupsRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("name", res.GetPayload().Name)
@@ -94,8 +100,8 @@ func resourceNetboxClusterGroupRead(d *schema.ResourceData, m interface{}) error
 	return nil
 }
 
-func resourceNetboxClusterGroupUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxClusterGroupUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.ClusterGroup{}
@@ -122,25 +128,25 @@ func resourceNetboxClusterGroupUpdate(d *schema.ResourceData, m interface{}) err
 		}
 	}
 
-	params := virtualization.NewVirtualizationClusterGroupsPartialUpdateParams().WithID(id).WithData(&data)
+	params := virtualization.NewVirtualizationClusterGroupsPartialUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
 	_, err := api.Virtualization.VirtualizationClusterGroupsPartialUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxClusterGroupRead(d, m)
+	return resourceNetboxClusterGroupRead(ctx, d, m)
 }
 
-func resourceNetboxClusterGroupDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxClusterGroupDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := virtualization.NewVirtualizationClusterGroupsDeleteParams().WithID(id)
+	params := virtualization.NewVirtualizationClusterGroupsDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Virtualization.VirtualizationClusterGroupsDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
@@ -1,25 +1,28 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/virtualization"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxClusterType() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxClusterTypeCreate,
-		Read:   resourceNetboxClusterTypeRead,
-		Update: resourceNetboxClusterTypeUpdate,
-		Delete: resourceNetboxClusterTypeDelete,
+		CreateContext: resourceNetboxClusterTypeCreate,
+		ReadContext:   resourceNetboxClusterTypeRead,
+		UpdateContext: resourceNetboxClusterTypeUpdate,
+		DeleteContext: resourceNetboxClusterTypeDelete,
 
 		Description: `From the [official documentation](https://docs.netbox.dev/en/stable/core-functionality/virtualization/#cluster-types):
 
 > A cluster type represents a technology or mechanism by which a cluster is formed. For example, you might create a cluster type named "VMware vSphere" for a locally hosted cluster or "DigitalOcean NYC3" for one hosted by a cloud provider.`,
 
+		Timeouts: defaultTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
@@ -32,13 +35,13 @@ func resourceNetboxClusterType() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxClusterTypeCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxClusterTypeCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	name := d.Get("name").(string)
 	slugValue, slugOk := d.GetOk("slug")
@@ -51,7 +54,7 @@ func resourceNetboxClusterTypeCreate(d *schema.ResourceData, m interface{}) erro
 		slug = slugValue.(string)
 	}
 
-	params := virtualization.NewVirtualizationClusterTypesCreateParams().WithData(
+	params := virtualization.NewVirtualizationClusterTypesCreateParams().WithContext(ctx).WithData(
 		&models.ClusterType{
 			Name: &name,
 			Slug: &slug,
@@ -61,23 +64,26 @@ func resourceNetboxClusterTypeCreate(d *schema.ResourceData, m interface{}) erro
 	res, err := api.Virtualization.VirtualizationClusterTypesCreate(params, nil)
 	if err != nil {
 		//return errors.New(getTextFromError(err))
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxClusterTypeRead(d, m)
+	return resourceNetboxClusterTypeRead(ctx, d, m)
 }
 
-func resourceNetboxClusterTypeRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxClusterTypeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := virtualization.NewVirtualizationClusterTypesReadParams().WithID(id)
+	params := virtualization.NewVirtualizationClusterTypesReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Virtualization.VirtualizationClusterTypesRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("name", res.GetPayload().Name)
@@ -85,8 +91,8 @@ func resourceNetboxClusterTypeRead(d *schema.ResourceData, m interface{}) error
 	return nil
 }
 
-func resourceNetboxClusterTypeUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxClusterTypeUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.ClusterType{}
@@ -105,25 +111,25 @@ func resourceNetboxClusterTypeUpdate(d *schema.ResourceData, m interface{}) erro
 	data.Slug = &slug
 	data.Name = &name
 
-	params := virtualization.NewVirtualizationClusterTypesPartialUpdateParams().WithID(id).WithData(&data)
+	params := virtualization.NewVirtualizationClusterTypesPartialUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
 	_, err := api.Virtualization.VirtualizationClusterTypesPartialUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxClusterTypeRead(d, m)
+	return resourceNetboxClusterTypeRead(ctx, d, m)
 }
 
-func resourceNetboxClusterTypeDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxClusterTypeDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := virtualization.NewVirtualizationClusterTypesDeleteParams().WithID(id)
+	params := virtualization.NewVirtualizationClusterTypesDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Virtualization.VirtualizationClusterTypesDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
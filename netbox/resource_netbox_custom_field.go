@@ -1,22 +1,24 @@
 package netbox
 
 import (
-	"fmt"
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/extras"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceCustomField() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxCustomFieldCreate,
-		Read:   resourceNetboxCustomFieldRead,
-		Update: resourceNetboxCustomFieldUpdate,
-		Delete: resourceNetboxCustomFieldDelete,
+		CreateContext: resourceNetboxCustomFieldCreate,
+		ReadContext:   resourceNetboxCustomFieldRead,
+		UpdateContext: resourceNetboxCustomFieldUpdate,
+		DeleteContext: resourceNetboxCustomFieldDelete,
+
+		Timeouts: defaultTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -89,13 +91,13 @@ func resourceCustomField() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxCustomFieldUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCustomFieldUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 
@@ -112,7 +114,7 @@ func resourceNetboxCustomFieldUpdate(d *schema.ResourceData, m interface{}) erro
 	choices, ok := d.GetOk("choices")
 	if ok {
 		if data.Type != "select" && data.Type != "multiselect" {
-			return fmt.Errorf("choices may be set only for custom selection fields")
+			return diag.Errorf("choices may be set only for custom selection fields")
 		}
 		for _, choice := range choices.(*schema.Set).List() {
 			data.Choices = append(data.Choices, choice.(string))
@@ -135,19 +137,19 @@ func resourceNetboxCustomFieldUpdate(d *schema.ResourceData, m interface{}) erro
 		data.ValidationMinimum = int64ToPtr(int64(vmin.(int)))
 	}
 
-	params := extras.NewExtrasCustomFieldsUpdateParams().WithID(id).WithData(data)
+	params := extras.NewExtrasCustomFieldsUpdateParams().WithContext(ctx).WithID(id).WithData(data)
 	res, err := api.Extras.ExtrasCustomFieldsUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxCustomFieldRead(d, m)
+	return resourceNetboxCustomFieldRead(ctx, d, m)
 }
 
-func resourceNetboxCustomFieldCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCustomFieldCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	data := &models.WritableCustomField{
 		Name:            strToPtr(d.Get("name").(string)),
@@ -162,7 +164,7 @@ func resourceNetboxCustomFieldCreate(d *schema.ResourceData, m interface{}) erro
 	choices, ok := d.GetOk("choices")
 	if ok {
 		if data.Type != "select" && data.Type != "multiselect" {
-			return fmt.Errorf("choices may be set only for custom selection fields")
+			return diag.Errorf("choices may be set only for custom selection fields")
 		}
 		for _, choice := range choices.(*schema.Set).List() {
 			data.Choices = append(data.Choices, choice.(string))
@@ -185,53 +187,57 @@ func resourceNetboxCustomFieldCreate(d *schema.ResourceData, m interface{}) erro
 		data.ValidationMinimum = int64ToPtr(int64(vmin.(int)))
 	}
 
-	params := extras.NewExtrasCustomFieldsCreateParams().WithData(data)
+	params := extras.NewExtrasCustomFieldsCreateParams().WithContext(ctx).WithData(data)
 
 	res, err := api.Extras.ExtrasCustomFieldsCreate(params, nil)
 	if err != nil {
 		//return errors.New(getTextFromError(err))
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxCustomFieldRead(d, m)
+	return resourceNetboxCustomFieldRead(ctx, d, m)
 }
 
-func resourceNetboxCustomFieldRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCustomFieldRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := extras.NewExtrasCustomFieldsReadParams().WithID(id)
+	params := extras.NewExtrasCustomFieldsReadParams().WithContext(ctx).WithID(id)
 	res, err := api.Extras.ExtrasCustomFieldsRead(params, nil)
 	if err != nil {
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	err = d.Set("name", res.GetPayload().Name)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	err = d.Set("type", *res.GetPayload().Type.Value)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	err = d.Set("content_types", res.GetPayload().ContentTypes)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	choices := res.GetPayload().Choices
 	if choices != nil {
 		err = d.Set("choices", res.GetPayload().Choices)
 		if err != nil {
-			return err
+			return errorDiagnostics(err)
 		}
 	}
 
 	err = d.Set("weight", res.GetPayload().Weight)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	if res.GetPayload().Default != nil {
 		d.Set("default", *res.GetPayload().Default)
@@ -239,37 +245,37 @@ func resourceNetboxCustomFieldRead(d *schema.ResourceData, m interface{}) error
 
 	err = d.Set("description", res.GetPayload().Description)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	err = d.Set("label", res.GetPayload().Label)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	err = d.Set("required", res.GetPayload().Required)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	err = d.Set("validation_maximum", res.GetPayload().ValidationMaximum)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	err = d.Set("validation_minimum", res.GetPayload().ValidationMinimum)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	err = d.Set("validation_regex", res.GetPayload().ValidationRegex)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	return nil
 }
 
-func resourceNetboxCustomFieldDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxCustomFieldDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := extras.NewExtrasCustomFieldsDeleteParams().WithID(id)
+	params := extras.NewExtrasCustomFieldsDeleteParams().WithContext(ctx).WithID(id)
 	_, err := api.Extras.ExtrasCustomFieldsDelete(params, nil)
-	return err
+	return errorDiagnostics(err)
 }
@@ -6,7 +6,6 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/dcim"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
@@ -17,11 +16,14 @@ func resourceNetboxDevice() *schema.Resource {
 		ReadContext:   resourceNetboxDeviceRead,
 		UpdateContext: resourceNetboxDeviceUpdate,
 		DeleteContext: resourceNetboxDeviceDelete,
+		CustomizeDiff: customizeDiffTagsAll,
 
 		Description: `From the [official documentation](https://docs.netbox.dev/en/stable/core-functionality/devices/#devices):
 
 > Every piece of hardware which is installed within a site or rack exists in NetBox as a device. Devices are measured in rack units (U) and can be half depth or full depth. A device may have a height of 0U: These devices do not consume vertical rack space and cannot be assigned to a particular rack unit. A common example of a 0U device is a vertically-mounted PDU.`,
 
+		Timeouts: configContextTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
@@ -59,19 +61,20 @@ func resourceNetboxDevice() *schema.Resource {
 				Optional: true,
 				Set:      schema.HashString,
 			},
+			"tags_all": tagsAllSchema,
 			"primary_ipv4": &schema.Schema{
 				Type:     schema.TypeInt,
 				Computed: true,
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
 func resourceNetboxDeviceCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	api := m.(*client.NetBoxAPI)
+	api := m.(*providerState)
 
 	name := d.Get("name").(string)
 
@@ -109,13 +112,17 @@ func resourceNetboxDeviceCreate(ctx context.Context, d *schema.ResourceData, m i
 		data.Site = &siteID
 	}
 
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	data.Tags = tags
 
-	params := dcim.NewDcimDevicesCreateParams().WithData(&data)
+	params := dcim.NewDcimDevicesCreateParams().WithContext(ctx).WithData(&data)
 
 	res, err := api.Dcim.DcimDevicesCreate(params, nil)
 	if err != nil {
-		return diag.FromErr(err)
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
@@ -124,17 +131,21 @@ func resourceNetboxDeviceCreate(ctx context.Context, d *schema.ResourceData, m i
 }
 
 func resourceNetboxDeviceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	api := m.(*client.NetBoxAPI)
+	api := m.(*providerState)
 
 	var diags diag.Diagnostics
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 
-	params := dcim.NewDcimDevicesReadParams().WithID(id)
+	params := dcim.NewDcimDevicesReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Dcim.DcimDevicesRead(params, nil)
 	if err != nil {
-		return diag.FromErr(err)
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("name", res.GetPayload().Name)
@@ -171,12 +182,12 @@ func resourceNetboxDeviceRead(ctx context.Context, d *schema.ResourceData, m int
 
 	d.Set("serial", res.GetPayload().Serial)
 
-	d.Set("tags", getTagListFromNestedTagList(res.GetPayload().Tags))
+	setTagsFromNestedTagList(api, d, res.GetPayload().Tags)
 	return diags
 }
 
 func resourceNetboxDeviceUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	api := m.(*client.NetBoxAPI)
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritableDeviceWithConfigContext{}
@@ -223,7 +234,11 @@ func resourceNetboxDeviceUpdate(ctx context.Context, d *schema.ResourceData, m i
 		data.PrimaryIp4 = &primaryIP
 	}
 
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	data.Tags = tags
 
 	if d.HasChanges("comments") {
 		// check if comment is set
@@ -251,27 +266,27 @@ func resourceNetboxDeviceUpdate(ctx context.Context, d *schema.ResourceData, m i
 		data.Serial = serial
 	}
 
-	params := dcim.NewDcimDevicesUpdateParams().WithID(id).WithData(&data)
+	params := dcim.NewDcimDevicesUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
-	_, err := api.Dcim.DcimDevicesUpdate(params, nil)
+	_, err = api.Dcim.DcimDevicesUpdate(params, nil)
 	if err != nil {
-		return diag.FromErr(err)
+		return errorDiagnostics(err)
 	}
 
 	return resourceNetboxDeviceRead(ctx, d, m)
 }
 
 func resourceNetboxDeviceDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	api := m.(*client.NetBoxAPI)
+	api := m.(*providerState)
 
 	var diags diag.Diagnostics
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := dcim.NewDcimDevicesDeleteParams().WithID(id)
+	params := dcim.NewDcimDevicesDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Dcim.DcimDevicesDelete(params, nil)
 	if err != nil {
-		return diag.FromErr(err)
+		return errorDiagnostics(err)
 	}
 	return diags
 }
@@ -1,25 +1,28 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/dcim"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxDeviceRole() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxDeviceRoleCreate,
-		Read:   resourceNetboxDeviceRoleRead,
-		Update: resourceNetboxDeviceRoleUpdate,
-		Delete: resourceNetboxDeviceRoleDelete,
+		CreateContext: resourceNetboxDeviceRoleCreate,
+		ReadContext:   resourceNetboxDeviceRoleRead,
+		UpdateContext: resourceNetboxDeviceRoleUpdate,
+		DeleteContext: resourceNetboxDeviceRoleDelete,
 
 		Description: `From the [official documentation](https://docs.netbox.dev/en/stable/core-functionality/devices/#device-roles):
 
 > Devices can be organized by functional roles, which are fully customizable by the user. For example, you might create roles for core switches, distribution switches, and access switches within your network.`,
 
+		Timeouts: defaultTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
@@ -41,13 +44,13 @@ func resourceNetboxDeviceRole() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxDeviceRoleCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxDeviceRoleCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	name := d.Get("name").(string)
 	slugValue, slugOk := d.GetOk("slug")
@@ -63,7 +66,7 @@ func resourceNetboxDeviceRoleCreate(d *schema.ResourceData, m interface{}) error
 	color := d.Get("color_hex").(string)
 	vmRole := d.Get("vm_role").(bool)
 
-	params := dcim.NewDcimDeviceRolesCreateParams().WithData(
+	params := dcim.NewDcimDeviceRolesCreateParams().WithContext(ctx).WithData(
 		&models.DeviceRole{
 			Name:   &name,
 			Slug:   &slug,
@@ -75,23 +78,26 @@ func resourceNetboxDeviceRoleCreate(d *schema.ResourceData, m interface{}) error
 	res, err := api.Dcim.DcimDeviceRolesCreate(params, nil)
 	if err != nil {
 		//return errors.New(getTextFromError(err))
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxDeviceRoleRead(d, m)
+	return resourceNetboxDeviceRoleRead(ctx, d, m)
 }
 
-func resourceNetboxDeviceRoleRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxDeviceRoleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := dcim.NewDcimDeviceRolesReadParams().WithID(id)
+	params := dcim.NewDcimDeviceRolesReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Dcim.DcimDeviceRolesRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("name", res.GetPayload().Name)
@@ -101,8 +107,8 @@ func resourceNetboxDeviceRoleRead(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func resourceNetboxDeviceRoleUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxDeviceRoleUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.DeviceRole{}
@@ -126,25 +132,25 @@ func resourceNetboxDeviceRoleUpdate(d *schema.ResourceData, m interface{}) error
 	data.VMRole = vmRole
 	data.Color = color
 
-	params := dcim.NewDcimDeviceRolesPartialUpdateParams().WithID(id).WithData(&data)
+	params := dcim.NewDcimDeviceRolesPartialUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
 	_, err := api.Dcim.DcimDeviceRolesPartialUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxDeviceRoleRead(d, m)
+	return resourceNetboxDeviceRoleRead(ctx, d, m)
 }
 
-func resourceNetboxDeviceRoleDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxDeviceRoleDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := dcim.NewDcimDeviceRolesDeleteParams().WithID(id)
+	params := dcim.NewDcimDeviceRolesDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Dcim.DcimDeviceRolesDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
@@ -90,7 +90,7 @@ resource "netbox_device" "test" {
 
 func testAccCheckDeviceDestroy(s *terraform.State) error {
 	// retrieve the connection established in Provider configuration
-	conn := testAccProvider.Meta().(*client.NetBoxAPI)
+	conn := testAccProvider.Meta().(*providerState)
 
 	// loop through the resources in state, verifying each device
 	// is destroyed
@@ -1,21 +1,25 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/dcim"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxDeviceType() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxDeviceTypeCreate,
-		Read:   resourceNetboxDeviceTypeRead,
-		Update: resourceNetboxDeviceTypeUpdate,
-		Delete: resourceNetboxDeviceTypeDelete,
+		CreateContext: resourceNetboxDeviceTypeCreate,
+		ReadContext:   resourceNetboxDeviceTypeRead,
+		UpdateContext: resourceNetboxDeviceTypeUpdate,
+		DeleteContext: resourceNetboxDeviceTypeDelete,
+		CustomizeDiff: customizeDiffTagsAll,
+
+		Timeouts: defaultTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"model": &schema.Schema{
@@ -40,15 +44,16 @@ func resourceNetboxDeviceType() *schema.Resource {
 				Optional: true,
 				Set:      schema.HashString,
 			},
+			"tags_all": tagsAllSchema,
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxDeviceTypeCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxDeviceTypeCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	data := models.WritableDeviceType{}
 
@@ -68,42 +73,49 @@ func resourceNetboxDeviceTypeCreate(d *schema.ResourceData, m interface{}) error
 		data.Manufacturer = int64ToPtr(int64(manufacturerIDValue.(int)))
 	}
 
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	data.Tags = tags
 
-	params := dcim.NewDcimDeviceTypesCreateParams().WithData(&data)
+	params := dcim.NewDcimDeviceTypesCreateParams().WithContext(ctx).WithData(&data)
 
 	res, err := api.Dcim.DcimDeviceTypesCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxDeviceTypeRead(d, m)
+	return resourceNetboxDeviceTypeRead(ctx, d, m)
 }
 
-func resourceNetboxDeviceTypeRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxDeviceTypeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := dcim.NewDcimDeviceTypesReadParams().WithID(id)
+	params := dcim.NewDcimDeviceTypesReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Dcim.DcimDeviceTypesRead(params, nil)
 
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("model", res.GetPayload().Model)
 	d.Set("slug", res.GetPayload().Slug)
 	d.Set("manufacturer_id", res.GetPayload().Manufacturer.ID)
-	d.Set("tags", getTagListFromNestedTagList(res.GetPayload().Tags))
+	setTagsFromNestedTagList(api, d, res.GetPayload().Tags)
 
 	return nil
 }
 
-func resourceNetboxDeviceTypeUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxDeviceTypeUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritableDeviceType{}
@@ -124,27 +136,31 @@ func resourceNetboxDeviceTypeUpdate(d *schema.ResourceData, m interface{}) error
 		data.Manufacturer = int64ToPtr(int64(manufacturerIDValue.(int)))
 	}
 
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	data.Tags = tags
 
-	params := dcim.NewDcimDeviceTypesPartialUpdateParams().WithID(id).WithData(&data)
+	params := dcim.NewDcimDeviceTypesPartialUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
-	_, err := api.Dcim.DcimDeviceTypesPartialUpdate(params, nil)
+	_, err = api.Dcim.DcimDeviceTypesPartialUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxDeviceTypeRead(d, m)
+	return resourceNetboxDeviceTypeRead(ctx, d, m)
 }
 
-func resourceNetboxDeviceTypeDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxDeviceTypeDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := dcim.NewDcimDeviceTypesDeleteParams().WithID(id)
+	params := dcim.NewDcimDeviceTypesDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Dcim.DcimDeviceTypesDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
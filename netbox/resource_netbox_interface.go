@@ -1,22 +1,26 @@
 package netbox
 
 import (
+	"context"
 	"regexp"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/virtualization"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxInterface() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxInterfaceCreate,
-		Read:   resourceNetboxInterfaceRead,
-		Update: resourceNetboxInterfaceUpdate,
-		Delete: resourceNetboxInterfaceDelete,
+		CreateContext: resourceNetboxInterfaceCreate,
+		ReadContext:   resourceNetboxInterfaceRead,
+		UpdateContext: resourceNetboxInterfaceUpdate,
+		DeleteContext: resourceNetboxInterfaceDelete,
+		CustomizeDiff: customizeDiffTagsAll,
+
+		Timeouts: defaultTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
@@ -52,21 +56,25 @@ func resourceNetboxInterface() *schema.Resource {
 				Optional: true,
 				Set:      schema.HashString,
 			},
+			"tags_all": tagsAllSchema,
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxInterfaceCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxInterfaceCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	name := d.Get("name").(string)
 	virtualMachineID := int64(d.Get("virtual_machine_id").(int))
 	description := d.Get("description").(string)
 	macAddress := d.Get("mac_address").(string)
-	tags, _ := getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
 
 	data := models.WritableVMInterface{
 		Name:           &name,
@@ -78,47 +86,53 @@ func resourceNetboxInterfaceCreate(d *schema.ResourceData, m interface{}) error
 	if macAddress != "" {
 		data.MacAddress = &macAddress
 	}
-	params := virtualization.NewVirtualizationInterfacesCreateParams().WithData(&data)
+	params := virtualization.NewVirtualizationInterfacesCreateParams().WithContext(ctx).WithData(&data)
 
 	res, err := api.Virtualization.VirtualizationInterfacesCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxInterfaceUpdate(d, m)
+	return resourceNetboxInterfaceUpdate(ctx, d, m)
 }
 
-func resourceNetboxInterfaceRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxInterfaceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 
-	params := virtualization.NewVirtualizationInterfacesReadParams().WithID(id)
+	params := virtualization.NewVirtualizationInterfacesReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Virtualization.VirtualizationInterfacesRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("name", res.GetPayload().Name)
 	d.Set("virtual_machine_id", res.GetPayload().VirtualMachine.ID)
 	d.Set("description", res.GetPayload().Description)
 	d.Set("mac_address", res.GetPayload().MacAddress)
-	d.Set("tags", getTagListFromNestedTagList(res.GetPayload().Tags))
+	setTagsFromNestedTagList(api, d, res.GetPayload().Tags)
 	return nil
 }
 
-func resourceNetboxInterfaceUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxInterfaceUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 
 	name := d.Get("name").(string)
 	virtualMachineID := int64(d.Get("virtual_machine_id").(int))
 	description := d.Get("description").(string)
-	tags, _ := getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
 
 	data := models.WritableVMInterface{
 		Name:           &name,
@@ -128,28 +142,28 @@ func resourceNetboxInterfaceUpdate(d *schema.ResourceData, m interface{}) error
 		TaggedVlans:    []int64{},
 	}
 
-	params := virtualization.NewVirtualizationInterfacesPartialUpdateParams().WithID(id).WithData(&data)
+	params := virtualization.NewVirtualizationInterfacesPartialUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 	if d.HasChange("mac_address") {
 		macAddress := d.Get("mac_address").(string)
 		data.MacAddress = &macAddress
 	}
-	_, err := api.Virtualization.VirtualizationInterfacesPartialUpdate(params, nil)
+	_, err = api.Virtualization.VirtualizationInterfacesPartialUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxInterfaceRead(d, m)
+	return resourceNetboxInterfaceRead(ctx, d, m)
 }
 
-func resourceNetboxInterfaceDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxInterfaceDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := virtualization.NewVirtualizationInterfacesDeleteParams().WithID(id)
+	params := virtualization.NewVirtualizationInterfacesDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Virtualization.VirtualizationInterfacesDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
@@ -1,21 +1,27 @@
 package netbox
 
 import (
+	"context"
+	"fmt"
+	"net"
 	"strconv"
+	"strings"
 
+	"github.com/go-openapi/runtime"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxIPAddress() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxIPAddressCreate,
-		Read:   resourceNetboxIPAddressRead,
-		Update: resourceNetboxIPAddressUpdate,
-		Delete: resourceNetboxIPAddressDelete,
+		CreateContext: resourceNetboxIPAddressCreate,
+		ReadContext:   resourceNetboxIPAddressRead,
+		UpdateContext: resourceNetboxIPAddressUpdate,
+		DeleteContext: resourceNetboxIPAddressDelete,
+		CustomizeDiff: customizeDiffTagsAll,
 
 		Description: `From the [official documentation](https://docs.netbox.dev/en/stable/core-functionality/ipam/#ip-addresses):
 
@@ -23,6 +29,8 @@ func resourceNetboxIPAddress() *schema.Resource {
 >
 > Like a prefix, an IP address can optionally be assigned to a VRF (otherwise, it will appear in the "global" table). IP addresses are automatically arranged under parent prefixes within their respective VRFs according to the IP hierarchy.`,
 
+		Timeouts: defaultTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"ip_address": &schema.Schema{
 				Type:         schema.TypeString,
@@ -33,6 +41,13 @@ func resourceNetboxIPAddress() *schema.Resource {
 				Type:     schema.TypeInt,
 				Optional: true,
 			},
+			"object_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "virtualization.vminterface",
+				ValidateFunc: validation.StringInSlice([]string{"dcim.interface", "virtualization.vminterface"}, false),
+				Description:  "The type of the interface given in `interface_id`. Either `dcim.interface` for device interfaces or `virtualization.vminterface` for virtual machine interfaces. Defaults to `virtualization.vminterface`.",
+			},
 			"vrf_id": &schema.Schema{
 				Type:     schema.TypeInt,
 				Optional: true,
@@ -50,6 +65,17 @@ func resourceNetboxIPAddress() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+			"role": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"loopback", "secondary", "anycast", "vip", "vrrp", "hsrp", "glbp", "carp"}, false),
+				Description:  "The functional role of the IP address, like `loopback` or `vip`.",
+			},
+			"nat_inside_id": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "ID of the IP address for which this address is the outside address of a NAT.",
+			},
 			"tags": &schema.Schema{
 				Type: schema.TypeSet,
 				Elem: &schema.Schema{
@@ -58,19 +84,33 @@ func resourceNetboxIPAddress() *schema.Resource {
 				Optional: true,
 				Set:      schema.HashString,
 			},
+			"tags_all": tagsAllSchema,
 			"description": {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+			customFieldsKey: customFieldsSchema,
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: resourceNetboxIPAddressImport,
 		},
 	}
 }
 
-func resourceNetboxIPAddressCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+// readIPAddress makes an operation decode the IP address it returns into
+// address and return result. The generated client fails to decode the object
+// an IP address is assigned to, whose ID Netbox returns as number instead of
+// the string the model expects, so it is skipped. Use assigned_object_type
+// and assigned_object_id instead.
+func readIPAddress(address *models.IPAddress, result interface{}) func(*runtime.ClientOperation) {
+	return withResponseBody(&struct {
+		*models.IPAddress
+		AssignedObject interface{} `json:"assigned_object"`
+	}{IPAddress: address}, result)
+}
+
+func resourceNetboxIPAddressCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	data := models.WritableIPAddress{}
 	ipAddress := d.Get("ip_address").(string)
@@ -82,34 +122,48 @@ func resourceNetboxIPAddressCreate(d *schema.ResourceData, m interface{}) error
 		data.DNSName = dnsName.(string)
 	}
 
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	data.Tags = tags
+
+	if cf, ok := d.GetOk(customFieldsKey); ok {
+		data.CustomFields = cf
+	}
 
-	params := ipam.NewIpamIPAddressesCreateParams().WithData(&data)
+	params := ipam.NewIpamIPAddressesCreateParams().WithContext(ctx).WithData(&data)
 
 	res, err := api.Ipam.IpamIPAddressesCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxIPAddressUpdate(d, m)
+	return resourceNetboxIPAddressUpdate(ctx, d, m)
 }
 
-func resourceNetboxIPAddressRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxIPAddressRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamIPAddressesReadParams().WithID(id)
+	params := ipam.NewIpamIPAddressesReadParams().WithContext(ctx).WithID(id)
 
-	res, err := api.Ipam.IpamIPAddressesRead(params, nil)
+	result := ipam.NewIpamIPAddressesReadOK()
+	result.Payload = &models.IPAddress{}
+	res, err := api.Ipam.IpamIPAddressesRead(params, nil, readIPAddress(result.Payload, result))
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	if res.GetPayload().AssignedObjectID != nil {
 		d.Set("interface_id", res.GetPayload().AssignedObjectID)
+		d.Set("object_type", res.GetPayload().AssignedObjectType)
 	} else {
 		d.Set("interface_id", nil)
 	}
@@ -130,16 +184,35 @@ func resourceNetboxIPAddressRead(d *schema.ResourceData, m interface{}) error {
 		d.Set("dns_name", res.GetPayload().DNSName)
 	}
 
+	if res.GetPayload().Role != nil {
+		d.Set("role", res.GetPayload().Role.Value)
+	} else {
+		d.Set("role", nil)
+	}
+
+	if res.GetPayload().NatInside != nil {
+		d.Set("nat_inside_id", res.GetPayload().NatInside.ID)
+	} else {
+		d.Set("nat_inside_id", nil)
+	}
+
+	// Custom fields removed in Netbox are removed from the state as well
+	cf := getCustomFields(res.GetPayload().CustomFields)
+	if cf == nil {
+		cf = map[string]interface{}{}
+	}
+	d.Set(customFieldsKey, cf)
+
 	d.Set("ip_address", res.GetPayload().Address)
 	d.Set("description", res.GetPayload().Description)
 	d.Set("status", res.GetPayload().Status.Value)
-	d.Set("tags", getTagListFromNestedTagList(res.GetPayload().Tags))
+	setTagsFromNestedTagList(api, d, res.GetPayload().Tags)
 	return nil
 }
 
-func resourceNetboxIPAddressUpdate(d *schema.ResourceData, m interface{}) error {
+func resourceNetboxIPAddressUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 
-	api := m.(*client.NetBoxAPI)
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritableIPAddress{}
@@ -168,9 +241,10 @@ func resourceNetboxIPAddressUpdate(d *schema.ResourceData, m interface{}) error
 		}
 	}
 
+	data.Role = d.Get("role").(string)
+
 	if interfaceID, ok := d.GetOk("interface_id"); ok {
-		// The other possible type is dcim.interface for devices
-		data.AssignedObjectType = strToPtr("virtualization.vminterface")
+		data.AssignedObjectType = strToPtr(d.Get("object_type").(string))
 		data.AssignedObjectID = int64ToPtr(int64(interfaceID.(int)))
 	}
 
@@ -182,27 +256,123 @@ func resourceNetboxIPAddressUpdate(d *schema.ResourceData, m interface{}) error
 		data.Tenant = int64ToPtr(int64(tenantID.(int)))
 	}
 
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	if natInsideID, ok := d.GetOk("nat_inside_id"); ok {
+		data.NatInside = int64ToPtr(int64(natInsideID.(int)))
+	}
+
+	if cf := getCustomFieldsUpdate(d); cf != nil {
+		data.CustomFields = cf
+	}
+
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	data.Tags = tags
+
+	// The model omits unset relations and an empty role, which would keep
+	// the current ones
+	body := struct {
+		*models.WritableIPAddress
+		AssignedObjectType *string `json:"assigned_object_type"`
+		AssignedObjectID   *int64  `json:"assigned_object_id"`
+		Vrf                *int64  `json:"vrf"`
+		Tenant             *int64  `json:"tenant"`
+		NatInside          *int64  `json:"nat_inside"`
+		Role               string  `json:"role"`
+	}{
+		WritableIPAddress:  &data,
+		AssignedObjectType: data.AssignedObjectType,
+		AssignedObjectID:   data.AssignedObjectID,
+		Vrf:                data.Vrf,
+		Tenant:             data.Tenant,
+		NatInside:          data.NatInside,
+		Role:               data.Role,
+	}
 
-	params := ipam.NewIpamIPAddressesUpdateParams().WithID(id).WithData(&data)
+	params := ipam.NewIpamIPAddressesUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
-	_, err := api.Ipam.IpamIPAddressesUpdate(params, nil)
+	result := ipam.NewIpamIPAddressesUpdateOK()
+	result.Payload = &models.IPAddress{}
+	_, err = api.Ipam.IpamIPAddressesUpdate(params, nil, withRequestBody(params, body), readIPAddress(result.Payload, result))
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxIPAddressRead(d, m)
+	return resourceNetboxIPAddressRead(ctx, d, m)
 }
 
-func resourceNetboxIPAddressDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxIPAddressDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamIPAddressesDeleteParams().WithID(id)
+	params := ipam.NewIpamIPAddressesDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Ipam.IpamIPAddressesDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
+
+// resourceNetboxIPAddressImport imports IP addresses by ID or by address,
+// optionally followed by @ and the name of the VRF of the address, like
+// 10.0.0.1/24@customer-a. Addresses without VRF are looked up in the global
+// table.
+func resourceNetboxIPAddressImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	if _, err := strconv.ParseInt(d.Id(), 10, 64); err == nil {
+		return []*schema.ResourceData{d}, nil
+	}
+	api := m.(*providerState)
+
+	address, vrfName := d.Id(), ""
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		address, vrfName = address[:i], address[i+1:]
+	}
+	if _, _, err := net.ParseCIDR(address); err != nil {
+		return nil, fmt.Errorf("unexpected format of ID (%s), expected ID, address or address@vrf", d.Id())
+	}
+
+	table := "the global table"
+	vrfID := "null"
+	if vrfName != "" {
+		vrfParams := ipam.NewIpamVrfsListParams().WithContext(ctx)
+		vrfParams.Name = &vrfName
+		limit := int64(2)
+		vrfParams.Limit = &limit
+
+		res, err := api.Ipam.IpamVrfsList(vrfParams, nil)
+		if err != nil {
+			return nil, err
+		}
+		if *res.GetPayload().Count != int64(1) {
+			return nil, fmt.Errorf("expected one VRF named %s, found %d", vrfName, *res.GetPayload().Count)
+		}
+		table = "VRF " + vrfName
+		vrfID = strconv.FormatInt(res.GetPayload().Results[0].ID, 10)
+	}
+
+	params := ipam.NewIpamIPAddressesListParams().WithContext(ctx)
+	params.Address = &address
+	params.VrfID = &vrfID
+	limit := int64(2)
+	params.Limit = &limit
+
+	// Only the ID is needed, which also avoids decoding assigned objects
+	var payload struct {
+		Count   int64 `json:"count"`
+		Results []struct {
+			ID int64 `json:"id"`
+		} `json:"results"`
+	}
+	_, err := api.Ipam.IpamIPAddressesList(params, nil, withResponseBody(&payload, ipam.NewIpamIPAddressesListOK()))
+	if err != nil {
+		return nil, err
+	}
+	if payload.Count != 1 {
+		return nil, fmt.Errorf("expected one IP address %s in %s, found %d", address, table, payload.Count)
+	}
+
+	d.SetId(strconv.FormatInt(payload.Results[0].ID, 10))
+	return []*schema.ResourceData{d}, nil
+}
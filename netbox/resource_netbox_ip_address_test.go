@@ -1,15 +1,19 @@
 package netbox
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"regexp"
+	"strconv"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 	"github.com/netbox-community/go-netbox/netbox/models"
+	"github.com/stretchr/testify/assert"
 )
 
 func testAccNetboxIPAddressFullDependencies(testName string) string {
@@ -150,10 +154,146 @@ resource "netbox_ip_address" "test" {
 				ImportState:       true,
 				ImportStateVerify: true,
 			},
+			{
+				ResourceName:      "netbox_ip_address.test",
+				ImportState:       true,
+				ImportStateId:     testIP,
+				ImportStateVerify: true,
+			},
 		},
 	})
 }
 
+func TestUnitNetboxIPAddress_deviceInterface(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	interfaceID := fake.Seed(t, "dcim/interfaces", map[string]interface{}{"name": "eth0", "type": "1000base-t"})
+	insideID := fake.Seed(t, "ipam/ip-addresses", map[string]interface{}{"address": "10.0.0.1/24", "status": "active"})
+	r := resourceNetboxIPAddress()
+
+	config := map[string]interface{}{
+		"ip_address":    "192.0.2.1/32",
+		"status":        "active",
+		"interface_id":  int(interfaceID),
+		"object_type":   "dcim.interface",
+		"role":          "vip",
+		"nat_inside_id": int(insideID),
+		"custom_fields": map[string]interface{}{"owner": "ops"},
+	}
+	state, diags := testUnitApply(t, r, nil, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "dcim.interface", state.Attributes["object_type"])
+	assert.Equal(t, strconv.FormatInt(interfaceID, 10), state.Attributes["interface_id"])
+	assert.Equal(t, "vip", state.Attributes["role"])
+	assert.Equal(t, strconv.FormatInt(insideID, 10), state.Attributes["nat_inside_id"])
+	assert.Equal(t, "ops", state.Attributes["custom_fields.owner"])
+
+	id, _ := strconv.ParseInt(state.ID, 10, 64)
+	address := fake.Get("ipam/ip-addresses", id)
+	assert.Equal(t, "dcim.interface", address["assigned_object_type"])
+	assert.Equal(t, interfaceID, address["assigned_object"].(map[string]interface{})["id"])
+
+	// Removing the assignment, role and NAT clears them in Netbox
+	config = map[string]interface{}{
+		"ip_address": "192.0.2.1/32",
+		"status":     "active",
+	}
+	state, diags = testUnitApply(t, r, state, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "", state.Attributes["role"])
+	assert.Equal(t, "0", state.Attributes["nat_inside_id"])
+	address = fake.Get("ipam/ip-addresses", id)
+	assert.Nil(t, address["assigned_object"])
+	assert.Nil(t, address["nat_inside"])
+}
+
+func TestUnitNetboxIPAddress_customFieldsRemoved(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	id := fake.Seed(t, "ipam/ip-addresses", map[string]interface{}{"address": "192.0.2.1/32", "status": "active"})
+
+	// The custom field was removed in Netbox since the last refresh
+	state := &terraform.InstanceState{
+		ID: strconv.FormatInt(id, 10),
+		Attributes: map[string]string{
+			"id":                  strconv.FormatInt(id, 10),
+			"ip_address":          "192.0.2.1/32",
+			"status":              "active",
+			"custom_fields.%":     "1",
+			"custom_fields.owner": "ops",
+		},
+	}
+	state, diags := testUnitRefresh(t, resourceNetboxIPAddress(), state, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "0", state.Attributes["custom_fields.%"])
+	assert.NotContains(t, state.Attributes, "custom_fields.owner")
+}
+
+func TestUnitNetboxIPAddress_customFieldsRemovedFromConfig(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	r := resourceNetboxIPAddress()
+
+	config := map[string]interface{}{
+		"ip_address":    "192.0.2.1/32",
+		"status":        "active",
+		"custom_fields": map[string]interface{}{"owner": "ops", "ticket": "1234"},
+	}
+	state, diags := testUnitApply(t, r, nil, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "2", state.Attributes["custom_fields.%"])
+
+	config["custom_fields"] = map[string]interface{}{"owner": "ops"}
+	state, diags = testUnitApply(t, r, state, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "1", state.Attributes["custom_fields.%"])
+	assert.NotContains(t, state.Attributes, "custom_fields.ticket")
+
+	id, _ := strconv.ParseInt(state.ID, 10, 64)
+	assert.Equal(t, map[string]interface{}{"owner": "ops", "ticket": nil}, fake.Get("ipam/ip-addresses", id)["custom_fields"])
+
+	delete(config, "custom_fields")
+	state, diags = testUnitApply(t, r, state, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "0", state.Attributes["custom_fields.%"])
+	assert.Equal(t, map[string]interface{}{"owner": nil, "ticket": nil}, fake.Get("ipam/ip-addresses", id)["custom_fields"])
+}
+
+func TestUnitNetboxIPAddress_importByAddress(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	vrfID := fake.Seed(t, "ipam/vrfs", map[string]interface{}{"name": "customer-a"})
+	globalID := fake.Seed(t, "ipam/ip-addresses", map[string]interface{}{"address": "10.0.0.1/24", "status": "active", "vrf": nil})
+	vrfAddressID := fake.Seed(t, "ipam/ip-addresses", map[string]interface{}{"address": "10.0.0.1/24", "status": "active", "vrf": vrfID})
+	r := resourceNetboxIPAddress()
+
+	for _, tt := range []struct {
+		id       string
+		expected int64
+		err      string
+	}{
+		{id: strconv.FormatInt(vrfAddressID, 10), expected: vrfAddressID},
+		{id: "10.0.0.1/24", expected: globalID},
+		{id: "10.0.0.1/24@customer-a", expected: vrfAddressID},
+		{id: "10.0.0.2/24", err: "expected one IP address 10.0.0.2/24 in the global table, found 0"},
+		{id: "10.0.0.1/24@customer-b", err: "expected one VRF named customer-b, found 0"},
+		{id: "customer-a", err: "unexpected format of ID (customer-a)"},
+	} {
+		d := r.Data(nil)
+		d.SetId(tt.id)
+		imported, err := r.Importer.StateContext(context.Background(), d, api)
+		if tt.err != "" {
+			if assert.Error(t, err, tt.id) {
+				assert.Contains(t, err.Error(), tt.err)
+			}
+			continue
+		}
+		if assert.NoError(t, err, tt.id) {
+			assert.Equal(t, strconv.FormatInt(tt.expected, 10), imported[0].Id(), tt.id)
+		}
+	}
+}
+
 func init() {
 	resource.AddTestSweepers("netbox_ip_address", &resource.Sweeper{
 		Name:         "netbox_ip_address",
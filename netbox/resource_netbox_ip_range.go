@@ -1,26 +1,30 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxIpRange() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxIpRangeCreate,
-		Read:   resourceNetboxIpRangeRead,
-		Update: resourceNetboxIpRangeUpdate,
-		Delete: resourceNetboxIpRangeDelete,
+		CreateContext: resourceNetboxIpRangeCreate,
+		ReadContext:   resourceNetboxIpRangeRead,
+		UpdateContext: resourceNetboxIpRangeUpdate,
+		DeleteContext: resourceNetboxIpRangeDelete,
+		CustomizeDiff: customizeDiffTagsAll,
 
 		Description: `From the [official documentation](https://docs.netbox.dev/en/stable/core-functionality/ipam/#ip-ranges):
 
 > This model represents an arbitrary range of individual IPv4 or IPv6 addresses, inclusive of its starting and ending addresses. For instance, the range 192.0.2.10 to 192.0.2.20 has eleven members. (The total member count is available as the size property on an IPRange instance.) Like prefixes and IP addresses, each IP range may optionally be assigned to a VRF and/or tenant.`,
 
+		Timeouts: defaultTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"start_address": &schema.Schema{
 				Type:     schema.TypeString,
@@ -60,15 +64,16 @@ func resourceNetboxIpRange() *schema.Resource {
 				Optional: true,
 				Set:      schema.HashString,
 			},
+			"tags_all": tagsAllSchema,
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxIpRangeCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxIpRangeCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	data := models.WritableIPRange{}
 
 	startAddress := d.Get("start_address").(string)
@@ -81,27 +86,34 @@ func resourceNetboxIpRangeCreate(d *schema.ResourceData, m interface{}) error {
 	data.Status = status
 	data.Description = description
 
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	data.Tags = tags
 
-	params := ipam.NewIpamIPRangesCreateParams().WithData(&data)
+	params := ipam.NewIpamIPRangesCreateParams().WithContext(ctx).WithData(&data)
 	res, err := api.Ipam.IpamIPRangesCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxIpRangeUpdate(d, m)
+	return resourceNetboxIpRangeUpdate(ctx, d, m)
 }
 
-func resourceNetboxIpRangeRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxIpRangeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamIPRangesReadParams().WithID(id)
+	params := ipam.NewIpamIPRangesReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Ipam.IpamIPRangesRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	if res.GetPayload().StartAddress != nil {
@@ -132,13 +144,13 @@ func resourceNetboxIpRangeRead(d *schema.ResourceData, m interface{}) error {
 		d.Set("role_id", res.GetPayload().Role.ID)
 	}
 
-	d.Set("tags", getTagListFromNestedTagList(res.GetPayload().Tags))
+	setTagsFromNestedTagList(api, d, res.GetPayload().Tags)
 
 	return nil
 }
 
-func resourceNetboxIpRangeUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxIpRangeUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritableIPRange{}
 	startAddress := d.Get("start_address").(string)
@@ -164,23 +176,27 @@ func resourceNetboxIpRangeUpdate(d *schema.ResourceData, m interface{}) error {
 		data.Role = int64ToPtr(int64(roleID.(int)))
 	}
 
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	data.Tags = tags
 
-	params := ipam.NewIpamIPRangesUpdateParams().WithID(id).WithData(&data)
-	_, err := api.Ipam.IpamIPRangesUpdate(params, nil)
+	params := ipam.NewIpamIPRangesUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
+	_, err = api.Ipam.IpamIPRangesUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
-	return resourceNetboxIpRangeRead(d, m)
+	return resourceNetboxIpRangeRead(ctx, d, m)
 }
 
-func resourceNetboxIpRangeDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxIpRangeDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamIPRangesDeleteParams().WithID(id)
+	params := ipam.NewIpamIPRangesDeleteParams().WithContext(ctx).WithID(id)
 	_, err := api.Ipam.IpamIPRangesDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	return nil
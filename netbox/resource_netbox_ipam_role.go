@@ -1,21 +1,24 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxIpamRole() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxIpamRoleCreate,
-		Read:   resourceNetboxIpamRoleRead,
-		Update: resourceNetboxIpamRoleUpdate,
-		Delete: resourceNetboxIpamRoleDelete,
+		CreateContext: resourceNetboxIpamRoleCreate,
+		ReadContext:   resourceNetboxIpamRoleRead,
+		UpdateContext: resourceNetboxIpamRoleUpdate,
+		DeleteContext: resourceNetboxIpamRoleDelete,
+
+		Timeouts: defaultTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
@@ -39,12 +42,12 @@ func resourceNetboxIpamRole() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
-func resourceNetboxIpamRoleCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxIpamRoleCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	data := models.Role{}
 
 	name := d.Get("name").(string)
@@ -65,25 +68,28 @@ func resourceNetboxIpamRoleCreate(d *schema.ResourceData, m interface{}) error {
 	data.Weight = &weight
 	data.Description = description
 
-	params := ipam.NewIpamRolesCreateParams().WithData(&data)
+	params := ipam.NewIpamRolesCreateParams().WithContext(ctx).WithData(&data)
 	res, err := api.Ipam.IpamRolesCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxIpamRoleUpdate(d, m)
+	return resourceNetboxIpamRoleUpdate(ctx, d, m)
 }
 
-func resourceNetboxIpamRoleRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxIpamRoleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamRolesReadParams().WithID(id)
+	params := ipam.NewIpamRolesReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Ipam.IpamRolesRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	if res.GetPayload().Name != nil {
@@ -105,8 +111,8 @@ func resourceNetboxIpamRoleRead(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func resourceNetboxIpamRoleUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxIpamRoleUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.Role{}
 
@@ -128,21 +134,21 @@ func resourceNetboxIpamRoleUpdate(d *schema.ResourceData, m interface{}) error {
 	data.Weight = &weight
 	data.Description = description
 
-	params := ipam.NewIpamRolesUpdateParams().WithID(id).WithData(&data)
+	params := ipam.NewIpamRolesUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 	_, err := api.Ipam.IpamRolesUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
-	return resourceNetboxIpamRoleRead(d, m)
+	return resourceNetboxIpamRoleRead(ctx, d, m)
 }
 
-func resourceNetboxIpamRoleDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxIpamRoleDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamRolesDeleteParams().WithID(id)
+	params := ipam.NewIpamRolesDeleteParams().WithContext(ctx).WithID(id)
 	_, err := api.Ipam.IpamRolesDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	d.SetId("")
 	return nil
@@ -1,21 +1,24 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/dcim"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxManufacturer() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxManufacturerCreate,
-		Read:   resourceNetboxManufacturerRead,
-		Update: resourceNetboxManufacturerUpdate,
-		Delete: resourceNetboxManufacturerDelete,
+		CreateContext: resourceNetboxManufacturerCreate,
+		ReadContext:   resourceNetboxManufacturerRead,
+		UpdateContext: resourceNetboxManufacturerUpdate,
+		DeleteContext: resourceNetboxManufacturerDelete,
+
+		Timeouts: defaultTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
@@ -30,13 +33,13 @@ func resourceNetboxManufacturer() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxManufacturerCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxManufacturerCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	data := models.Manufacturer{}
 
@@ -51,28 +54,31 @@ func resourceNetboxManufacturerCreate(d *schema.ResourceData, m interface{}) err
 		data.Slug = strToPtr(slugValue.(string))
 	}
 
-	params := dcim.NewDcimManufacturersCreateParams().WithData(&data)
+	params := dcim.NewDcimManufacturersCreateParams().WithContext(ctx).WithData(&data)
 
 	res, err := api.Dcim.DcimManufacturersCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxManufacturerRead(d, m)
+	return resourceNetboxManufacturerRead(ctx, d, m)
 }
 
-func resourceNetboxManufacturerRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxManufacturerRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := dcim.NewDcimManufacturersReadParams().WithID(id)
+	params := dcim.NewDcimManufacturersReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Dcim.DcimManufacturersRead(params, nil)
 
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("name", res.GetPayload().Name)
@@ -81,8 +87,8 @@ func resourceNetboxManufacturerRead(d *schema.ResourceData, m interface{}) error
 	return nil
 }
 
-func resourceNetboxManufacturerUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxManufacturerUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.Manufacturer{}
@@ -98,25 +104,25 @@ func resourceNetboxManufacturerUpdate(d *schema.ResourceData, m interface{}) err
 		data.Slug = strToPtr(slugValue.(string))
 	}
 
-	params := dcim.NewDcimManufacturersPartialUpdateParams().WithID(id).WithData(&data)
+	params := dcim.NewDcimManufacturersPartialUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
 	_, err := api.Dcim.DcimManufacturersPartialUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxManufacturerRead(d, m)
+	return resourceNetboxManufacturerRead(ctx, d, m)
 }
 
-func resourceNetboxManufacturerDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxManufacturerDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := dcim.NewDcimManufacturersDeleteParams().WithID(id)
+	params := dcim.NewDcimManufacturersDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Dcim.DcimManufacturersDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
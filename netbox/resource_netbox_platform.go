@@ -1,26 +1,29 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/dcim"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxPlatform() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxPlatformCreate,
-		Read:   resourceNetboxPlatformRead,
-		Update: resourceNetboxPlatformUpdate,
-		Delete: resourceNetboxPlatformDelete,
+		CreateContext: resourceNetboxPlatformCreate,
+		ReadContext:   resourceNetboxPlatformRead,
+		UpdateContext: resourceNetboxPlatformUpdate,
+		DeleteContext: resourceNetboxPlatformDelete,
 
 		Description: `From the [official documentation](https://docs.netbox.dev/en/stable/core-functionality/devices/#platforms):
 
 > A platform defines the type of software running on a device or virtual machine. This can be helpful to model when it is necessary to distinguish between different versions or feature sets. Note that two devices of the same type may be assigned different platforms: For example, one Juniper MX240 might run Junos 14 while another runs Junos 15.`,
 
+		Timeouts: defaultTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:     schema.TypeString,
@@ -34,13 +37,13 @@ func resourceNetboxPlatform() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxPlatformCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxPlatformCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	name := d.Get("name").(string)
 
@@ -53,7 +56,7 @@ func resourceNetboxPlatformCreate(d *schema.ResourceData, m interface{}) error {
 		slug = slugValue.(string)
 	}
 
-	params := dcim.NewDcimPlatformsCreateParams().WithData(
+	params := dcim.NewDcimPlatformsCreateParams().WithContext(ctx).WithData(
 		&models.WritablePlatform{
 			Name: &name,
 			Slug: &slug,
@@ -63,24 +66,27 @@ func resourceNetboxPlatformCreate(d *schema.ResourceData, m interface{}) error {
 	res, err := api.Dcim.DcimPlatformsCreate(params, nil)
 	if err != nil {
 		//return errors.New(getTextFromError(err))
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxPlatformRead(d, m)
+	return resourceNetboxPlatformRead(ctx, d, m)
 }
 
-func resourceNetboxPlatformRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxPlatformRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := dcim.NewDcimPlatformsReadParams().WithID(id)
+	params := dcim.NewDcimPlatformsReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Dcim.DcimPlatformsRead(params, nil)
 
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("name", res.GetPayload().Name)
@@ -88,8 +94,8 @@ func resourceNetboxPlatformRead(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func resourceNetboxPlatformUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxPlatformUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritablePlatform{}
@@ -108,25 +114,25 @@ func resourceNetboxPlatformUpdate(d *schema.ResourceData, m interface{}) error {
 	data.Slug = &slug
 	data.Name = &name
 
-	params := dcim.NewDcimPlatformsPartialUpdateParams().WithID(id).WithData(&data)
+	params := dcim.NewDcimPlatformsPartialUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
 	_, err := api.Dcim.DcimPlatformsPartialUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxPlatformRead(d, m)
+	return resourceNetboxPlatformRead(ctx, d, m)
 }
 
-func resourceNetboxPlatformDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxPlatformDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := dcim.NewDcimPlatformsDeleteParams().WithID(id)
+	params := dcim.NewDcimPlatformsDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Dcim.DcimPlatformsDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
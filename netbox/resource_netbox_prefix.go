@@ -1,21 +1,23 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxPrefix() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxPrefixCreate,
-		Read:   resourceNetboxPrefixRead,
-		Update: resourceNetboxPrefixUpdate,
-		Delete: resourceNetboxPrefixDelete,
+		CreateContext: resourceNetboxPrefixCreate,
+		ReadContext:   resourceNetboxPrefixRead,
+		UpdateContext: resourceNetboxPrefixUpdate,
+		DeleteContext: resourceNetboxPrefixDelete,
+		CustomizeDiff: customizeDiffTagsAll,
 
 		Description: `From the [official documentation](https://docs.netbox.dev/en/stable/core-functionality/ipam/#prefixes):
 
@@ -23,6 +25,8 @@ func resourceNetboxPrefix() *schema.Resource {
 >
 > Prefixes are automatically organized by their parent aggregates. Additionally, each prefix can be assigned to a particular site and virtual routing and forwarding instance (VRF). Each VRF represents a separate IP space or routing table. All prefixes not assigned to a VRF are considered to be in the "global" table.`,
 
+		Timeouts: defaultTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"prefix": {
 				Type:         schema.TypeString,
@@ -74,14 +78,21 @@ func resourceNetboxPrefix() *schema.Resource {
 				Optional: true,
 				Set:      schema.HashString,
 			},
+			"tags_all": tagsAllSchema,
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
-func resourceNetboxPrefixCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxPrefixCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+
+	if d.Get("mark_utilized").(bool) {
+		if diags := api.requireNetboxVersion("mark_utilized", "3.0.0"); diags.HasError() {
+			return diags
+		}
+	}
 	data := models.WritablePrefix{}
 
 	prefix := d.Get("prefix").(string)
@@ -118,27 +129,34 @@ func resourceNetboxPrefixCreate(d *schema.ResourceData, m interface{}) error {
 		data.Role = int64ToPtr(int64(roleID.(int)))
 	}
 
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	data.Tags = tags
 
-	params := ipam.NewIpamPrefixesCreateParams().WithData(&data)
+	params := ipam.NewIpamPrefixesCreateParams().WithContext(ctx).WithData(&data)
 	res, err := api.Ipam.IpamPrefixesCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxPrefixRead(d, m)
+	return resourceNetboxPrefixRead(ctx, d, m)
 }
 
-func resourceNetboxPrefixRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxPrefixRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamPrefixesReadParams().WithID(id)
+	params := ipam.NewIpamPrefixesReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Ipam.IpamPrefixesRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("description", res.GetPayload().Description)
@@ -181,14 +199,20 @@ func resourceNetboxPrefixRead(d *schema.ResourceData, m interface{}) error {
 		d.Set("role_id", nil)
 	}
 
-	d.Set("tags", getTagListFromNestedTagList(res.GetPayload().Tags))
+	setTagsFromNestedTagList(api, d, res.GetPayload().Tags)
 	// FIGURE OUT NESTED VRF AND NESTED VLAN (from maybe interfaces?)
 
 	return nil
 }
 
-func resourceNetboxPrefixUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxPrefixUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+
+	if d.Get("mark_utilized").(bool) {
+		if diags := api.requireNetboxVersion("mark_utilized", "3.0.0"); diags.HasError() {
+			return diags
+		}
+	}
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritablePrefix{}
 	prefix := d.Get("prefix").(string)
@@ -224,23 +248,27 @@ func resourceNetboxPrefixUpdate(d *schema.ResourceData, m interface{}) error {
 		data.Role = int64ToPtr(int64(roleID.(int)))
 	}
 
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	data.Tags = tags
 
-	params := ipam.NewIpamPrefixesUpdateParams().WithID(id).WithData(&data)
-	_, err := api.Ipam.IpamPrefixesUpdate(params, nil)
+	params := ipam.NewIpamPrefixesUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
+	_, err = api.Ipam.IpamPrefixesUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
-	return resourceNetboxPrefixRead(d, m)
+	return resourceNetboxPrefixRead(ctx, d, m)
 }
 
-func resourceNetboxPrefixDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxPrefixDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamPrefixesDeleteParams().WithID(id)
+	params := ipam.NewIpamPrefixesDeleteParams().WithContext(ctx).WithID(id)
 	_, err := api.Ipam.IpamPrefixesDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	d.SetId("")
 	return nil
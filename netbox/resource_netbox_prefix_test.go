@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"log"
 	"regexp"
+	"strconv"
 	"testing"
 
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 	"github.com/netbox-community/go-netbox/netbox/models"
+	"github.com/stretchr/testify/assert"
 )
 
 func testAccNetboxPrefixFullDependencies(testName string, testSlug string, testVid string) string {
@@ -210,6 +213,85 @@ resource "netbox_prefix" "test" {
 	})
 }
 
+func TestUnitNetboxPrefix_lifecycle(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	vrfID := fake.Seed(t, "ipam/vrfs", map[string]interface{}{"name": "vrf"})
+	tenantID := fake.Seed(t, "tenancy/tenants", map[string]interface{}{"name": "tenant", "slug": "tenant"})
+	siteID := fake.Seed(t, "dcim/sites", map[string]interface{}{"name": "site", "slug": "site", "status": "active"})
+	vlanID := fake.Seed(t, "ipam/vlans", map[string]interface{}{"name": "vlan", "vid": 100, "status": "active"})
+	roleID := fake.Seed(t, "ipam/roles", map[string]interface{}{"name": "role", "slug": "role"})
+	r := resourceNetboxPrefix()
+
+	state, diags := testUnitApply(t, r, nil, map[string]interface{}{
+		"prefix":    "10.0.0.0/24",
+		"status":    "active",
+		"vrf_id":    int(vrfID),
+		"tenant_id": int(tenantID),
+		"site_id":   int(siteID),
+		"vlan_id":   int(vlanID),
+		"role_id":   int(roleID),
+		"is_pool":   true,
+	}, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, strconv.FormatInt(vrfID, 10), state.Attributes["vrf_id"])
+	assert.Equal(t, strconv.FormatInt(tenantID, 10), state.Attributes["tenant_id"])
+	assert.Equal(t, strconv.FormatInt(siteID, 10), state.Attributes["site_id"])
+	assert.Equal(t, strconv.FormatInt(vlanID, 10), state.Attributes["vlan_id"])
+	assert.Equal(t, strconv.FormatInt(roleID, 10), state.Attributes["role_id"])
+	assert.Equal(t, "true", state.Attributes["is_pool"])
+
+	state, diags = testUnitApply(t, r, state, map[string]interface{}{
+		"prefix": "10.0.0.0/24",
+		"status": "reserved",
+		"vrf_id": int(vrfID),
+	}, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "reserved", state.Attributes["status"])
+
+	_, diags = testUnitApply(t, r, nil, map[string]interface{}{
+		"prefix": "10.1.0.0/24",
+		"status": "active",
+		"vrf_id": 4242,
+	}, api)
+	assert.True(t, diags.HasError())
+
+	_, diags = testUnitApply(t, r, state, nil, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, 0, fake.Count("ipam/prefixes"))
+}
+
+func TestUnitNetboxPrefix_requiresNetboxVersion(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	api.netboxVersion = version.Must(version.NewVersion("2.11.12"))
+	r := resourceNetboxPrefix()
+
+	_, diags := testUnitApply(t, r, nil, map[string]interface{}{
+		"prefix":        "10.0.0.0/24",
+		"status":        "active",
+		"mark_utilized": true,
+	}, api)
+	assert.True(t, diags.HasError())
+	assert.Equal(t, "Attribute mark_utilized requires Netbox >= 3.0.0", diags[0].Summary)
+	assert.Equal(t, 0, fake.Count("ipam/prefixes"))
+
+	state, diags := testUnitApply(t, r, nil, map[string]interface{}{
+		"prefix": "10.0.0.0/24",
+		"status": "active",
+	}, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+
+	_, diags = testUnitApply(t, r, state, map[string]interface{}{
+		"prefix":        "10.0.0.0/24",
+		"status":        "active",
+		"mark_utilized": true,
+	}, api)
+	assert.True(t, diags.HasError())
+	id, _ := strconv.ParseInt(state.ID, 10, 64)
+	assert.NotEqual(t, true, fake.Get("ipam/prefixes", id)["mark_utilized"])
+}
+
 func init() {
 	resource.AddTestSweepers("netbox_prefix", &resource.Sweeper{
 		Name:         "netbox_prefix",
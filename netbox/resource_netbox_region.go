@@ -1,21 +1,24 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/dcim"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxRegion() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxRegionCreate,
-		Read:   resourceNetboxRegionRead,
-		Update: resourceNetboxRegionUpdate,
-		Delete: resourceNetboxRegionDelete,
+		CreateContext: resourceNetboxRegionCreate,
+		ReadContext:   resourceNetboxRegionRead,
+		UpdateContext: resourceNetboxRegionUpdate,
+		DeleteContext: resourceNetboxRegionDelete,
+
+		Timeouts: defaultTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
@@ -43,13 +46,13 @@ func resourceNetboxRegion() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxRegionCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxRegionCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	data := models.WritableRegion{}
 
@@ -73,27 +76,30 @@ func resourceNetboxRegionCreate(d *schema.ResourceData, m interface{}) error {
 		data.Parent = int64ToPtr(int64(parentRegionIDValue.(int)))
 	}
 
-	params := dcim.NewDcimRegionsCreateParams().WithData(&data)
+	params := dcim.NewDcimRegionsCreateParams().WithContext(ctx).WithData(&data)
 
 	res, err := api.Dcim.DcimRegionsCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxRegionRead(d, m)
+	return resourceNetboxRegionRead(ctx, d, m)
 }
 
-func resourceNetboxRegionRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxRegionRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := dcim.NewDcimRegionsReadParams().WithID(id)
+	params := dcim.NewDcimRegionsReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Dcim.DcimRegionsRead(params, nil)
 
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("name", res.GetPayload().Name)
@@ -107,8 +113,8 @@ func resourceNetboxRegionRead(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func resourceNetboxRegionUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxRegionUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritableRegion{}
@@ -133,25 +139,25 @@ func resourceNetboxRegionUpdate(d *schema.ResourceData, m interface{}) error {
 		data.Parent = int64ToPtr(int64(parentRegionIDValue.(int)))
 	}
 
-	params := dcim.NewDcimRegionsPartialUpdateParams().WithID(id).WithData(&data)
+	params := dcim.NewDcimRegionsPartialUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
 	_, err := api.Dcim.DcimRegionsPartialUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxRegionRead(d, m)
+	return resourceNetboxRegionRead(ctx, d, m)
 }
 
-func resourceNetboxRegionDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxRegionDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := dcim.NewDcimRegionsDeleteParams().WithID(id)
+	params := dcim.NewDcimRegionsDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Dcim.DcimRegionsDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
@@ -1,21 +1,24 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxRir() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxRirCreate,
-		Read:   resourceNetboxRirRead,
-		Update: resourceNetboxRirUpdate,
-		Delete: resourceNetboxRirDelete,
+		CreateContext: resourceNetboxRirCreate,
+		ReadContext:   resourceNetboxRirRead,
+		UpdateContext: resourceNetboxRirUpdate,
+		DeleteContext: resourceNetboxRirDelete,
+
+		Timeouts: defaultTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
@@ -30,12 +33,12 @@ func resourceNetboxRir() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
-func resourceNetboxRirCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxRirCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	data := models.RIR{}
 
 	name := d.Get("name").(string)
@@ -51,25 +54,28 @@ func resourceNetboxRirCreate(d *schema.ResourceData, m interface{}) error {
 	data.Name = &name
 	data.Slug = &slug
 
-	params := ipam.NewIpamRirsCreateParams().WithData(&data)
+	params := ipam.NewIpamRirsCreateParams().WithContext(ctx).WithData(&data)
 	res, err := api.Ipam.IpamRirsCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxRirUpdate(d, m)
+	return resourceNetboxRirUpdate(ctx, d, m)
 }
 
-func resourceNetboxRirRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxRirRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamRirsReadParams().WithID(id)
+	params := ipam.NewIpamRirsReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Ipam.IpamRirsRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	if res.GetPayload().Name != nil {
@@ -83,8 +89,8 @@ func resourceNetboxRirRead(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func resourceNetboxRirUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxRirUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.RIR{}
 
@@ -101,21 +107,21 @@ func resourceNetboxRirUpdate(d *schema.ResourceData, m interface{}) error {
 	data.Name = &name
 	data.Slug = &slug
 
-	params := ipam.NewIpamRirsUpdateParams().WithID(id).WithData(&data)
+	params := ipam.NewIpamRirsUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 	_, err := api.Ipam.IpamRirsUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
-	return resourceNetboxRirRead(d, m)
+	return resourceNetboxRirRead(ctx, d, m)
 }
 
-func resourceNetboxRirDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxRirDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamRirsDeleteParams().WithID(id)
+	params := ipam.NewIpamRirsDeleteParams().WithContext(ctx).WithID(id)
 	_, err := api.Ipam.IpamRirsDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	d.SetId("")
 	return nil
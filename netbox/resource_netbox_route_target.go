@@ -0,0 +1,161 @@
+package netbox
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/netbox-community/go-netbox/netbox/client/ipam"
+	"github.com/netbox-community/go-netbox/netbox/models"
+)
+
+func resourceNetboxRouteTarget() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceNetboxRouteTargetCreate,
+		ReadContext:   resourceNetboxRouteTargetRead,
+		UpdateContext: resourceNetboxRouteTargetUpdate,
+		DeleteContext: resourceNetboxRouteTargetDelete,
+		CustomizeDiff: customizeDiffTagsAll,
+
+		Description: `From the [official documentation](https://docs.netbox.dev/en/stable/core-functionality/ipam/#route-targets):
+
+> A route target is a particular type of extended BGP community used to control the redistribution of routes among VRF tables in a network. Route targets can be assigned to individual VRFs in NetBox as import or export targets (or both) to model this exchange in an L3VPN. Each route target must be given a unique name, which should be in a format prescribed by RFC 4364, similar to a VRF route distinguisher.`,
+
+		Timeouts: defaultTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringLenBetween(1, 21),
+				Description:  "Route target value, formatted in accordance with RFC 4360, like `65000:100`.",
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"tenant_id": {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+			"tags": {
+				Type: schema.TypeSet,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+				Set:      schema.HashString,
+			},
+			"tags_all": tagsAllSchema,
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+// writableRouteTarget is the request body of route targets. The model omits
+// an empty description and tenant, which would keep the current ones.
+type writableRouteTarget struct {
+	*models.WritableRouteTarget
+	Description string `json:"description"`
+	Tenant      *int64 `json:"tenant"`
+}
+
+func getRouteTargetFromResourceData(ctx context.Context, api *providerState, d *schema.ResourceData) (*writableRouteTarget, error) {
+	name := d.Get("name").(string)
+	data := &writableRouteTarget{
+		WritableRouteTarget: &models.WritableRouteTarget{
+			Name: &name,
+		},
+		Description: d.Get("description").(string),
+	}
+
+	if tenantID, ok := d.GetOk("tenant_id"); ok {
+		data.Tenant = int64ToPtr(int64(tenantID.(int)))
+	}
+
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return nil, err
+	}
+	data.Tags = tags
+	return data, nil
+}
+
+func resourceNetboxRouteTargetCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+
+	data, err := getRouteTargetFromResourceData(ctx, api, d)
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+
+	params := ipam.NewIpamRouteTargetsCreateParams().WithContext(ctx).WithData(data.WritableRouteTarget)
+	res, err := api.Ipam.IpamRouteTargetsCreate(params, nil, withRequestBody(params, data))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+
+	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
+
+	return resourceNetboxRouteTargetRead(ctx, d, m)
+}
+
+func resourceNetboxRouteTargetRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+	id, _ := strconv.ParseInt(d.Id(), 10, 64)
+	params := ipam.NewIpamRouteTargetsReadParams().WithContext(ctx).WithID(id)
+
+	res, err := api.Ipam.IpamRouteTargetsRead(params, nil)
+	if err != nil {
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
+	}
+
+	routeTarget := res.GetPayload()
+	d.Set("name", routeTarget.Name)
+	d.Set("description", routeTarget.Description)
+	if routeTarget.Tenant != nil {
+		d.Set("tenant_id", routeTarget.Tenant.ID)
+	} else {
+		d.Set("tenant_id", nil)
+	}
+	setTagsFromNestedTagList(api, d, routeTarget.Tags)
+	return nil
+}
+
+func resourceNetboxRouteTargetUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+	id, _ := strconv.ParseInt(d.Id(), 10, 64)
+
+	data, err := getRouteTargetFromResourceData(ctx, api, d)
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+
+	params := ipam.NewIpamRouteTargetsUpdateParams().WithContext(ctx).WithID(id).WithData(data.WritableRouteTarget)
+	_, err = api.Ipam.IpamRouteTargetsUpdate(params, nil, withRequestBody(params, data))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+
+	return resourceNetboxRouteTargetRead(ctx, d, m)
+}
+
+func resourceNetboxRouteTargetDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+	id, _ := strconv.ParseInt(d.Id(), 10, 64)
+
+	params := ipam.NewIpamRouteTargetsDeleteParams().WithContext(ctx).WithID(id)
+	_, err := api.Ipam.IpamRouteTargetsDelete(params, nil)
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package netbox
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/netbox-community/go-netbox/netbox/client"
+	"github.com/netbox-community/go-netbox/netbox/client/ipam"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccNetboxRouteTarget_basic(t *testing.T) {
+
+	testSlug := "rt"
+	testName := testAccGetTestName(testSlug)
+	resource.ParallelTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		PreCheck:  func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "netbox_tenant" "test" {
+  name = "%[1]s"
+}
+
+resource "netbox_route_target" "test" {
+  name        = "%[1]s"
+  description = "%[1]s description"
+  tenant_id   = netbox_tenant.test.id
+}`, testName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("netbox_route_target.test", "name", testName),
+					resource.TestCheckResourceAttr("netbox_route_target.test", "description", testName+" description"),
+					resource.TestCheckResourceAttrPair("netbox_route_target.test", "tenant_id", "netbox_tenant.test", "id"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(`
+resource "netbox_tenant" "test" {
+  name = "%[1]s"
+}
+
+resource "netbox_route_target" "test" {
+  name = "%[1]s"
+}`, testName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("netbox_route_target.test", "description", ""),
+					resource.TestCheckResourceAttr("netbox_route_target.test", "tenant_id", "0"),
+				),
+			},
+			{
+				ResourceName:      "netbox_route_target.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestUnitNetboxRouteTarget_lifecycle(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	tenantID := fake.Seed(t, "tenancy/tenants", map[string]interface{}{"name": "tenant", "slug": "tenant"})
+	r := resourceNetboxRouteTarget()
+
+	config := map[string]interface{}{
+		"name":        "65000:100",
+		"description": "customer a",
+		"tenant_id":   int(tenantID),
+	}
+	state, diags := testUnitApply(t, r, nil, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "65000:100", state.Attributes["name"])
+	assert.Equal(t, "customer a", state.Attributes["description"])
+	assert.Equal(t, strconv.FormatInt(tenantID, 10), state.Attributes["tenant_id"])
+
+	// Removing optional attributes clears them in Netbox
+	config = map[string]interface{}{
+		"name": "65000:100",
+	}
+	state, diags = testUnitApply(t, r, state, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	id, _ := strconv.ParseInt(state.ID, 10, 64)
+	assert.Equal(t, "", fake.Get("ipam/route-targets", id)["description"])
+	assert.Nil(t, fake.Get("ipam/route-targets", id)["tenant"])
+
+	_, diags = testUnitApply(t, r, state, nil, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, 0, fake.Count("ipam/route-targets"))
+}
+
+func init() {
+	resource.AddTestSweepers("netbox_route_target", &resource.Sweeper{
+		Name:         "netbox_route_target",
+		Dependencies: []string{"netbox_vrf"},
+		F: func(region string) error {
+			m, err := sharedClientForRegion(region)
+			if err != nil {
+				return fmt.Errorf("Error getting client: %s", err)
+			}
+			api := m.(*client.NetBoxAPI)
+			params := ipam.NewIpamRouteTargetsListParams()
+			res, err := api.Ipam.IpamRouteTargetsList(params, nil)
+			if err != nil {
+				return err
+			}
+			for _, routeTarget := range res.GetPayload().Results {
+				if strings.HasPrefix(*routeTarget.Name, testPrefix) {
+					deleteParams := ipam.NewIpamRouteTargetsDeleteParams().WithID(routeTarget.ID)
+					_, err := api.Ipam.IpamRouteTargetsDelete(deleteParams, nil)
+					if err != nil {
+						return err
+					}
+					log.Print("[DEBUG] Deleted a route target")
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -1,21 +1,22 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxService() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxServiceCreate,
-		Read:   resourceNetboxServiceRead,
-		Update: resourceNetboxServiceUpdate,
-		Delete: resourceNetboxServiceDelete,
+		CreateContext: resourceNetboxServiceCreate,
+		ReadContext:   resourceNetboxServiceRead,
+		UpdateContext: resourceNetboxServiceUpdate,
+		DeleteContext: resourceNetboxServiceDelete,
 
 		Description: `From the [official documentation](https://docs.netbox.dev/en/stable/core-functionality/services/#services):
 
@@ -23,6 +24,8 @@ func resourceNetboxService() *schema.Resource {
 >
 > A service may optionally be bound to one or more specific IP addresses belonging to its parent device or VM. (If no IP addresses are bound, the service is assumed to be reachable via any assigned IP address.`,
 
+		Timeouts: defaultTimeouts(),
+
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
 				Type:         schema.TypeString,
@@ -53,12 +56,12 @@ func resourceNetboxService() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
-func resourceNetboxServiceCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxServiceCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	data := models.WritableService{}
 
 	dataName := d.Get("name").(string)
@@ -90,25 +93,28 @@ func resourceNetboxServiceCreate(d *schema.ResourceData, m interface{}) error {
 	data.Tags = []*models.NestedTag{}
 	data.Ipaddresses = []int64{}
 
-	params := ipam.NewIpamServicesCreateParams().WithData(&data)
+	params := ipam.NewIpamServicesCreateParams().WithContext(ctx).WithData(&data)
 	res, err := api.Ipam.IpamServicesCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxServiceUpdate(d, m)
+	return resourceNetboxServiceUpdate(ctx, d, m)
 }
 
-func resourceNetboxServiceRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxServiceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamServicesReadParams().WithID(id)
+	params := ipam.NewIpamServicesReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Ipam.IpamServicesRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("name", res.GetPayload().Name)
@@ -119,8 +125,8 @@ func resourceNetboxServiceRead(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func resourceNetboxServiceUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxServiceUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritableService{}
 
@@ -150,21 +156,21 @@ func resourceNetboxServiceUpdate(d *schema.ResourceData, m interface{}) error {
 	dataVirtualMachineID := int64(d.Get("virtual_machine_id").(int))
 	data.VirtualMachine = &dataVirtualMachineID
 
-	params := ipam.NewIpamServicesUpdateParams().WithID(id).WithData(&data)
+	params := ipam.NewIpamServicesUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 	_, err := api.Ipam.IpamServicesUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
-	return resourceNetboxServiceRead(d, m)
+	return resourceNetboxServiceRead(ctx, d, m)
 }
 
-func resourceNetboxServiceDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxServiceDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamServicesDeleteParams().WithID(id)
+	params := ipam.NewIpamServicesDeleteParams().WithContext(ctx).WithID(id)
 	_, err := api.Ipam.IpamServicesDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
@@ -66,9 +66,35 @@ resource "netbox_service" "test" {
 	})
 }
 
+func TestAccNetboxService_deletedOutOfBand(t *testing.T) {
+
+	testSlug := "svc_oob"
+	testName := testAccGetTestName(testSlug)
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNetboxServiceFullDependencies(testName) + fmt.Sprintf(`
+resource "netbox_service" "test" {
+  name = "%s"
+  virtual_machine_id = netbox_virtual_machine.test.id
+  ports = [666]
+  protocol = "tcp"
+}`, testName),
+				Check: testAccDeleteOutOfBand("netbox_service.test", func(api *providerState, id int64) error {
+					_, err := api.Ipam.IpamServicesDelete(ipam.NewIpamServicesDeleteParams().WithID(id), nil)
+					return err
+				}),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
 func testAccCheckServiceDestroy(s *terraform.State) error {
 	// retrieve the connection established in Provider configuration
-	conn := testAccProvider.Meta().(*client.NetBoxAPI)
+	conn := testAccProvider.Meta().(*providerState)
 
 	// loop through the resources in state, verifying each service
 	// is destroyed
@@ -86,8 +112,7 @@ func testAccCheckServiceDestroy(s *terraform.State) error {
 			return fmt.Errorf("service (%s) still exists", rs.Primary.ID)
 		}
 
-		if err != nil {
-
+		if !errorIsNotFound(err) {
 			return err
 		}
 	}
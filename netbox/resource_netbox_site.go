@@ -1,21 +1,25 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/dcim"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxSite() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxSiteCreate,
-		Read:   resourceNetboxSiteRead,
-		Update: resourceNetboxSiteUpdate,
-		Delete: resourceNetboxSiteDelete,
+		CreateContext: resourceNetboxSiteCreate,
+		ReadContext:   resourceNetboxSiteRead,
+		UpdateContext: resourceNetboxSiteUpdate,
+		DeleteContext: resourceNetboxSiteDelete,
+		CustomizeDiff: customizeDiffTagsAll,
+
+		Timeouts: defaultTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
@@ -67,6 +71,7 @@ func resourceNetboxSite() *schema.Resource {
 				Optional: true,
 				Set:      schema.HashString,
 			},
+			"tags_all": tagsAllSchema,
 			"timezone": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
@@ -78,13 +83,13 @@ func resourceNetboxSite() *schema.Resource {
 			customFieldsKey: customFieldsSchema,
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxSiteCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxSiteCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	data := models.WritableSite{}
 
@@ -138,95 +143,102 @@ func resourceNetboxSiteCreate(d *schema.ResourceData, m interface{}) error {
 		data.Asns = asnValue.([]int64)
 	}
 
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	data.Tags = tags
 
 	ct, ok := d.GetOk(customFieldsKey)
 	if ok {
 		data.CustomFields = ct
 	}
 
-	params := dcim.NewDcimSitesCreateParams().WithData(&data)
+	params := dcim.NewDcimSitesCreateParams().WithContext(ctx).WithData(&data)
 
 	res, err := api.Dcim.DcimSitesCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxSiteRead(d, m)
+	return resourceNetboxSiteRead(ctx, d, m)
 }
 
-func resourceNetboxSiteRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxSiteRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := dcim.NewDcimSitesReadParams().WithID(id)
+	params := dcim.NewDcimSitesReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Dcim.DcimSitesRead(params, nil)
 
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	err = d.Set("name", res.GetPayload().Name)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	err = d.Set("slug", res.GetPayload().Slug)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	err = d.Set("status", res.GetPayload().Status.Value)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	err = d.Set("description", res.GetPayload().Description)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	err = d.Set("facility", res.GetPayload().Facility)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	err = d.Set("longitude", res.GetPayload().Longitude)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	err = d.Set("latitude", res.GetPayload().Latitude)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	err = d.Set("timezone", res.GetPayload().TimeZone)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	err = d.Set("asn", res.GetPayload().Asns)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	if res.GetPayload().Region != nil {
 		err = d.Set("region_id", res.GetPayload().Region.ID)
 		if err != nil {
-			return err
+			return errorDiagnostics(err)
 		}
 	} else {
 		err = d.Set("region_id", nil)
 		if err != nil {
-			return err
+			return errorDiagnostics(err)
 		}
 	}
 
 	if res.GetPayload().Tenant != nil {
 		err = d.Set("tenant_id", res.GetPayload().Tenant.ID)
 		if err != nil {
-			return err
+			return errorDiagnostics(err)
 		}
 	} else {
 		err = d.Set("tenant_id", nil)
 		if err != nil {
-			return err
+			return errorDiagnostics(err)
 		}
 	}
 
@@ -234,16 +246,16 @@ func resourceNetboxSiteRead(d *schema.ResourceData, m interface{}) error {
 	if cf != nil {
 		d.Set(customFieldsKey, cf)
 	}
-	err = d.Set("tags", getTagListFromNestedTagList(res.GetPayload().Tags))
+	err = setTagsFromNestedTagList(api, d, res.GetPayload().Tags)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	return nil
 }
 
-func resourceNetboxSiteUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxSiteUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritableSite{}
@@ -298,32 +310,36 @@ func resourceNetboxSiteUpdate(d *schema.ResourceData, m interface{}) error {
 		data.Asns = asnValue.([]int64)
 	}
 
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	data.Tags = tags
 
 	cf, ok := d.GetOk(customFieldsKey)
 	if ok {
 		data.CustomFields = cf
 	}
 
-	params := dcim.NewDcimSitesPartialUpdateParams().WithID(id).WithData(&data)
+	params := dcim.NewDcimSitesPartialUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
-	_, err := api.Dcim.DcimSitesPartialUpdate(params, nil)
+	_, err = api.Dcim.DcimSitesPartialUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxSiteRead(d, m)
+	return resourceNetboxSiteRead(ctx, d, m)
 }
 
-func resourceNetboxSiteDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxSiteDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := dcim.NewDcimSitesDeleteParams().WithID(id)
+	params := dcim.NewDcimSitesDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Dcim.DcimSitesDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
@@ -1,22 +1,25 @@
 package netbox
 
 import (
+	"context"
 	"regexp"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/extras"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxTag() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxTagCreate,
-		Read:   resourceNetboxTagRead,
-		Update: resourceNetboxTagUpdate,
-		Delete: resourceNetboxTagDelete,
+		CreateContext: resourceNetboxTagCreate,
+		ReadContext:   resourceNetboxTagRead,
+		UpdateContext: resourceNetboxTagUpdate,
+		DeleteContext: resourceNetboxTagDelete,
+
+		Timeouts: defaultTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
@@ -48,13 +51,13 @@ func resourceNetboxTag() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxTagCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxTagCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	name := d.Get("name").(string)
 
@@ -69,7 +72,7 @@ func resourceNetboxTagCreate(d *schema.ResourceData, m interface{}) error {
 
 	color := d.Get("color_hex").(string)
 	description := d.Get("description").(string)
-	params := extras.NewExtrasTagsCreateParams().WithData(
+	params := extras.NewExtrasTagsCreateParams().WithContext(ctx).WithData(
 		&models.Tag{
 			Name:        &name,
 			Slug:        &slug,
@@ -81,23 +84,26 @@ func resourceNetboxTagCreate(d *schema.ResourceData, m interface{}) error {
 	res, err := api.Extras.ExtrasTagsCreate(params, nil)
 	if err != nil {
 		//return errors.New(getTextFromError(err))
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxTagRead(d, m)
+	return resourceNetboxTagRead(ctx, d, m)
 }
 
-func resourceNetboxTagRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxTagRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := extras.NewExtrasTagsReadParams().WithID(id)
+	params := extras.NewExtrasTagsReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Extras.ExtrasTagsRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("name", res.GetPayload().Name)
@@ -107,8 +113,8 @@ func resourceNetboxTagRead(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func resourceNetboxTagUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxTagUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.Tag{}
@@ -131,25 +137,27 @@ func resourceNetboxTagUpdate(d *schema.ResourceData, m interface{}) error {
 	data.Color = color
 	data.Description = description
 
-	params := extras.NewExtrasTagsUpdateParams().WithID(id).WithData(&data)
+	params := extras.NewExtrasTagsUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
 	_, err := api.Extras.ExtrasTagsUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
+	api.tagCache.invalidate()
 
-	return resourceNetboxTagRead(d, m)
+	return resourceNetboxTagRead(ctx, d, m)
 }
 
-func resourceNetboxTagDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxTagDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := extras.NewExtrasTagsDeleteParams().WithID(id)
+	params := extras.NewExtrasTagsDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Extras.ExtrasTagsDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
+	api.tagCache.invalidate()
 	return nil
 }
@@ -1,21 +1,25 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/tenancy"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxTenant() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxTenantCreate,
-		Read:   resourceNetboxTenantRead,
-		Update: resourceNetboxTenantUpdate,
-		Delete: resourceNetboxTenantDelete,
+		CreateContext: resourceNetboxTenantCreate,
+		ReadContext:   resourceNetboxTenantRead,
+		UpdateContext: resourceNetboxTenantUpdate,
+		DeleteContext: resourceNetboxTenantDelete,
+		CustomizeDiff: customizeDiffTagsAll,
+
+		Timeouts: defaultTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
@@ -36,19 +40,20 @@ func resourceNetboxTenant() *schema.Resource {
 				Optional: true,
 				Set:      schema.HashString,
 			},
+			"tags_all": tagsAllSchema,
 			"group_id": &schema.Schema{
 				Type:     schema.TypeInt,
 				Optional: true,
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxTenantCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxTenantCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	name := d.Get("name").(string)
 	group_id := int64(d.Get("group_id").(int))
@@ -61,7 +66,10 @@ func resourceNetboxTenantCreate(d *schema.ResourceData, m interface{}) error {
 		slug = slugValue.(string)
 	}
 
-	tags, _ := getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
 
 	data := &models.WritableTenant{}
 
@@ -73,27 +81,30 @@ func resourceNetboxTenantCreate(d *schema.ResourceData, m interface{}) error {
 		data.Group = &group_id
 	}
 
-	params := tenancy.NewTenancyTenantsCreateParams().WithData(data)
+	params := tenancy.NewTenancyTenantsCreateParams().WithContext(ctx).WithData(data)
 
 	res, err := api.Tenancy.TenancyTenantsCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxTenantRead(d, m)
+	return resourceNetboxTenantRead(ctx, d, m)
 }
 
-func resourceNetboxTenantRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxTenantRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := tenancy.NewTenancyTenantsReadParams().WithID(id)
+	params := tenancy.NewTenancyTenantsReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Tenancy.TenancyTenantsRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("name", res.GetPayload().Name)
@@ -101,12 +112,13 @@ func resourceNetboxTenantRead(d *schema.ResourceData, m interface{}) error {
 	if res.GetPayload().Group != nil {
 		d.Set("group_id", res.GetPayload().Group.ID)
 	}
+	setTagsFromNestedTagList(api, d, res.GetPayload().Tags)
 
 	return nil
 }
 
-func resourceNetboxTenantUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxTenantUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritableTenant{}
@@ -122,7 +134,10 @@ func resourceNetboxTenantUpdate(d *schema.ResourceData, m interface{}) error {
 		slug = slugValue.(string)
 	}
 
-	tags, _ := getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
 
 	data.Slug = &slug
 	data.Name = &name
@@ -131,25 +146,25 @@ func resourceNetboxTenantUpdate(d *schema.ResourceData, m interface{}) error {
 		data.Group = &group_id
 	}
 
-	params := tenancy.NewTenancyTenantsPartialUpdateParams().WithID(id).WithData(&data)
+	params := tenancy.NewTenancyTenantsPartialUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
-	_, err := api.Tenancy.TenancyTenantsPartialUpdate(params, nil)
+	_, err = api.Tenancy.TenancyTenantsPartialUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxTenantRead(d, m)
+	return resourceNetboxTenantRead(ctx, d, m)
 }
 
-func resourceNetboxTenantDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxTenantDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := tenancy.NewTenancyTenantsDeleteParams().WithID(id)
+	params := tenancy.NewTenancyTenantsDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Tenancy.TenancyTenantsDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
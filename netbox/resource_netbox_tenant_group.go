@@ -1,21 +1,24 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/tenancy"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxTenantGroup() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxTenantGroupCreate,
-		Read:   resourceNetboxTenantGroupRead,
-		Update: resourceNetboxTenantGroupUpdate,
-		Delete: resourceNetboxTenantGroupDelete,
+		CreateContext: resourceNetboxTenantGroupCreate,
+		ReadContext:   resourceNetboxTenantGroupRead,
+		UpdateContext: resourceNetboxTenantGroupUpdate,
+		DeleteContext: resourceNetboxTenantGroupDelete,
+
+		Timeouts: defaultTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
@@ -38,13 +41,13 @@ func resourceNetboxTenantGroup() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxTenantGroupCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxTenantGroupCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	name := d.Get("name").(string)
 	parent_id := int64(d.Get("parent_id").(int))
@@ -67,28 +70,31 @@ func resourceNetboxTenantGroupCreate(d *schema.ResourceData, m interface{}) erro
 		data.Parent = &parent_id
 	}
 
-	params := tenancy.NewTenancyTenantGroupsCreateParams().WithData(data)
+	params := tenancy.NewTenancyTenantGroupsCreateParams().WithContext(ctx).WithData(data)
 
 	res, err := api.Tenancy.TenancyTenantGroupsCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxTenantGroupRead(d, m)
+	return resourceNetboxTenantGroupRead(ctx, d, m)
 }
 
-func resourceNetboxTenantGroupRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxTenantGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 
-	params := tenancy.NewTenancyTenantGroupsReadParams().WithID(id)
+	params := tenancy.NewTenancyTenantGroupsReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Tenancy.TenancyTenantGroupsRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	d.Set("name", res.GetPayload().Name)
@@ -100,8 +106,8 @@ func resourceNetboxTenantGroupRead(d *schema.ResourceData, m interface{}) error
 	return nil
 }
 
-func resourceNetboxTenantGroupUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxTenantGroupUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritableTenantGroup{}
@@ -125,25 +131,25 @@ func resourceNetboxTenantGroupUpdate(d *schema.ResourceData, m interface{}) erro
 	if parent_id != 0 {
 		data.Parent = &parent_id
 	}
-	params := tenancy.NewTenancyTenantGroupsPartialUpdateParams().WithID(id).WithData(&data)
+	params := tenancy.NewTenancyTenantGroupsPartialUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 
 	_, err := api.Tenancy.TenancyTenantGroupsPartialUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxTenantGroupRead(d, m)
+	return resourceNetboxTenantGroupRead(ctx, d, m)
 }
 
-func resourceNetboxTenantGroupDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxTenantGroupDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := tenancy.NewTenancyTenantGroupsDeleteParams().WithID(id)
+	params := tenancy.NewTenancyTenantGroupsDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Tenancy.TenancyTenantGroupsDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
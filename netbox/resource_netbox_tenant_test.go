@@ -3,12 +3,15 @@ package netbox
 import (
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/tenancy"
+	"github.com/stretchr/testify/assert"
 )
 
 func testAccNetboxTenantTagDependencies(testName string) string {
@@ -52,6 +55,72 @@ resource "netbox_tenant" "test" {
 	})
 }
 
+func TestUnitNetboxTenant_basic(t *testing.T) {
+	testUnitPreCheck(t)
+	fake := newFakeNetbox(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProviderFactories: testUnitProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fake.providerConfig() + `
+resource "netbox_tag" "test" {
+  name = "unit"
+}
+
+resource "netbox_tenant" "test" {
+  name = "unit-tenant"
+  tags = [netbox_tag.test.name]
+}`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("netbox_tenant.test", "name", "unit-tenant"),
+					resource.TestCheckResourceAttr("netbox_tenant.test", "slug", "unit-tenant"),
+					resource.TestCheckResourceAttr("netbox_tenant.test", "tags.#", "1"),
+				),
+			},
+			{
+				ResourceName:      "netbox_tenant.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestUnitNetboxTenant_lifecycle(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	groupID := fake.Seed(t, "tenancy/tenant-groups", map[string]interface{}{"name": "group", "slug": "group"})
+	fake.Seed(t, "extras/tags", map[string]interface{}{"name": "unit", "slug": "unit"})
+	r := resourceNetboxTenant()
+
+	state, diags := testUnitApply(t, r, nil, map[string]interface{}{
+		"name":     "unit-tenant",
+		"group_id": int(groupID),
+		"tags":     []interface{}{"unit"},
+	}, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "unit-tenant", state.Attributes["name"])
+	assert.Equal(t, "unit-tenant", state.Attributes["slug"])
+	assert.Equal(t, "1", state.Attributes["tags_all.#"])
+
+	id, _ := strconv.ParseInt(state.ID, 10, 64)
+	assert.Equal(t, "group", fake.Get("tenancy/tenants", id)["group"].(map[string]interface{})["slug"])
+
+	state, diags = testUnitApply(t, r, state, map[string]interface{}{
+		"name": "renamed",
+		"slug": "unit-tenant",
+	}, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "renamed", fake.Get("tenancy/tenants", id)["name"])
+	assert.Equal(t, "0", state.Attributes["tags.#"])
+
+	fake.Delete("tenancy/tenants", id)
+	state, diags = testUnitRefresh(t, r, state, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Nil(t, state)
+}
+
 func TestAccNetboxTenant_defaultSlug(t *testing.T) {
 
 	testSlug := "tenant_defSlug"
@@ -74,6 +143,61 @@ resource "netbox_tenant" "test" {
 	})
 }
 
+func TestAccNetboxTenant_defaultTags(t *testing.T) {
+
+	testSlug := "tenant_defTags"
+	testName := testAccGetTestName(testSlug)
+	resource.ParallelTest(t, resource.TestCase{
+		ProviderFactories: map[string]func() (*schema.Provider, error){
+			"netbox": func() (*schema.Provider, error) {
+				return Provider(), nil
+			},
+		},
+		PreCheck: func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNetboxTenantTagDependencies(testName) + fmt.Sprintf(`
+provider "netbox" {
+  default_tags {
+    tags = ["%[1]sa"]
+  }
+}
+
+resource "netbox_tenant" "test" {
+  name = "%[1]s"
+  tags = ["%[1]sb"]
+
+  depends_on = [netbox_tag.test_a, netbox_tag.test_b]
+}`, testName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("netbox_tenant.test", "tags.#", "1"),
+					resource.TestCheckResourceAttr("netbox_tenant.test", "tags.0", testName+"b"),
+					resource.TestCheckResourceAttr("netbox_tenant.test", "tags_all.#", "2"),
+				),
+			},
+			{
+				Config: testAccNetboxTenantTagDependencies(testName) + fmt.Sprintf(`
+provider "netbox" {
+  default_tags {
+    tags = ["%[1]sa"]
+  }
+}
+
+resource "netbox_tenant" "test" {
+  name = "%[1]s"
+
+  depends_on = [netbox_tag.test_a, netbox_tag.test_b]
+}`, testName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("netbox_tenant.test", "tags.#", "0"),
+					resource.TestCheckResourceAttr("netbox_tenant.test", "tags_all.#", "1"),
+					resource.TestCheckResourceAttr("netbox_tenant.test", "tags_all.0", testName+"a"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccNetboxTenant_tags(t *testing.T) {
 
 	testSlug := "tenant_tags"
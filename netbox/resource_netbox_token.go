@@ -1,21 +1,24 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/users"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxToken() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxTokenCreate,
-		Read:   resourceNetboxTokenRead,
-		Update: resourceNetboxTokenUpdate,
-		Delete: resourceNetboxTokenDelete,
+		CreateContext: resourceNetboxTokenCreate,
+		ReadContext:   resourceNetboxTokenRead,
+		UpdateContext: resourceNetboxTokenUpdate,
+		DeleteContext: resourceNetboxTokenDelete,
+
+		Timeouts: defaultTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"user_id": &schema.Schema{
@@ -30,13 +33,13 @@ func resourceNetboxToken() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxTokenCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxTokenCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	data := models.WritableToken{}
 
 	userid := int64(d.Get("user_id").(int))
@@ -46,25 +49,28 @@ func resourceNetboxTokenCreate(d *schema.ResourceData, m interface{}) error {
 	data.User = &userid
 	data.Key = key
 
-	params := users.NewUsersTokensCreateParams().WithData(&data)
+	params := users.NewUsersTokensCreateParams().WithContext(ctx).WithData(&data)
 	res, err := api.Users.UsersTokensCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxTokenUpdate(d, m)
+	return resourceNetboxTokenUpdate(ctx, d, m)
 }
 
-func resourceNetboxTokenRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxTokenRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := users.NewUsersTokensReadParams().WithID(id)
+	params := users.NewUsersTokensReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Users.UsersTokensRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	if res.GetPayload().User != nil {
@@ -76,8 +82,8 @@ func resourceNetboxTokenRead(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func resourceNetboxTokenUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxTokenUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritableToken{}
 
@@ -87,21 +93,21 @@ func resourceNetboxTokenUpdate(d *schema.ResourceData, m interface{}) error {
 	data.User = &userid
 	data.Key = key
 
-	params := users.NewUsersTokensUpdateParams().WithID(id).WithData(&data)
+	params := users.NewUsersTokensUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 	_, err := api.Users.UsersTokensUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
-	return resourceNetboxTokenRead(d, m)
+	return resourceNetboxTokenRead(ctx, d, m)
 }
 
-func resourceNetboxTokenDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxTokenDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := users.NewUsersTokensDeleteParams().WithID(id)
+	params := users.NewUsersTokensDeleteParams().WithContext(ctx).WithID(id)
 	_, err := api.Users.UsersTokensDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	d.SetId("")
 	return nil
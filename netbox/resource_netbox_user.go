@@ -1,20 +1,23 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/users"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxUser() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxUserCreate,
-		Read:   resourceNetboxUserRead,
-		Update: resourceNetboxUserUpdate,
-		Delete: resourceNetboxUserDelete,
+		CreateContext: resourceNetboxUserCreate,
+		ReadContext:   resourceNetboxUserRead,
+		UpdateContext: resourceNetboxUserUpdate,
+		DeleteContext: resourceNetboxUserDelete,
+
+		Timeouts: defaultTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"username": &schema.Schema{
@@ -38,12 +41,12 @@ func resourceNetboxUser() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
-func resourceNetboxUserCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxUserCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	data := models.WritableUser{}
 
 	username := d.Get("username").(string)
@@ -58,25 +61,28 @@ func resourceNetboxUserCreate(d *schema.ResourceData, m interface{}) error {
 
 	data.Groups = []int64{}
 
-	params := users.NewUsersUsersCreateParams().WithData(&data)
+	params := users.NewUsersUsersCreateParams().WithContext(ctx).WithData(&data)
 	res, err := api.Users.UsersUsersCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxUserRead(d, m)
+	return resourceNetboxUserRead(ctx, d, m)
 }
 
-func resourceNetboxUserRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxUserRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := users.NewUsersUsersReadParams().WithID(id)
+	params := users.NewUsersUsersReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Users.UsersUsersRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	if res.GetPayload().Username != nil {
@@ -91,8 +97,8 @@ func resourceNetboxUserRead(d *schema.ResourceData, m interface{}) error {
 	return nil
 }
 
-func resourceNetboxUserUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxUserUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritableUser{}
 
@@ -108,21 +114,21 @@ func resourceNetboxUserUpdate(d *schema.ResourceData, m interface{}) error {
 
 	data.Groups = []int64{}
 
-	params := users.NewUsersUsersUpdateParams().WithID(id).WithData(&data)
+	params := users.NewUsersUsersUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
 	_, err := api.Users.UsersUsersUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
-	return resourceNetboxUserRead(d, m)
+	return resourceNetboxUserRead(ctx, d, m)
 }
 
-func resourceNetboxUserDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxUserDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := users.NewUsersUsersDeleteParams().WithID(id)
+	params := users.NewUsersUsersDeleteParams().WithContext(ctx).WithID(id)
 	_, err := api.Users.UsersUsersDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	d.SetId("")
 	return nil
@@ -1,21 +1,25 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxVlan() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxVlanCreate,
-		Read:   resourceNetboxVlanRead,
-		Update: resourceNetboxVlanUpdate,
-		Delete: resourceNetboxVlanDelete,
+		CreateContext: resourceNetboxVlanCreate,
+		ReadContext:   resourceNetboxVlanRead,
+		UpdateContext: resourceNetboxVlanUpdate,
+		DeleteContext: resourceNetboxVlanDelete,
+		CustomizeDiff: customizeDiffTagsAll,
+
+		Timeouts: defaultTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"name": &schema.Schema{
@@ -45,6 +49,11 @@ func resourceNetboxVlan() *schema.Resource {
 				Type:     schema.TypeInt,
 				Optional: true,
 			},
+			"group_id": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Description: "ID of the VLAN group the VLAN belongs to.",
+			},
 			"description": &schema.Schema{
 				Type:     schema.TypeString,
 				Optional: true,
@@ -57,15 +66,16 @@ func resourceNetboxVlan() *schema.Resource {
 				Required: true,
 				Set:      schema.HashString,
 			},
+			"tags_all": tagsAllSchema,
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxVlanCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxVlanCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	data := models.WritableVLAN{}
 
 	name := d.Get("name").(string)
@@ -90,27 +100,38 @@ func resourceNetboxVlanCreate(d *schema.ResourceData, m interface{}) error {
 		data.Role = int64ToPtr(int64(roleID.(int)))
 	}
 
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	if groupID, ok := d.GetOk("group_id"); ok {
+		data.Group = int64ToPtr(int64(groupID.(int)))
+	}
 
-	params := ipam.NewIpamVlansCreateParams().WithData(&data)
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	data.Tags = tags
+
+	params := ipam.NewIpamVlansCreateParams().WithContext(ctx).WithData(&data)
 	res, err := api.Ipam.IpamVlansCreate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxVlanRead(d, m)
+	return resourceNetboxVlanRead(ctx, d, m)
 }
 
-func resourceNetboxVlanRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxVlanRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamVlansReadParams().WithID(id)
+	params := ipam.NewIpamVlansReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Ipam.IpamVlansRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
 	if res.GetPayload().Name != nil {
@@ -141,13 +162,19 @@ func resourceNetboxVlanRead(d *schema.ResourceData, m interface{}) error {
 		d.Set("role_id", res.GetPayload().Role.ID)
 	}
 
-	d.Set("tags", getTagListFromNestedTagList(res.GetPayload().Tags))
+	if res.GetPayload().Group != nil {
+		d.Set("group_id", res.GetPayload().Group.ID)
+	} else {
+		d.Set("group_id", nil)
+	}
+
+	setTagsFromNestedTagList(api, d, res.GetPayload().Tags)
 
 	return nil
 }
 
-func resourceNetboxVlanUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxVlanUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
 	data := models.WritableVLAN{}
 	name := d.Get("name").(string)
@@ -173,23 +200,31 @@ func resourceNetboxVlanUpdate(d *schema.ResourceData, m interface{}) error {
 		data.Role = int64ToPtr(int64(roleID.(int)))
 	}
 
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+	if groupID, ok := d.GetOk("group_id"); ok {
+		data.Group = int64ToPtr(int64(groupID.(int)))
+	}
+
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	data.Tags = tags
 
-	params := ipam.NewIpamVlansUpdateParams().WithID(id).WithData(&data)
-	_, err := api.Ipam.IpamVlansUpdate(params, nil)
+	params := ipam.NewIpamVlansUpdateParams().WithContext(ctx).WithID(id).WithData(&data)
+	_, err = api.Ipam.IpamVlansUpdate(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
-	return resourceNetboxVlanRead(d, m)
+	return resourceNetboxVlanRead(ctx, d, m)
 }
 
-func resourceNetboxVlanDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxVlanDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamVlansDeleteParams().WithID(id)
+	params := ipam.NewIpamVlansDeleteParams().WithContext(ctx).WithID(id)
 	_, err := api.Ipam.IpamVlansDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	return nil
@@ -0,0 +1,274 @@
+package netbox
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-openapi/runtime"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/netbox-community/go-netbox/netbox/client/ipam"
+	"github.com/netbox-community/go-netbox/netbox/models"
+)
+
+// vlanGroupScopeTypes are the object types a VLAN group can be scoped to.
+var vlanGroupScopeTypes = []string{
+	"dcim.location",
+	"dcim.rack",
+	"dcim.region",
+	"dcim.site",
+	"dcim.sitegroup",
+	"virtualization.cluster",
+	"virtualization.clustergroup",
+}
+
+// The VLAN ID range of VLAN groups that Netbox before 3.2 implies, as it has
+// no VLAN ID ranges.
+const (
+	vlanGroupDefaultMinVID = 1
+	vlanGroupDefaultMaxVID = 4094
+)
+
+func resourceNetboxVlanGroup() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceNetboxVlanGroupCreate,
+		ReadContext:   resourceNetboxVlanGroupRead,
+		UpdateContext: resourceNetboxVlanGroupUpdate,
+		DeleteContext: resourceNetboxVlanGroupDelete,
+		CustomizeDiff: customizeDiffTagsAll,
+
+		Description: `From the [official documentation](https://docs.netbox.dev/en/stable/core-functionality/vlans/#vlan-groups):
+
+> VLAN groups can be used to organize VLANs within NetBox. Each VLAN group can be scoped to a particular region, site group, site, location, rack, cluster group, or cluster. Member VLANs will be available for assignment to devices and/or virtual machines within the specified scope.`,
+
+		Timeouts: defaultTimeouts(),
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"slug": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringLenBetween(1, 100),
+				Description:  "Defaults to `name`.",
+			},
+			"scope_type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice(vlanGroupScopeTypes, false),
+				RequiredWith: []string{"scope_id"},
+				Description:  "Type of the object the VLAN group is scoped to, like `dcim.site` or `virtualization.cluster`.",
+			},
+			"scope_id": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				RequiredWith: []string{"scope_type"},
+				Description:  "ID of the object the VLAN group is scoped to.",
+			},
+			"min_vid": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      vlanGroupDefaultMinVID,
+				ValidateFunc: validation.IntBetween(1, 4094),
+				Description:  "Lowest VLAN ID of the VLANs in the group. Values other than the default require Netbox >= 3.2.0.",
+			},
+			"max_vid": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      vlanGroupDefaultMaxVID,
+				ValidateFunc: validation.IntBetween(1, 4094),
+				Description:  "Highest VLAN ID of the VLANs in the group. Values other than the default require Netbox >= 3.2.0.",
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"tags": {
+				Type: schema.TypeSet,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Optional: true,
+				Set:      schema.HashString,
+			},
+			"tags_all": tagsAllSchema,
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+// readVLANGroup makes an operation decode the VLAN group it returns into
+// group and return result. The generated client fails to decode the scope of
+// VLAN groups, which Netbox returns as object instead of the string the
+// model expects, so it is skipped.
+func readVLANGroup(group *models.VLANGroup, result interface{}) func(*runtime.ClientOperation) {
+	return withResponseBody(&struct {
+		*models.VLANGroup
+		Scope interface{} `json:"scope"`
+	}{VLANGroup: group}, result)
+}
+
+// requireVLANGroupVIDRange returns an error diagnostic if the VLAN ID range
+// of the group is not the default range and the Netbox server is older than
+// 3.2, which would silently ignore it.
+func requireVLANGroupVIDRange(api *providerState, d *schema.ResourceData) diag.Diagnostics {
+	if d.Get("min_vid").(int) != vlanGroupDefaultMinVID {
+		if diags := api.requireNetboxVersion("min_vid", "3.2.0"); diags.HasError() {
+			return diags
+		}
+	}
+	if d.Get("max_vid").(int) != vlanGroupDefaultMaxVID {
+		if diags := api.requireNetboxVersion("max_vid", "3.2.0"); diags.HasError() {
+			return diags
+		}
+	}
+	return nil
+}
+
+func getVLANGroupFromResourceData(ctx context.Context, api *providerState, d *schema.ResourceData) (*models.VLANGroup, error) {
+	name := d.Get("name").(string)
+	slug := name
+	if value, ok := d.GetOk("slug"); ok {
+		slug = value.(string)
+	}
+
+	data := models.VLANGroup{
+		Name:        &name,
+		Slug:        &slug,
+		MinVid:      int64(d.Get("min_vid").(int)),
+		MaxVid:      int64(d.Get("max_vid").(int)),
+		Description: d.Get("description").(string),
+	}
+	if scopeType, ok := d.GetOk("scope_type"); ok {
+		data.ScopeType = scopeType.(string)
+		data.ScopeID = int64ToPtr(int64(d.Get("scope_id").(int)))
+	}
+
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return nil, err
+	}
+	data.Tags = tags
+	return &data, nil
+}
+
+func resourceNetboxVlanGroupCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+
+	if diags := requireVLANGroupVIDRange(api, d); diags.HasError() {
+		return diags
+	}
+
+	data, err := getVLANGroupFromResourceData(ctx, api, d)
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+
+	group := &models.VLANGroup{}
+	result := ipam.NewIpamVlanGroupsCreateCreated()
+	result.Payload = group
+	params := ipam.NewIpamVlanGroupsCreateParams().WithContext(ctx).WithData(data)
+	_, err = api.Ipam.IpamVlanGroupsCreate(params, nil, readVLANGroup(group, result))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	d.SetId(strconv.FormatInt(group.ID, 10))
+
+	return resourceNetboxVlanGroupRead(ctx, d, m)
+}
+
+func resourceNetboxVlanGroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+	id, _ := strconv.ParseInt(d.Id(), 10, 64)
+
+	group := &models.VLANGroup{}
+	result := ipam.NewIpamVlanGroupsReadOK()
+	result.Payload = group
+	params := ipam.NewIpamVlanGroupsReadParams().WithContext(ctx).WithID(id)
+	_, err := api.Ipam.IpamVlanGroupsRead(params, nil, readVLANGroup(group, result))
+	if err != nil {
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
+	}
+
+	d.Set("name", group.Name)
+	d.Set("slug", group.Slug)
+	d.Set("description", group.Description)
+
+	// Netbox before 3.2 does not return a VLAN ID range
+	if group.MinVid == 0 && group.MaxVid == 0 {
+		d.Set("min_vid", vlanGroupDefaultMinVID)
+		d.Set("max_vid", vlanGroupDefaultMaxVID)
+	} else {
+		d.Set("min_vid", group.MinVid)
+		d.Set("max_vid", group.MaxVid)
+	}
+
+	if group.ScopeType != "" && group.ScopeID != nil {
+		d.Set("scope_type", group.ScopeType)
+		d.Set("scope_id", group.ScopeID)
+	} else {
+		d.Set("scope_type", nil)
+		d.Set("scope_id", nil)
+	}
+
+	setTagsFromNestedTagList(api, d, group.Tags)
+
+	return nil
+}
+
+func resourceNetboxVlanGroupUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+	id, _ := strconv.ParseInt(d.Id(), 10, 64)
+
+	if diags := requireVLANGroupVIDRange(api, d); diags.HasError() {
+		return diags
+	}
+
+	data, err := getVLANGroupFromResourceData(ctx, api, d)
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+
+	// The model omits an empty scope, which would keep the current scope
+	body := struct {
+		*models.VLANGroup
+		ScopeType *string `json:"scope_type"`
+		ScopeID   *int64  `json:"scope_id"`
+	}{VLANGroup: data, ScopeID: data.ScopeID}
+	if data.ScopeType != "" {
+		body.ScopeType = &data.ScopeType
+	}
+
+	result := ipam.NewIpamVlanGroupsUpdateOK()
+	result.Payload = &models.VLANGroup{}
+	params := ipam.NewIpamVlanGroupsUpdateParams().WithContext(ctx).WithID(id).WithData(data)
+	_, err = api.Ipam.IpamVlanGroupsUpdate(params, nil, withRequestBody(params, body), readVLANGroup(result.Payload, result))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+
+	return resourceNetboxVlanGroupRead(ctx, d, m)
+}
+
+func resourceNetboxVlanGroupDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+	id, _ := strconv.ParseInt(d.Id(), 10, 64)
+
+	params := ipam.NewIpamVlanGroupsDeleteParams().WithContext(ctx).WithID(id)
+	_, err := api.Ipam.IpamVlanGroupsDelete(params, nil)
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package netbox
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccNetboxVlanGroup_basic(t *testing.T) {
+
+	testSlug := "vlan_group_basic"
+	testName := testAccGetTestName(testSlug)
+	resource.ParallelTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		PreCheck: func() {
+			testAccPreCheck(t)
+			testAccPreCheckNetboxVersion(t, "3.2.0")
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "netbox_tag" "test" {
+  name = "%[1]s"
+}
+
+resource "netbox_site" "test" {
+  name = "%[1]s"
+  status = "active"
+}
+
+resource "netbox_vlan_group" "test" {
+  name = "%[1]s"
+  scope_type = "dcim.site"
+  scope_id = netbox_site.test.id
+  min_vid = 100
+  max_vid = 199
+  description = "%[1]s"
+  tags = [netbox_tag.test.name]
+}`, testName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("netbox_vlan_group.test", "name", testName),
+					resource.TestCheckResourceAttr("netbox_vlan_group.test", "slug", testName),
+					resource.TestCheckResourceAttr("netbox_vlan_group.test", "scope_type", "dcim.site"),
+					resource.TestCheckResourceAttrPair("netbox_vlan_group.test", "scope_id", "netbox_site.test", "id"),
+					resource.TestCheckResourceAttr("netbox_vlan_group.test", "min_vid", "100"),
+					resource.TestCheckResourceAttr("netbox_vlan_group.test", "max_vid", "199"),
+					resource.TestCheckResourceAttr("netbox_vlan_group.test", "tags.#", "1"),
+				),
+			},
+			{
+				ResourceName:      "netbox_vlan_group.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestUnitNetboxVlanGroup_lifecycle(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	siteID := fake.Seed(t, "dcim/sites", map[string]interface{}{"name": "site", "slug": "site", "status": "active"})
+	r := resourceNetboxVlanGroup()
+
+	config := map[string]interface{}{
+		"name":       "group",
+		"scope_type": "dcim.site",
+		"scope_id":   int(siteID),
+		"min_vid":    100,
+		"max_vid":    199,
+	}
+	state, diags := testUnitApply(t, r, nil, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "group", state.Attributes["slug"])
+	assert.Equal(t, "dcim.site", state.Attributes["scope_type"])
+	assert.Equal(t, fmt.Sprint(siteID), state.Attributes["scope_id"])
+	assert.Equal(t, "100", state.Attributes["min_vid"])
+	assert.Equal(t, "199", state.Attributes["max_vid"])
+
+	id, _ := strconv.ParseInt(state.ID, 10, 64)
+	assert.Equal(t, siteID, fake.Get("ipam/vlan-groups", id)["scope"].(map[string]interface{})["id"])
+
+	config = map[string]interface{}{
+		"name": "group",
+	}
+	state, diags = testUnitApply(t, r, state, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "", state.Attributes["scope_type"])
+	assert.Nil(t, fake.Get("ipam/vlan-groups", id)["scope"])
+	assert.Equal(t, "1", state.Attributes["min_vid"])
+	assert.Equal(t, "4094", state.Attributes["max_vid"])
+
+	_, diags = testUnitApply(t, r, state, nil, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, 0, fake.Count("ipam/vlan-groups"))
+}
+
+func TestUnitNetboxVlanGroup_requiresNetboxVersion(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	api.netboxVersion = version.Must(version.NewVersion("3.1.9"))
+	r := resourceNetboxVlanGroup()
+
+	_, diags := testUnitApply(t, r, nil, map[string]interface{}{
+		"name":    "group",
+		"min_vid": 100,
+	}, api)
+	assert.True(t, diags.HasError())
+	assert.Equal(t, "Attribute min_vid requires Netbox >= 3.2.0", diags[0].Summary)
+	assert.Equal(t, 0, fake.Count("ipam/vlan-groups"))
+
+	state, diags := testUnitApply(t, r, nil, map[string]interface{}{
+		"name": "group",
+	}, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+
+	_, diags = testUnitApply(t, r, state, map[string]interface{}{
+		"name":    "group",
+		"max_vid": 199,
+	}, api)
+	assert.True(t, diags.HasError())
+	assert.Equal(t, "Attribute max_vid requires Netbox >= 3.2.0", diags[0].Summary)
+}
+
+func TestUnitNetboxVlanGroup_readWithoutVIDRange(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	// Netbox before 3.2 returns VLAN groups without a VLAN ID range
+	id := fake.Seed(t, "ipam/vlan-groups", map[string]interface{}{"name": "group", "slug": "group"})
+
+	state := &terraform.InstanceState{ID: strconv.FormatInt(id, 10), Attributes: map[string]string{}}
+	state, diags := testUnitRefresh(t, resourceNetboxVlanGroup(), state, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "1", state.Attributes["min_vid"])
+	assert.Equal(t, "4094", state.Attributes["max_vid"])
+}
@@ -3,12 +3,14 @@ package netbox
 import (
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
+	"github.com/stretchr/testify/assert"
 )
 
 func testAccNetboxVlanFullDependencies(testName string) string {
@@ -132,3 +134,25 @@ func init() {
 		},
 	})
 }
+
+func TestUnitNetboxVlan_group(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	groupID := fake.Seed(t, "ipam/vlan-groups", map[string]interface{}{"name": "group", "slug": "group"})
+	r := resourceNetboxVlan()
+
+	config := map[string]interface{}{
+		"name":     "vlan",
+		"vid":      10,
+		"group_id": int(groupID),
+		"tags":     []interface{}{},
+	}
+	state, diags := testUnitApply(t, r, nil, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, strconv.FormatInt(groupID, 10), state.Attributes["group_id"])
+
+	delete(config, "group_id")
+	state, diags = testUnitApply(t, r, state, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "0", state.Attributes["group_id"])
+}
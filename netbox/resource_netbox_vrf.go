@@ -1,26 +1,63 @@
 package netbox
 
 import (
+	"context"
 	"strconv"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/netbox-community/go-netbox/netbox/client"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
 func resourceNetboxVrf() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceNetboxVrfCreate,
-		Read:   resourceNetboxVrfRead,
-		Update: resourceNetboxVrfUpdate,
-		Delete: resourceNetboxVrfDelete,
+		CreateContext: resourceNetboxVrfCreate,
+		ReadContext:   resourceNetboxVrfRead,
+		UpdateContext: resourceNetboxVrfUpdate,
+		DeleteContext: resourceNetboxVrfDelete,
+		CustomizeDiff: customizeDiffTagsAll,
+
+		Timeouts: defaultTimeouts(),
 
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
 				Required: true,
 			},
+			"rd": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringLenBetween(1, 21),
+				Description:  "Route distinguisher of the VRF, formatted in accordance with RFC 4364, like `65000:100`.",
+			},
+			"enforce_unique": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Prevent duplicate prefixes and IP addresses within the VRF.",
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"import_targets": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+				Description: "IDs of the route targets the VRF imports routes from.",
+			},
+			"export_targets": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+				Description: "IDs of the route targets the VRF exports routes to.",
+			},
 			"tenant_id": {
 				Type:     schema.TypeInt,
 				Optional: true,
@@ -33,99 +70,149 @@ func resourceNetboxVrf() *schema.Resource {
 				Optional: true,
 				Set:      schema.HashString,
 			},
+			"tags_all": tagsAllSchema,
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
-func resourceNetboxVrfCreate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
-	data := models.WritableVRF{}
+// writableVRF is the request body of VRFs. The model omits false, empty and
+// nil values, which would keep the current ones.
+type writableVRF struct {
+	*models.WritableVRF
+	Rd            *string `json:"rd"`
+	EnforceUnique bool    `json:"enforce_unique"`
+	Description   string  `json:"description"`
+	Tenant        *int64  `json:"tenant"`
+}
 
+func getVRFFromResourceData(ctx context.Context, api *providerState, d *schema.ResourceData) (*writableVRF, error) {
 	name := d.Get("name").(string)
-	tenant_id := int64(d.Get("tenant_id").(int))
+	data := &writableVRF{
+		WritableVRF: &models.WritableVRF{
+			Name:          &name,
+			ImportTargets: getRouteTargetIDs(d.Get("import_targets")),
+			ExportTargets: getRouteTargetIDs(d.Get("export_targets")),
+		},
+		EnforceUnique: d.Get("enforce_unique").(bool),
+		Description:   d.Get("description").(string),
+	}
+
+	if rd, ok := d.GetOk("rd"); ok {
+		data.Rd = strToPtr(rd.(string))
+	}
+
+	if tenantID, ok := d.GetOk("tenant_id"); ok {
+		data.Tenant = int64ToPtr(int64(tenantID.(int)))
+	}
 
-	data.Name = &name
-	if tenant_id != 0 {
-		data.Tenant = &tenant_id
+	tags, err := getNestedTagListFromResourceDataSet(ctx, api, d.Get("tags"))
+	if err != nil {
+		return nil, err
 	}
+	data.Tags = tags
+	return data, nil
+}
 
-	data.Tags, _ = getNestedTagListFromResourceDataSet(api, d.Get("tags"))
+// getRouteTargetIDs returns the IDs of a set of route targets.
+func getRouteTargetIDs(targets interface{}) []int64 {
+	ids := []int64{}
+	for _, id := range targets.(*schema.Set).List() {
+		ids = append(ids, int64(id.(int)))
+	}
+	return ids
+}
 
-	data.ExportTargets = []int64{}
-	data.ImportTargets = []int64{}
+// flattenRouteTargetIDs returns the IDs of the route targets of a VRF.
+func flattenRouteTargetIDs(targets []*models.NestedRouteTarget) []int64 {
+	ids := []int64{}
+	for _, target := range targets {
+		ids = append(ids, target.ID)
+	}
+	return ids
+}
+
+func resourceNetboxVrfCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
+
+	data, err := getVRFFromResourceData(ctx, api, d)
+	if err != nil {
+		return errorDiagnostics(err)
+	}
 
-	params := ipam.NewIpamVrfsCreateParams().WithData(&data)
+	params := ipam.NewIpamVrfsCreateParams().WithContext(ctx).WithData(data.WritableVRF)
 
-	res, err := api.Ipam.IpamVrfsCreate(params, nil)
+	res, err := api.Ipam.IpamVrfsCreate(params, nil, withRequestBody(params, data))
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
 	d.SetId(strconv.FormatInt(res.GetPayload().ID, 10))
 
-	return resourceNetboxVrfRead(d, m)
+	return resourceNetboxVrfRead(ctx, d, m)
 }
 
-func resourceNetboxVrfRead(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxVrfRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamVrfsReadParams().WithID(id)
+	params := ipam.NewIpamVrfsReadParams().WithContext(ctx).WithID(id)
 
 	res, err := api.Ipam.IpamVrfsRead(params, nil)
 	if err != nil {
-
-		return err
+		if errorIsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return errorDiagnostics(err)
 	}
 
-	d.Set("name", res.GetPayload().Name)
-	if res.GetPayload().Tenant != nil {
-		d.Set("tenant_id", res.GetPayload().Tenant.ID)
+	vrf := res.GetPayload()
+	d.Set("name", vrf.Name)
+	d.Set("rd", vrf.Rd)
+	d.Set("enforce_unique", vrf.EnforceUnique)
+	d.Set("description", vrf.Description)
+	d.Set("import_targets", flattenRouteTargetIDs(vrf.ImportTargets))
+	d.Set("export_targets", flattenRouteTargetIDs(vrf.ExportTargets))
+	if vrf.Tenant != nil {
+		d.Set("tenant_id", vrf.Tenant.ID)
 	} else {
 		d.Set("tenant_id", nil)
 	}
+	setTagsFromNestedTagList(api, d, vrf.Tags)
 	return nil
 }
 
-func resourceNetboxVrfUpdate(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxVrfUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	data := models.WritableVRF{}
-
-	name := d.Get("name").(string)
-
-	tags, _ := getNestedTagListFromResourceDataSet(api, d.Get("tags"))
 
-	data.Name = &name
-	data.Tags = tags
-	data.ExportTargets = []int64{}
-	data.ImportTargets = []int64{}
-
-	if tenantID, ok := d.GetOk("tenant_id"); ok {
-		data.Tenant = int64ToPtr(int64(tenantID.(int)))
+	data, err := getVRFFromResourceData(ctx, api, d)
+	if err != nil {
+		return errorDiagnostics(err)
 	}
-	params := ipam.NewIpamVrfsPartialUpdateParams().WithID(id).WithData(&data)
 
-	_, err := api.Ipam.IpamVrfsPartialUpdate(params, nil)
+	params := ipam.NewIpamVrfsPartialUpdateParams().WithContext(ctx).WithID(id).WithData(data.WritableVRF)
+
+	_, err = api.Ipam.IpamVrfsPartialUpdate(params, nil, withRequestBody(params, data))
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 
-	return resourceNetboxVrfRead(d, m)
+	return resourceNetboxVrfRead(ctx, d, m)
 }
 
-func resourceNetboxVrfDelete(d *schema.ResourceData, m interface{}) error {
-	api := m.(*client.NetBoxAPI)
+func resourceNetboxVrfDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	api := m.(*providerState)
 
 	id, _ := strconv.ParseInt(d.Id(), 10, 64)
-	params := ipam.NewIpamVrfsDeleteParams().WithID(id)
+	params := ipam.NewIpamVrfsDeleteParams().WithContext(ctx).WithID(id)
 
 	_, err := api.Ipam.IpamVrfsDelete(params, nil)
 	if err != nil {
-		return err
+		return errorDiagnostics(err)
 	}
 	return nil
 }
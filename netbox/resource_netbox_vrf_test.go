@@ -3,12 +3,15 @@ package netbox
 import (
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/ipam"
+	"github.com/stretchr/testify/assert"
 )
 
 func testAccNetboxVrfTagDependencies(testName string) string {
@@ -139,6 +142,99 @@ resource "netbox_vrf" "test_tenant" {
 	})
 }
 
+func TestAccNetboxVrf_routeTargets(t *testing.T) {
+
+	testSlug := "vrf_rt"
+	testName := testAccGetTestName(testSlug)
+	rd := fmt.Sprintf("65000:%d", acctest.RandIntRange(1, 65535))
+	resource.ParallelTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		PreCheck:  func() { testAccPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "netbox_route_target" "test_a" {
+  name = "%[1]s"
+}
+
+resource "netbox_route_target" "test_b" {
+  name = "%[2]s"
+}
+
+resource "netbox_vrf" "test" {
+  name           = "%[3]s"
+  rd             = "%[4]s"
+  enforce_unique = false
+  description    = "%[3]s description"
+  import_targets = [netbox_route_target.test_a.id, netbox_route_target.test_b.id]
+  export_targets = [netbox_route_target.test_a.id]
+}`, testAccGetTestName("rt"), testAccGetTestName("rt"), testName, rd),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("netbox_vrf.test", "rd", rd),
+					resource.TestCheckResourceAttr("netbox_vrf.test", "enforce_unique", "false"),
+					resource.TestCheckResourceAttr("netbox_vrf.test", "description", testName+" description"),
+					resource.TestCheckResourceAttr("netbox_vrf.test", "import_targets.#", "2"),
+					resource.TestCheckResourceAttr("netbox_vrf.test", "export_targets.#", "1"),
+					resource.TestCheckTypeSetElemAttrPair("netbox_vrf.test", "export_targets.*", "netbox_route_target.test_a", "id"),
+				),
+			},
+			{
+				ResourceName:      "netbox_vrf.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestUnitNetboxVrf_routeTargets(t *testing.T) {
+	fake := newFakeNetbox(t)
+	api := fake.providerState(t)
+	importID := fake.Seed(t, "ipam/route-targets", map[string]interface{}{"name": "65000:1"})
+	exportID := fake.Seed(t, "ipam/route-targets", map[string]interface{}{"name": "65000:2"})
+	r := resourceNetboxVrf()
+
+	config := map[string]interface{}{
+		"name":           "vrf",
+		"rd":             "65000:100",
+		"enforce_unique": false,
+		"description":    "customer a",
+		"import_targets": []interface{}{int(importID), int(exportID)},
+		"export_targets": []interface{}{int(exportID)},
+	}
+	state, diags := testUnitApply(t, r, nil, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "65000:100", state.Attributes["rd"])
+	assert.Equal(t, "false", state.Attributes["enforce_unique"])
+	assert.Equal(t, "customer a", state.Attributes["description"])
+	assert.Equal(t, "2", state.Attributes["import_targets.#"])
+	assert.Equal(t, "1", state.Attributes["export_targets.#"])
+
+	id, _ := strconv.ParseInt(state.ID, 10, 64)
+	vrf := fake.Get("ipam/vrfs", id)
+	assert.Equal(t, false, vrf["enforce_unique"])
+	assert.Equal(t, exportID, vrf["export_targets"].([]interface{})[0].(map[string]interface{})["id"])
+
+	// Removing optional attributes resets them in Netbox
+	config = map[string]interface{}{
+		"name": "vrf",
+	}
+	state, diags = testUnitApply(t, r, state, config, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, "", state.Attributes["rd"])
+	assert.Equal(t, "true", state.Attributes["enforce_unique"])
+	assert.Equal(t, "0", state.Attributes["import_targets.#"])
+	assert.Equal(t, "0", state.Attributes["export_targets.#"])
+	vrf = fake.Get("ipam/vrfs", id)
+	assert.Nil(t, vrf["rd"])
+	assert.Equal(t, "", vrf["description"])
+	assert.Empty(t, vrf["import_targets"])
+
+	_, diags = testUnitApply(t, r, state, nil, api)
+	assert.False(t, diags.HasError(), "%v", diags)
+	assert.Equal(t, 0, fake.Count("ipam/vrfs"))
+}
+
 func init() {
 	resource.AddTestSweepers("netbox_vrf", &resource.Sweeper{
 		Name:         "netbox_vrf",
@@ -1,51 +1,125 @@
 package netbox
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"sync"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/client/extras"
 	"github.com/netbox-community/go-netbox/netbox/models"
 )
 
-func getNestedTagListFromResourceDataSet(client *client.NetBoxAPI, d interface{}) ([]*models.NestedTag, diag.Diagnostics) {
-	var diags diag.Diagnostics
+// tagsAllSchema is the schema of the tags_all attribute of every resource
+// that supports tags. It holds the resource tags merged with the default tags
+// of the provider.
+var tagsAllSchema = &schema.Schema{
+	Type: schema.TypeSet,
+	Elem: &schema.Schema{
+		Type: schema.TypeString,
+	},
+	Computed:    true,
+	Set:         schema.HashString,
+	Description: "All tags of this resource, including the `default_tags` of the provider.",
+}
+
+// tagCache maps tag names to tags. It is shared by all resources so that tag
+// names are resolved with a single paginated list request instead of one
+// request per tag and resource.
+type tagCache struct {
+	mu   sync.Mutex
+	tags map[string]*models.NestedTag
+}
+
+// tagCachePageSize is the number of tags requested per page when the tag
+// cache is refreshed.
+const tagCachePageSize = int64(1000)
+
+// lookup resolves the given tag names. If a name is not in the cache, the
+// cache is refreshed once. Names that are still unknown are returned as
+// missing.
+func (c *tagCache) lookup(ctx context.Context, api *providerState, names []string) ([]*models.NestedTag, []string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, name := range names {
+		if _, ok := c.tags[name]; !ok {
+			if err := c.refresh(ctx, api); err != nil {
+				return nil, nil, err
+			}
+			break
+		}
+	}
 
-	tagList := d.(*schema.Set).List()
 	tags := []*models.NestedTag{}
-	for _, tag := range tagList {
-
-		tagString := tag.(string)
-		params := extras.NewExtrasTagsListParams()
-		params.Name = &tagString
-		limit := int64(2) // We search for a unique tag. Having two hits suffices to know its not unique.
-		params.Limit = &limit
-		res, err := client.Extras.ExtrasTagsList(params, nil)
+	missing := []string{}
+	for _, name := range names {
+		tag, ok := c.tags[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return tags, missing, nil
+}
+
+// refresh replaces the content of the cache with all tags in Netbox. The
+// caller must hold the lock.
+func (c *tagCache) refresh(ctx context.Context, api *providerState) error {
+	tags := make(map[string]*models.NestedTag)
+
+	params := extras.NewExtrasTagsListParams().WithContext(ctx)
+	params.Limit = int64ToPtr(tagCachePageSize)
+	offset := int64(0)
+	for {
+		params.Offset = int64ToPtr(offset)
+		res, err := api.Extras.ExtrasTagsList(params, nil)
 		if err != nil {
-			diags = append(diags, diag.Diagnostic{
-				Severity: diag.Warning,
-				Summary:  fmt.Sprintf("Error retrieving tag %s from netbox", tag.(string)),
-				Detail:   fmt.Sprintf("API Error trying to retrieve tag %s from netbox", tag.(string)),
-			})
-		} else {
-			payload := res.GetPayload()
-			if *payload.Count == int64(1) {
-				tags = append(tags, &models.NestedTag{
-					Name: payload.Results[0].Name,
-					Slug: payload.Results[0].Slug,
-				})
-			} else {
-				diags = append(diags, diag.Diagnostic{
-					Severity: diag.Warning,
-					Summary:  fmt.Sprintf("Error retrieving tag %s from netbox", tag.(string)),
-					Detail:   fmt.Sprintf("Could not map tag %s to unique tag in netbox", tag.(string)),
-				})
+			return err
+		}
+		payload := res.GetPayload()
+		for _, tag := range payload.Results {
+			tags[*tag.Name] = &models.NestedTag{
+				Name: tag.Name,
+				Slug: tag.Slug,
 			}
 		}
+		offset += int64(len(payload.Results))
+		if payload.Next == nil || len(payload.Results) == 0 {
+			break
+		}
+	}
+
+	c.tags = tags
+	return nil
+}
+
+// invalidate empties the cache so that the next lookup refreshes it.
+func (c *tagCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tags = nil
+}
+
+func getNestedTagListFromResourceDataSet(ctx context.Context, api *providerState, d interface{}) ([]*models.NestedTag, error) {
+	names := []string{}
+	for _, tag := range api.mergeDefaultTags(d.(*schema.Set)).List() {
+		names = append(names, tag.(string))
+	}
+	if len(names) == 0 {
+		return []*models.NestedTag{}, nil
+	}
+
+	tags, missing, err := api.tagCache.lookup(ctx, api, names)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving tags from netbox: %w", err)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("could not find tags %s in netbox", strings.Join(missing, ", "))
 	}
-	return tags, diags
+	return tags, nil
 }
 
 func getTagListFromNestedTagList(nestedTags []*models.NestedTag) []string {
@@ -55,3 +129,47 @@ func getTagListFromNestedTagList(nestedTags []*models.NestedTag) []string {
 	}
 	return tags
 }
+
+// mergeDefaultTags returns the union of the given tags and the default tags
+// of the provider.
+func (s *providerState) mergeDefaultTags(tags *schema.Set) *schema.Set {
+	if s.defaultTags == nil {
+		return tags
+	}
+	return tags.Union(s.defaultTags)
+}
+
+// setTagsFromNestedTagList sets tags_all to the tags returned by Netbox and
+// tags to the same list without the default tags of the provider, unless
+// they are also configured on the resource itself. This keeps default tags
+// out of the plan diff.
+func setTagsFromNestedTagList(api *providerState, d *schema.ResourceData, nestedTags []*models.NestedTag) error {
+	allTags := getTagListFromNestedTagList(nestedTags)
+	configuredTags := d.Get("tags").(*schema.Set)
+
+	tags := []string{}
+	for _, tag := range allTags {
+		if api.defaultTags != nil && api.defaultTags.Contains(tag) && !configuredTags.Contains(tag) {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := d.Set("tags", tags); err != nil {
+		return err
+	}
+	return d.Set("tags_all", allTags)
+}
+
+// customizeDiffTagsAll plans tags_all as the union of the configured tags and
+// the default tags of the provider.
+func customizeDiffTagsAll(ctx context.Context, diff *schema.ResourceDiff, m interface{}) error {
+	api := m.(*providerState)
+
+	if !diff.NewValueKnown("tags") {
+		return diff.SetNewComputed("tags_all")
+	}
+
+	tags := diff.Get("tags").(*schema.Set)
+	return diff.SetNew("tags_all", api.mergeDefaultTags(tags).List())
+}
@@ -1,8 +1,16 @@
 package netbox
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/netbox-community/go-netbox/netbox/client"
 	"github.com/netbox-community/go-netbox/netbox/models"
 	"github.com/stretchr/testify/assert"
 )
@@ -27,3 +35,121 @@ func TestGetTagListFromNestedTagList(t *testing.T) {
 	}
 	assert.Equal(t, flat, expected)
 }
+
+func TestMergeDefaultTags(t *testing.T) {
+
+	tags := schema.NewSet(schema.HashString, []interface{}{"Foo"})
+
+	state := &providerState{}
+	assert.ElementsMatch(t, []interface{}{"Foo"}, state.mergeDefaultTags(tags).List())
+
+	state.defaultTags = schema.NewSet(schema.HashString, []interface{}{"Foo", "Bar"})
+	assert.ElementsMatch(t, []interface{}{"Foo", "Bar"}, state.mergeDefaultTags(tags).List())
+}
+
+func TestSetTagsFromNestedTagListOmitsDefaultTags(t *testing.T) {
+
+	state := &providerState{
+		defaultTags: schema.NewSet(schema.HashString, []interface{}{"Bar", "Baz"}),
+	}
+	d := resourceNetboxTenant().TestResourceData()
+	d.Set("tags", []string{"Foo", "Baz"})
+
+	tags := []*models.NestedTag{
+		{Name: strToPtr("Foo"), Slug: strToPtr("foo")},
+		{Name: strToPtr("Bar"), Slug: strToPtr("bar")},
+		{Name: strToPtr("Baz"), Slug: strToPtr("baz")},
+	}
+
+	assert.NoError(t, setTagsFromNestedTagList(state, d, tags))
+	assert.ElementsMatch(t, []interface{}{"Foo", "Baz"}, d.Get("tags").(*schema.Set).List())
+	assert.ElementsMatch(t, []interface{}{"Foo", "Bar", "Baz"}, d.Get("tags_all").(*schema.Set).List())
+}
+
+// testTagServer serves the given tag names from the tag list endpoint, two
+// tags per page, and counts the requests it receives.
+func testTagServer(t *testing.T, names *[]string, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		assert.Equal(t, "/api/extras/tags/", r.URL.Path)
+
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		end := offset + 2
+		if end > len(*names) {
+			end = len(*names)
+		}
+
+		results := []map[string]interface{}{}
+		for i, name := range (*names)[offset:end] {
+			results = append(results, map[string]interface{}{
+				"id":   offset + i + 1,
+				"name": name,
+				"slug": name,
+			})
+		}
+		var next interface{}
+		if end < len(*names) {
+			next = fmt.Sprintf("http://%s/api/extras/tags/?limit=2&offset=%d", r.Host, end)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"count":   len(*names),
+			"next":    next,
+			"results": results,
+		})
+	}))
+}
+
+func TestGetNestedTagListUsesCache(t *testing.T) {
+
+	requests := 0
+	names := []string{"a", "b", "c"}
+	ts := testTagServer(t, &names, &requests)
+	defer ts.Close()
+
+	config := Config{
+		APIToken:  "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL: ts.URL,
+	}
+	netboxClient, err := config.Client(context.Background())
+	assert.NoError(t, err)
+	state := &providerState{NetBoxAPI: netboxClient.(*client.NetBoxAPI)}
+
+	tags, err := getNestedTagListFromResourceDataSet(context.Background(), state, schema.NewSet(schema.HashString, []interface{}{"a", "c"}))
+	assert.NoError(t, err)
+	assert.Len(t, tags, 2)
+	assert.Equal(t, 2, requests)
+
+	// All tags are cached, so no further requests are made
+	tags, err = getNestedTagListFromResourceDataSet(context.Background(), state, schema.NewSet(schema.HashString, []interface{}{"b"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "b", *tags[0].Slug)
+	assert.Equal(t, 2, requests)
+
+	// A miss refreshes the cache
+	names = append(names, "d")
+	tags, err = getNestedTagListFromResourceDataSet(context.Background(), state, schema.NewSet(schema.HashString, []interface{}{"d"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "d", *tags[0].Name)
+	assert.Equal(t, 4, requests)
+}
+
+func TestGetNestedTagListUnknownTag(t *testing.T) {
+
+	requests := 0
+	names := []string{"a"}
+	ts := testTagServer(t, &names, &requests)
+	defer ts.Close()
+
+	config := Config{
+		APIToken:  "07b12b765127747e4afd56cb531b7bf9c61f3c30",
+		ServerURL: ts.URL,
+	}
+	netboxClient, err := config.Client(context.Background())
+	assert.NoError(t, err)
+	state := &providerState{NetBoxAPI: netboxClient.(*client.NetBoxAPI)}
+
+	_, err = getNestedTagListFromResourceDataSet(context.Background(), state, schema.NewSet(schema.HashString, []interface{}{"a", "x"}))
+	assert.EqualError(t, err, "could not find tags x in netbox")
+}
@@ -0,0 +1,41 @@
+package netbox
+
+import (
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// defaultTimeout is the default time an operation on a resource may take.
+// Most operations send a single request to Netbox.
+const defaultTimeout = 5 * time.Minute
+
+// defaultTimeouts returns the default timeouts of resources.
+func defaultTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(defaultTimeout),
+		Read:   schema.DefaultTimeout(defaultTimeout),
+		Update: schema.DefaultTimeout(defaultTimeout),
+		Delete: schema.DefaultTimeout(defaultTimeout),
+	}
+}
+
+// allocationTimeouts returns the timeouts of resources that allocate from a
+// parent. Their creation waits for the allocation lock of the parent until
+// every other allocation from the same parent in the run is done.
+func allocationTimeouts() *schema.ResourceTimeout {
+	timeouts := defaultTimeouts()
+	timeouts.Create = schema.DefaultTimeout(10 * time.Minute)
+	return timeouts
+}
+
+// configContextTimeouts returns the timeouts of devices and virtual machines.
+// Netbox renders their config context into every response, which is slow
+// with many config contexts.
+func configContextTimeouts() *schema.ResourceTimeout {
+	timeouts := defaultTimeouts()
+	timeouts.Create = schema.DefaultTimeout(10 * time.Minute)
+	timeouts.Read = schema.DefaultTimeout(10 * time.Minute)
+	timeouts.Update = schema.DefaultTimeout(10 * time.Minute)
+	return timeouts
+}
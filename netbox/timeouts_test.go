@@ -0,0 +1,26 @@
+package netbox
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceTimeouts(t *testing.T) {
+	// Allocations wait for the allocation lock of their parent
+	allocations := map[string]bool{
+		"netbox_available_ip_address": true,
+		"netbox_available_prefix":     true,
+		"netbox_available_vlan":       true,
+	}
+
+	for name, r := range Provider().ResourcesMap {
+		if !assert.NotNil(t, r.Timeouts, name) {
+			continue
+		}
+		if allocations[name] {
+			assert.Greater(t, *r.Timeouts.Create, defaultTimeout, name)
+		}
+		assert.Equal(t, defaultTimeout, *r.Timeouts.Delete, name)
+	}
+}